@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
@@ -14,6 +16,7 @@ import (
 	"github.com/m2q/algo-siam/client"
 
 	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
 )
 
 // AlgorandBuffer implements the Buffer interface. The underlying storage mechanism is
@@ -34,7 +37,9 @@ import (
 //   client, err := client.CreateAlgorandClientWrapper(url, token)
 //   buffer, err := NewAlgorandBuffer(client, privKey)
 type AlgorandBuffer struct {
-	// AppId is the ID of Algorand application this buffer publishes to.
+	// AppId is the ID of Algorand application this buffer publishes to. The management
+	// loop updates it when it adopts a different app, so don't read it concurrently
+	// with the loop. Use AppID instead.
 	AppId uint64
 
 	// AccountCrypt is the owner of the buffer's Algorand application.
@@ -43,10 +48,34 @@ type AlgorandBuffer struct {
 	// Client is the wrapping interface for communicating with the node
 	Client client.AlgorandClient
 
+	// AppChannel receives a ManageEvent whenever the buffer adopts a different
+	// application, or detects a problem like ErrUnexpectedRekey. Events are dropped if
+	// the channel is full.
+	AppChannel chan ManageEvent
+
+	// ErrChannel receives the errors of failed cycles of the management loop (see
+	// Manage), wrapped in a ManageError that names the phase of the cycle. Errors are
+	// dropped if the channel is full, so the loop never blocks.
+	ErrChannel chan error
+
 	// storeArguments is consumed by the Manage goroutine and writes kv pairs
-	// regularly to the blockchain app storage
+	// regularly to the blockchain app storage (see QueueElements)
 	storeArguments chan models.TealKeyValue
 
+	// queued holds the pairs taken from storeArguments that haven't been stored yet.
+	// Only used by the management loop.
+	queued map[string][]byte
+
+	// queueMu makes the pairs of a single QueueElements call enter the queue together
+	queueMu sync.Mutex
+
+	// queueSeq counts the QueueElements calls, and unflushed maps queued keys to the
+	// call that queued them last, until they're stored. flushed is closed and replaced
+	// whenever queued pairs have been stored (see Flush). Guarded by queueMu.
+	queueSeq  uint64
+	unflushed map[string]uint64
+	flushed   chan struct{}
+
 	// DeleteElements is consumed by the Manage goroutine and deletes given
 	// keys from the blockchain application storage
 	deleteArguments chan string
@@ -54,6 +83,133 @@ type AlgorandBuffer struct {
 	// timeoutLength is the default duration for Client requests like
 	// Health() or Status() to timeout.
 	timeoutLength time.Duration
+
+	// config holds the configuration the buffer was created with
+	config ManageConfig
+
+	// metrics are the counters exported by WritePrometheus. Guarded by mu.
+	metrics bufferMetrics
+
+	// lastWrites holds the time at which keys were last written, and lastSuppressed
+	// the keys that were suppressed during the last store. Guarded by mu.
+	lastWrites     map[string]time.Time
+	lastSuppressed []string
+
+	// keyOrder holds the order in which state keys are evicted (see
+	// ManageConfig.EvictionPolicy), by the sequence number of their last use. Guarded
+	// by mu.
+	keyOrder map[string]uint64
+	keySeq   uint64
+
+	// watchers are the subscriptions of Watch, and watchState the global state the
+	// management loop observed last, with user keys. Guarded by mu.
+	watchers      map[*watcher]bool
+	watchState    map[string]string
+	watchObserved bool
+
+	// lastHeartbeat is the time of the last heartbeat. Only used by the management loop.
+	lastHeartbeat time.Time
+
+	// converged is true if the last cycle of the management loop converged (see
+	// ManageConfig.OnConverged). Only used by the management loop.
+	converged bool
+
+	// loop holds the statistics of the management loop. Guarded by mu.
+	loop loopStats
+
+	// lastTruncated holds the keys whose values were truncated during the last
+	// store. Guarded by mu.
+	lastTruncated []string
+
+	// lastUncleared holds the addresses of the opted-in accounts whose local state
+	// couldn't be removed during the last app deletion. Guarded by mu.
+	lastUncleared []string
+
+	// nearCapacity is true if the last write filled the buffer beyond
+	// ManageConfig.WarnCapacityRatio. Guarded by mu.
+	nearCapacity bool
+
+	// lastRound is the last round of the node seen by the management loop. Guarded
+	// by mu.
+	lastRound uint64
+
+	// managed holds the managedApp published for AppID
+	managed atomic.Value
+
+	// appId holds the uint64 ID of the app the buffer publishes to (see currentAppId)
+	appId atomic.Value
+
+	// readOnly is true for buffers without a private key (see NewReadOnlyBuffer)
+	readOnly bool
+
+	// standby is true while another manager holds the lease (see Standby). Guarded by mu.
+	standby bool
+
+	// leaseOwner is the owner ID of this buffer in the lease
+	leaseOwner string
+
+	// signer is the expected auth address of the target account, or zero if it signs
+	// for itself. Guarded by mu.
+	signer types.Address
+
+	// reportedSigner is the unexpected auth address that was last reported. Only used
+	// by the management loop.
+	reportedSigner types.Address
+
+	// params caches the suggested params of the node. Guarded by mu.
+	params paramsCache
+
+	// schema is the global state schema of the app the buffer publishes to. Guarded by mu.
+	schema models.ApplicationStateSchema
+
+	// localSchema is the local state schema of the app the buffer publishes to. Guarded
+	// by mu.
+	localSchema models.ApplicationStateSchema
+
+	// extraApps holds the time at which extra valid apps were first observed. Guarded
+	// by reconcileMu.
+	extraApps map[uint64]time.Time
+
+	// reconcileMu serializes the reconciliations of the target account (see
+	// ReconcileOnce)
+	reconcileMu sync.Mutex
+
+	// cycleMu serializes the cycles of the management loop with migrations (see
+	// MigrateTo). Acquired before reconcileMu.
+	cycleMu sync.Mutex
+
+	// fees tracks the spent transaction fees if a FeeBudget is configured
+	fees *feeTracker
+
+	// now returns the current time. It can be replaced to control time in tests.
+	now func() time.Time
+
+	// latency keeps the confirmation durations of recent transactions
+	latency *latencyReservoir
+
+	// loopCtx is the context of the management loop. It's cancelled by stopLoop
+	// when the buffer shuts down.
+	loopCtx  context.Context
+	stopLoop context.CancelFunc
+
+	// loopDone is closed when the management loop returns. Guarded by mu.
+	loopDone chan struct{}
+
+	// stopped is true after Stop. Guarded by mu.
+	stopped  bool
+	stopOnce sync.Once
+
+	// active counts the writes in flight (see beginWrite)
+	active sync.WaitGroup
+
+	// lastLogs are the app logs of the transactions of the last write
+	lastLogs [][]byte
+
+	// lastDelta is the decoded global state delta of the transactions of the last write
+	lastDelta []models.EvalDeltaKeyValue
+
+	// mu guards the state the buffer observed from its transactions
+	mu sync.Mutex
 }
 
 // PrintNewAccount will randomly generate a new account, and print the base64-encoded
@@ -96,6 +252,13 @@ func NewAlgorandBufferFromEnv() (*AlgorandBuffer, error) {
 // base64key is the base64-encoded private key of the 'target account'. The target account
 // creates and maintains the applications state on the blockchain.
 func NewAlgorandBuffer(c client.AlgorandClient, b64key string) (*AlgorandBuffer, error) {
+	return NewAlgorandBufferWithConfig(c, b64key, ManageConfig{})
+}
+
+// NewAlgorandBufferWithConfig creates a new instance of AlgorandBuffer, just like
+// NewAlgorandBuffer. The given ManageConfig controls how the buffer submits
+// transactions and manages the target account.
+func NewAlgorandBufferWithConfig(c client.AlgorandClient, b64key string, cfg ManageConfig) (*AlgorandBuffer, error) {
 	// Decode Base64 private key
 	pk, err := base64.StdEncoding.DecodeString(b64key)
 	if err != nil {
@@ -106,6 +269,16 @@ func NewAlgorandBuffer(c client.AlgorandClient, b64key string) (*AlgorandBuffer,
 	if err != nil {
 		return nil, err
 	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.OnBeforeSubmit != nil {
+		c = client.WithSubmitHook(c, cfg.OnBeforeSubmit)
+	}
+	if cfg.FeeStrategy != nil {
+		c = client.WithFeeStrategy(c, *cfg.FeeStrategy)
+	}
+	c = withTimeouts(c, cfg)
 
 	buffer := &AlgorandBuffer{
 		Client:          c,
@@ -113,6 +286,25 @@ func NewAlgorandBuffer(c client.AlgorandClient, b64key string) (*AlgorandBuffer,
 		deleteArguments: make(chan string, 64),
 		storeArguments:  make(chan models.TealKeyValue, 64),
 		timeoutLength:   client.AlgorandDefaultTimeout,
+		config:          cfg,
+		now:             time.Now,
+		latency:         newLatencyReservoir(latencyReservoirSize),
+		extraApps:       make(map[uint64]time.Time),
+		lastWrites:      make(map[string]time.Time),
+		AppChannel:      make(chan ManageEvent, appChannelSize),
+		ErrChannel:      make(chan error, errChannelSize),
+		signer:          cfg.ExpectedSigner,
+	}
+	buffer.loopCtx, buffer.stopLoop = context.WithCancel(context.Background())
+	buffer.leaseOwner = cfg.LeaseOwner
+	if buffer.leaseOwner == "" {
+		buffer.leaseOwner = newLeaseOwner()
+	}
+	if cfg.FeeBudget != nil {
+		buffer.fees = newFeeTracker(*cfg.FeeBudget)
+	}
+	if cfg.HealthTimeout > 0 {
+		buffer.timeoutLength = cfg.HealthTimeout
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), client.AlgorandDefaultTimeout)
@@ -134,32 +326,70 @@ func (ab *AlgorandBuffer) ensureRemoteValid(ctx context.Context) error {
 	// Connectivity check
 	err := ab.checkConnection()
 	if err != nil {
-		return err
+		return withPhase(PhaseHealth, err)
 	}
 
 	// Deletion Routine
-	err = ab.manageDeletion()
+	err = ab.manageDeletion(ctx)
 	if err != nil {
-		return err
+		return withPhase(PhaseDelete, err)
 	}
 
 	// Creation Routine
-	err = ab.manageCreation()
+	err = ab.manageCreation(ctx)
 	if err != nil {
-		return err
+		return withPhase(PhaseCreate, err)
 	}
 
 	// Set AppID correctly
-	ctx, cancel := context.WithTimeout(context.Background(), ab.timeoutLength)
-	info, err := ab.Client.AccountInformation(ab.AccountCrypt.Address.String(), ctx)
+	infoCtx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	info, err := ab.accountInformation(infoCtx)
 	cancel()
 	if err != nil {
-		return err
+		return withPhase(PhaseValidate, err)
 	}
-	ab.AppId = info.CreatedApps[0].Id
+	kept := ab.keptApp(info.CreatedApps)
+	if kept < 0 {
+		return withPhase(PhaseValidate, &NoApplication{Account: ab.AccountCrypt})
+	}
+	ab.setAppId(info.CreatedApps[kept].Id)
+	ab.observeSchema(info.CreatedApps[kept])
+	ab.observeState(info.Round, info.CreatedApps[kept])
+	ab.publishApp(ab.currentAppId(), true)
 	return nil
 }
 
+// ReconcileOnce brings the target account into a valid state once: it checks the
+// connection to the node, deletes invalid (or extra) applications and creates a new
+// application if necessary. This is done automatically when creating the buffer.
+// Call it regularly to repair the account if it has been modified externally. Errors
+// are wrapped in a ManageError that names the phase that failed.
+func (ab *AlgorandBuffer) ReconcileOnce(ctx context.Context) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	ab.reconcileMu.Lock()
+	defer ab.reconcileMu.Unlock()
+	return ab.ensureRemoteValid(ctx)
+}
+
+// accountInformation returns the information of the target account, including its
+// created apps.
+func (ab *AlgorandBuffer) accountInformation(ctx context.Context) (models.Account, error) {
+	return ab.Client.AccountInformation(ab.AccountCrypt.Address.String(), ctx)
+}
+
+// accountSummary returns the information of the target account for requests that
+// don't need its created apps (e.g. the balance or the auth address). Fields configured
+// in ManageConfig.ExcludeAccountFields are excluded from the response.
+func (ab *AlgorandBuffer) accountSummary(ctx context.Context) (models.Account, error) {
+	addr := ab.AccountCrypt.Address.String()
+	if len(ab.config.ExcludeAccountFields) > 0 {
+		return client.AccountInformationExcluding(ab.Client, addr, ab.config.ExcludeAccountFields, ctx)
+	}
+	return ab.Client.AccountInformation(addr, ctx)
+}
+
 // VerifyToken checks whether the URL and provided API token resolve to a correct
 // Algorand node instance.
 func (ab *AlgorandBuffer) VerifyToken() error {
@@ -188,24 +418,26 @@ func (ab *AlgorandBuffer) GetBuffer(ctx context.Context) (map[string]string, err
 	}
 	m := make(map[string]string, len(b))
 	for k, v := range b {
-		m[k] = string(v)
+		m[k] = ab.config.ValueEncoding.encode(v)
 	}
 	return m, nil
 }
 
 // GetBufferRaw returns the stored global state of this buffer's associated Algorand application.
 func (ab *AlgorandBuffer) GetBufferRaw(ctx context.Context) (map[string][]byte, error) {
-	ctx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
-	app, err := ab.Client.GetApplicationByID(ab.AppId, ctx)
-	cancel()
+	if ab.isStopped() {
+		return nil, ErrStopped
+	}
+	state, err := ab.userState(ctx)
 	if err != nil {
 		return nil, err
 	}
-	m := make(map[string][]byte)
-	for _, kv := range app.Params.GlobalState {
-		decodedKey, _ := base64.StdEncoding.DecodeString(kv.Key)
-		decodedVal, _ := base64.StdEncoding.DecodeString(kv.Value.Bytes)
-		m[string(decodedKey)] = decodedVal
+	if ab.config.KeyEncoding == KeyRaw {
+		return state, nil
+	}
+	m := make(map[string][]byte, len(state))
+	for k, v := range state {
+		m[ab.config.KeyEncoding.fromState([]byte(k))] = v
 	}
 	return m, nil
 }
@@ -213,69 +445,322 @@ func (ab *AlgorandBuffer) GetBufferRaw(ctx context.Context) (map[string][]byte,
 // PutElements stores given key-value pairs. Existing keys will be overridden,
 // non-existing keys will be created.
 func (ab *AlgorandBuffer) PutElements(ctx context.Context, data map[string]string) error {
+	return ab.PutElementsWithOptions(ctx, data, client.WriteOptions{})
+}
+
+// PutElementsWithOptions stores given key-value pairs like PutElements, and tags the
+// transactions with the note and lease of opts (see client.WriteOptions). If the pairs
+// are split into several batches, each batch gets its own lease, derived from
+// opts.Lease (see client.WriteOptions.ForBatch). Pairs with empty values are deleted
+// without options (see ManageConfig.EmptyValue).
+func (ab *AlgorandBuffer) PutElementsWithOptions(ctx context.Context, data map[string]string, opts client.WriteOptions) error {
 	m := make(map[string][]byte, len(data))
 	for k, v := range data {
-		m[k] = []byte(v)
+		value, err := ab.config.ValueEncoding.decode(v)
+		if err != nil {
+			return err
+		}
+		m[k] = value
 	}
-	err := ab.PutElementsRaw(ctx, m)
+	err := ab.putElementsRaw(ctx, m, opts)
 	return err
 }
 
 // PutElementsRaw stores given key-value pairs, with []byte values. See PutElements for a
 // convenience function using string values
 func (ab *AlgorandBuffer) PutElementsRaw(ctx context.Context, data map[string][]byte) error {
+	return ab.putElementsRaw(ctx, data, client.WriteOptions{})
+}
+
+func (ab *AlgorandBuffer) putElementsRaw(ctx context.Context, data map[string][]byte, opts client.WriteOptions) error {
+	if stores, deletes := ab.splitEmpty(data); len(deletes) > 0 {
+		if len(stores) > 0 {
+			if err := ab.putElementsRaw(ctx, stores, opts); err != nil {
+				return err
+			}
+		}
+		return ab.DeleteElements(ctx, deletes...)
+	}
+	batches, err := ab.rawBatches(data)
+	if err != nil {
+		return err
+	}
+	return ab.storeBatches(ctx, batches, opts)
+}
+
+// rawBatches splits the given key-value pairs into batches of ManageConfig.BatchSize
+// pairs, with global state keys. Values are fitted to the maximum length (see
+// ManageConfig.TruncateOversized).
+func (ab *AlgorandBuffer) rawBatches(data map[string][]byte) ([][]models.TealKeyValue, error) {
+	batches, truncated, err := ab.fittedBatches(data)
+	if err != nil {
+		return nil, err
+	}
+	ab.setTruncated(truncated)
+	return batches, nil
+}
+
+// fittedBatches splits the given key-value pairs into batches like rawBatches, and
+// returns the sorted keys of truncated values instead of recording them.
+func (ab *AlgorandBuffer) fittedBatches(data map[string][]byte) ([][]models.TealKeyValue, []string, error) {
+	data, err := ab.stateKeys(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	fitted := make(map[string][]byte, len(data))
+	truncated := make([]string, 0)
 	for k, v := range data {
-		if len(k)+len(v) > 128 {
-			return errors.New("kv pair cannot exceed 128 bytes")
+		value, wasTruncated, err := ab.fitValue(k, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if wasTruncated {
+			truncated = append(truncated, ab.config.KeyEncoding.fromState([]byte(k)))
 		}
+		fitted[k] = value
 	}
-	// if the number of kv pairs exceed client.MaxKVArgs, we need to split them up
+	sort.Strings(truncated)
+	return ab.batchesOf(fitted), truncated, nil
+}
+
+// batchesOf splits the given key-value pairs with global state keys into batches of
+// ManageConfig.BatchSize pairs.
+func (ab *AlgorandBuffer) batchesOf(data map[string][]byte) [][]models.TealKeyValue {
+	// if the number of kv pairs exceed the batch size, we need to split them up
 	// into partitions. One txn for each partition
-	partitions := partitionMapByte(data, client.MaxKVArgs)
+	partitions := partitionMapByte(data, ab.batchSize())
+	batches := make([][]models.TealKeyValue, 0, len(partitions))
 	for _, p := range partitions {
-		kvArray := make([]models.TealKeyValue, 0, client.MaxKVArgs)
+		kvArray := make([]models.TealKeyValue, 0, len(p))
 		for k, v := range p {
 			tkv := models.TealKeyValue{Key: k, Value: models.TealValue{Bytes: string(v)}}
 			kvArray = append(kvArray, tkv)
 		}
-		err := ab.Client.StoreGlobals(ab.AccountCrypt, ab.AppId, kvArray)
+		batches = append(batches, kvArray)
+	}
+	return batches
+}
+
+// stateKeys returns the given data with global state keys (see ManageConfig.KeyEncoding).
+func (ab *AlgorandBuffer) stateKeys(data map[string][]byte) (map[string][]byte, error) {
+	if ab.config.KeyEncoding == KeyRaw {
+		return data, nil
+	}
+	m := make(map[string][]byte, len(data))
+	for k, v := range data {
+		key, err := ab.config.KeyEncoding.toState(k)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// KV is a key-value pair of the buffer.
+type KV struct {
+	Key   string
+	Value string
+}
+
+// PutOrdered stores the given key-value pairs in the order of the slice. The pairs
+// are split into consecutive batches of up to ManageConfig.BatchSize pairs, and each
+// batch is submitted as a separate transaction. Transactions are submitted one after
+// another. approval.teal applies the pairs of a single transaction from last to first,
+// so a key that occurs again starts a new batch. This way, if a key occurs several
+// times, the last value wins. Use it instead of PutElements if your approval program
+// depends on the order of updates across transactions.
+func (ab *AlgorandBuffer) PutOrdered(ctx context.Context, pairs []KV) error {
+	if stores, deletes := ab.splitEmptyPairs(pairs); len(deletes) > 0 {
+		if len(stores) > 0 {
+			if err := ab.PutOrdered(ctx, stores); err != nil {
+				return err
+			}
+		}
+		return ab.DeleteElements(ctx, deletes...)
+	}
+	encoded := pairs
+	pairs = make([]KV, len(encoded))
+	for i, kv := range encoded {
+		key, err := ab.config.KeyEncoding.toState(kv.Key)
 		if err != nil {
 			return err
 		}
+		value, err := ab.config.ValueEncoding.decode(kv.Value)
+		if err != nil {
+			return err
+		}
+		pairs[i] = KV{Key: key, Value: string(value)}
+	}
+	truncated := make([]string, 0)
+	for i, kv := range pairs {
+		value, wasTruncated, err := ab.fitValue(kv.Key, []byte(kv.Value))
+		if err != nil {
+			return err
+		}
+		if wasTruncated {
+			truncated = append(truncated, encoded[i].Key)
+		}
+		pairs[i].Value = string(value)
+	}
+	ab.setTruncated(truncated)
+	partitions := partitionPairs(pairs, ab.batchSize())
+	batches := make([][]models.TealKeyValue, 0, len(partitions))
+	for _, p := range partitions {
+		kvArray := make([]models.TealKeyValue, 0, len(p))
+		for _, kv := range p {
+			tkv := models.TealKeyValue{Key: kv.Key, Value: models.TealValue{Bytes: kv.Value}}
+			kvArray = append(kvArray, tkv)
+		}
+		batches = append(batches, kvArray)
+	}
+	return ab.storeBatches(ctx, batches, client.WriteOptions{})
+}
+
+// storeBatches submits one transaction for each batch of key-value pairs, in order,
+// with the options of the write.
+func (ab *AlgorandBuffer) storeBatches(ctx context.Context, batches [][]models.TealKeyValue, opts client.WriteOptions) error {
+	return ab.writeBatches(ctx, batches, opts, true)
+}
+
+// writeBatches is storeBatches, but only suppresses churn (see suppressChurn) if
+// suppress is set.
+func (ab *AlgorandBuffer) writeBatches(ctx context.Context, batches [][]models.TealKeyValue, opts client.WriteOptions, suppress bool) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	if err := ab.beginWrite(); err != nil {
+		return err
+	}
+	defer ab.active.Done()
+	if err := ab.validateSpec(batches); err != nil {
+		return err
+	}
+	if suppress {
+		batches = ab.suppressChurn(batches)
+	}
+	if err := ab.checkCapacity(ctx, batches); err != nil {
+		return err
+	}
+	batches, err := ab.withModified(ctx, batches)
+	if err != nil {
+		return err
+	}
+	err = ab.spendTxnFees(ctx, writeTxnSizes(batches, opts.Note)...)
+	if err != nil {
+		return err
+	}
+	results := make([]models.PendingTransactionInfoResponse, 0, len(batches))
+	defer func() { ab.observeResults(&ab.metrics.storeTxns, results) }()
+	for i, kvArray := range batches {
+		// don't submit further batches of cancelled writes
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		start := ab.now()
+		var result models.PendingTransactionInfoResponse
+		if len(opts.Note) == 0 && opts.Lease == ([32]byte{}) {
+			result, err = client.StoreGlobalsResult(ab.Client, ab.AccountCrypt, ab.currentAppId(), kvArray)
+		} else {
+			result, err = client.StoreGlobalsWithOptions(ab.Client, ab.AccountCrypt, ab.currentAppId(), kvArray, opts.ForBatch(i))
+		}
+		if err != nil {
+			return ab.observeSubmitError(err)
+		}
+		ab.observeLatency(start)
+		ab.recordWrites(kvArray, start)
+		ab.trackKeys(kvArray)
+		results = append(results, result)
+	}
+	if err := ab.awaitStoreFinality(ctx, results); err != nil {
+		return err
+	}
+	ab.warnNearLimits(ctx, batches)
+	return nil
+}
+
+// validateSpec validates the batches against the ContractSpec, if one is configured.
+func (ab *AlgorandBuffer) validateSpec(batches [][]models.TealKeyValue) error {
+	spec := ab.config.ContractSpec
+	if spec == nil {
+		return nil
+	}
+	for _, kvArray := range batches {
+		for _, kv := range kvArray {
+			if err := spec.validate(kv.Key, kv.Value.Bytes); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
 func (ab *AlgorandBuffer) DeleteElements(ctx context.Context, keys ...string) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	if err := ab.beginWrite(); err != nil {
+		return err
+	}
+	defer ab.active.Done()
+	encoded := keys
+	keys = make([]string, len(encoded))
+	for i, k := range encoded {
+		key, err := ab.config.KeyEncoding.toState(k)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
 	for _, k := range keys {
 		if len(k) > 128 {
 			return errors.New("key can't exceed 128 bytes")
 		}
 	}
+	keys = ab.withModifiedKeys(keys)
+	batches := (len(keys) + client.MaxArgs - 1) / client.MaxArgs
+	err := ab.spendTxnFees(ctx, deleteTxnSizes(keys)...)
+	if err != nil {
+		return err
+	}
+	results := make([]models.PendingTransactionInfoResponse, 0, batches)
+	defer func() { ab.observeResults(&ab.metrics.deleteTxns, results) }()
 	delArray := make([]string, 0)
 	for _, k := range keys {
 		if len(delArray) == client.MaxArgs {
-			err := ab.Client.DeleteGlobals(ab.AccountCrypt, ab.AppId, delArray...)
+			start := ab.now()
+			result, err := client.DeleteGlobalsResult(ab.Client, ab.AccountCrypt, ab.currentAppId(), delArray...)
 			if err != nil {
-				return err
+				return ab.observeSubmitError(err)
 			}
+			ab.observeLatency(start)
+			results = append(results, result)
 			delArray = make([]string, 0)
 		}
 		delArray = append(delArray, k)
 	}
 	if len(delArray) > 0 {
-		err := ab.Client.DeleteGlobals(ab.AccountCrypt, ab.AppId, delArray...)
+		start := ab.now()
+		result, err := client.DeleteGlobalsResult(ab.Client, ab.AccountCrypt, ab.currentAppId(), delArray...)
 		if err != nil {
-			return err
+			return ab.observeSubmitError(err)
 		}
+		ab.observeLatency(start)
+		results = append(results, result)
+	}
+	ab.untrackKeys(keys)
+	if err := ab.awaitStoreFinality(ctx, results); err != nil {
+		return err
 	}
+	// deletes can only drop below the capacity threshold
+	ab.warnNearLimits(ctx, nil)
 	return nil
 }
 
 // ContainsWithin returns true if the AlgorandBuffer contains the given data within time.
 // The polling interval determines how often the endpoint is pinged for new data.
 func (ab *AlgorandBuffer) ContainsWithin(m map[string]string, t time.Duration, pollingInterval time.Duration) bool {
-	if len(m) > client.GlobalBytes {
+	if len(m) > ab.capacity() {
 		return false
 	}
 	now := time.Now()
@@ -298,7 +783,7 @@ func (ab *AlgorandBuffer) ContainsWithin(m map[string]string, t time.Duration, p
 // Contains returns true if the AlgorandBuffer contains the given data. Returns
 // an error if the request to the Algorand node failed.
 func (ab *AlgorandBuffer) Contains(ctx context.Context, m map[string]string) (bool, error) {
-	if len(m) > client.GlobalBytes {
+	if len(m) > ab.capacity() {
 		return false, nil
 	}
 	data, err := ab.GetBuffer(ctx)
@@ -338,35 +823,53 @@ func (ab *AlgorandBuffer) AchieveDesiredState(ctx context.Context, desired map[s
 
 // manageCreation creates an Algorand application for the target account.
 // For this to work, the account needs to be valid (i.e. have no registered
-// app and enough funding).
-func (ab *AlgorandBuffer) manageCreation() error {
-	info, err := ab.Client.AccountInformation(ab.AccountCrypt.Address.String(), context.Background())
+// app and enough funding). If the account already owns a valid app, nothing
+// is created.
+func (ab *AlgorandBuffer) manageCreation(ctx context.Context) error {
+	info, err := ab.accountInformation(ctx)
 	if err != nil {
 		return err
 	}
 
-	if client.ValidAccount(info) {
+	if ab.keptApp(info.CreatedApps) >= 0 {
 		return nil
 	}
-	if len(info.CreatedApps) > 0 {
+	ab.publishApp(0, false)
+	// Apps that don't match the AppFilter are unrelated to the buffer
+	if len(info.CreatedApps) > 0 && ab.config.AppFilter == nil {
 		return errors.New("must delete invalid applications before creating new one")
 	}
+	err = ab.spendTxnFees(ctx, createTxnSize)
+	if err != nil {
+		return err
+	}
 
-	appId, err := ab.Client.CreateApplication(ab.AccountCrypt, client.ApproveTeal, client.ClearTeal)
+	start := ab.now()
+	appId, err := ab.createApp(client.ApproveTeal, client.ClearTeal)
+	if err != nil {
+		return ab.observeSubmitError(err)
+	}
+	ab.observeLatency(start)
+	err = ab.awaitFinality(ctx, ab.config.CreateConfirmation, 0)
 	if err != nil {
 		return err
 	}
 
-	ab.AppId = appId
+	ab.setAppId(appId)
+	ab.publishApp(appId, false)
 	return nil
 }
 
 // manageDeletion removes applications tied to the target account, if they
 // don't fulfil the specs of the Algorand buffer (e.g. wrong schema). If
-// the account has several valid applications, then the one with the smallest
-// CreatedAtRound-parameter will be kept. All others will be deleted.
-func (ab *AlgorandBuffer) manageDeletion() error {
-	info, err := ab.Client.AccountInformation(ab.AccountCrypt.Address.String(), context.Background())
+// the account has several valid applications, then the one selected by keptApp
+// will be kept. All others will be deleted, once they have been observed for
+// longer than ManageConfig.ExtraAppGrace. Their state is merged into the kept
+// app first, if configured (see ManageConfig.ConsolidationPolicy). If an AppFilter
+// is configured, apps that don't match it are unrelated to the buffer and are never
+// deleted.
+func (ab *AlgorandBuffer) manageDeletion(ctx context.Context) error {
+	info, err := ab.accountInformation(ctx)
 	if err != nil {
 		return err
 	}
@@ -374,32 +877,124 @@ func (ab *AlgorandBuffer) manageDeletion() error {
 	if len(info.CreatedApps) == 0 {
 		return nil
 	}
-	// Find out if there exists an app that's already "valid" (i.e. right schema)
-	validApp := -1
-	earliestValidApp := uint64(math.MaxUint64)
-	for i, val := range info.CreatedApps {
-		if client.FulfillsSchema(val) && val.CreatedAtRound < earliestValidApp {
-			validApp = i
-			earliestValidApp = val.CreatedAtRound
-		}
+	validApp := ab.keptApp(info.CreatedApps)
+	ab.forgetExtraApps(info.CreatedApps)
+	var merge *consolidation
+	if validApp >= 0 && ab.config.ConsolidationPolicy != DeleteOnly {
+		merge = ab.newConsolidation(info.CreatedApps[validApp])
 	}
 
-	// Delete apps if there's at least one incorrect app
-	if !client.ValidAccount(info) {
-		for i := len(info.CreatedApps) - 1; i >= 0; i-- {
-			if i == validApp {
-				continue
-			}
-			err := ab.Client.DeleteApplication(ab.AccountCrypt, info.CreatedApps[i].Id)
-			if err != nil {
-
+	now := ab.now()
+	for i := len(info.CreatedApps) - 1; i >= 0; i-- {
+		app := info.CreatedApps[i]
+		if i == validApp {
+			continue
+		}
+		valid := ab.isBufferApp(app)
+		if !valid && ab.config.AppFilter != nil {
+			continue
+		}
+		// Extra apps with the right schema might be part of a migration
+		if valid && !ab.extraAppGraceOver(app.Id, now) {
+			continue
+		}
+		if valid && merge != nil {
+			if err := ab.consolidate(ctx, merge, app); err != nil {
 				return err
 			}
 		}
+		if err := ab.deleteApp(ctx, app.Id); err != nil {
+			return err
+		}
+		if app.Id == ab.currentAppId() {
+			ab.publishApp(0, false)
+		}
+	}
+	return nil
+}
+
+// deleteApp deletes the app of the target account with the given ID, after removing
+// the local states of the opted-in accounts (see ManageConfig.OptedInAccounts).
+func (ab *AlgorandBuffer) deleteApp(ctx context.Context, appId uint64) error {
+	err := ab.clearLocalStates(ctx, appId)
+	if err != nil {
+		return err
 	}
+	err = ab.spendFees(ctx, 1)
+	if err != nil {
+		return err
+	}
+	err = ab.Client.DeleteApplication(ab.AccountCrypt, appId)
+	if err != nil {
+		return ab.observeSubmitError(err)
+	}
+	delete(ab.extraApps, appId)
 	return nil
 }
 
+// keptApp returns the index of the valid app the buffer keeps, if the account owns
+// several valid apps. Returns -1, if none of the apps are valid. The pinned app
+// (see ManageConfig.PinnedAppId) is preferred, followed by the app that the buffer
+// currently publishes to. Those only need to fulfil the schema, because they're known
+// to belong to the buffer. Otherwise, the app that passes isBufferApp is chosen by
+// ManageConfig.KeepPolicy.
+func (ab *AlgorandBuffer) keptApp(apps []models.Application) int {
+	for _, preferred := range []uint64{ab.config.PinnedAppId, ab.currentAppId()} {
+		if preferred == 0 {
+			continue
+		}
+		for i, app := range apps {
+			if app.Id == preferred && ab.fulfillsSchema(app) {
+				return i
+			}
+		}
+	}
+
+	kept := -1
+	for i, app := range apps {
+		if ab.isBufferApp(app) && (kept < 0 || ab.config.KeepPolicy.prefers(app, apps[kept])) {
+			kept = i
+		}
+	}
+	return kept
+}
+
+// isBufferApp returns true if the app fulfils the schema of the buffer, and matches
+// the AppFilter (if configured).
+func (ab *AlgorandBuffer) isBufferApp(app models.Application) bool {
+	if !ab.fulfillsSchema(app) {
+		return false
+	}
+	return ab.config.AppFilter == nil || ab.config.AppFilter(app)
+}
+
+// extraAppGraceOver returns true if the extra app with the given ID has been
+// observed for longer than ManageConfig.ExtraAppGrace.
+func (ab *AlgorandBuffer) extraAppGraceOver(id uint64, now time.Time) bool {
+	if ab.config.ExtraAppGrace == 0 {
+		return true
+	}
+	first, ok := ab.extraApps[id]
+	if !ok {
+		ab.extraApps[id] = now
+		return false
+	}
+	return now.Sub(first) >= ab.config.ExtraAppGrace
+}
+
+// forgetExtraApps removes apps that no longer exist from the observed extra apps.
+func (ab *AlgorandBuffer) forgetExtraApps(apps []models.Application) {
+	for id := range ab.extraApps {
+		exists := false
+		for _, app := range apps {
+			exists = exists || app.Id == id
+		}
+		if !exists {
+			delete(ab.extraApps, id)
+		}
+	}
+}
+
 // checkConnection is a helper function that checks node connectivity and
 // verifies that the API token is correct. Ideally this is done on a regular
 // basis and used to monitor the app.
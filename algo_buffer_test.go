@@ -5,11 +5,13 @@ package siam
 import (
 	"context"
 	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
 	"github.com/m2q/algo-siam/client"
 	"github.com/stretchr/testify/assert"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 // If HealthCheck and token verification works, expect no errors
@@ -118,6 +120,50 @@ func TestAlgorandBuffer_DeleteNewest(t *testing.T) {
 	assert.EqualValues(t, 50, c.Account.CreatedApps[0].CreatedAtRound)
 }
 
+// Only the extra apps are deleted, each exactly once
+func TestAlgorandBuffer_DeletedApps(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18, 32)
+	c.Account.CreatedApps[0].CreatedAtRound = 200
+	c.Account.CreatedApps[1].CreatedAtRound = 50
+	c.Account.CreatedApps[2].CreatedAtRound = 150
+
+	_, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	calls := c.CallsOf((*client.AlgorandMock).DeleteApplication)
+	assert.Len(t, calls, 2)
+	deleted := make([]uint64, 0)
+	for _, call := range calls {
+		deleted = append(deleted, call.Args[1].(uint64))
+	}
+	assert.ElementsMatch(t, []uint64{6, 32}, deleted)
+}
+
+func TestAlgorandBuffer_KeepPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy KeepPolicy
+		kept   uint64
+	}{
+		{"oldest, ties by lowest ID", KeepOldest, 18},
+		{"lowest ID", KeepLowestID, 6},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := client.CreateAlgorandClientMock("", "")
+			c.CreateDummyApps(32, 18, 6)
+			c.Account.CreatedApps[0].CreatedAtRound = 50
+			c.Account.CreatedApps[1].CreatedAtRound = 50
+			c.Account.CreatedApps[2].CreatedAtRound = 150
+
+			buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{KeepPolicy: tc.policy})
+			assert.Nil(t, err)
+			assert.True(t, client.ValidAccount(c.Account))
+			assert.EqualValues(t, tc.kept, c.Account.CreatedApps[0].Id)
+			assert.EqualValues(t, tc.kept, buffer.AppId)
+		})
+	}
+}
+
 func TestAlgorandBuffer_Creation(t *testing.T) {
 	c := client.CreateAlgorandClientMock("", "")
 
@@ -178,7 +224,7 @@ func TestAlgorandBuffer_TooMany(t *testing.T) {
 	assert.Nil(t, err)
 
 	err = buffer.PutElements(context.Background(), map[string]string{"x": "y"})
-	assert.Nil(t, err)
+	assert.ErrorIs(t, err, ErrBufferFull)
 
 	// confirm buffer size
 	d, err := buffer.GetBuffer(context.Background())
@@ -258,3 +304,177 @@ func TestAlgorandBuffer_AchieveDesiredState(t *testing.T) {
 	assert.Equal(t, "val", d["0"])
 	assert.Equal(t, "", d[strconv.Itoa(client.GlobalBytes-1)])
 }
+
+// If exclude values are configured, they're sent with the account requests that don't
+// need the created apps. The reconciliation still requests the full account.
+func TestAlgorandBuffer_ExcludeAccountFields(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.Assets = []models.AssetHolding{{AssetId: 1}, {AssetId: 2}}
+	cfg := ManageConfig{ExcludeAccountFields: []string{client.ExcludeAll}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.True(t, client.ValidAccount(c.Account))
+	assert.EqualValues(t, 6, buffer.AppId)
+	assert.Equal(t, 0, c.CallCount((*client.AlgorandMock).AccountInformationExcluding))
+
+	c.ClearCalls()
+	assert.Nil(t, buffer.checkRekey(context.Background()))
+	assert.Equal(t, 1, c.CallCount((*client.AlgorandMock).AccountInformationExcluding))
+	assert.Equal(t, []string{client.ExcludeAll}, c.AccountExclude)
+}
+
+// Extra apps with the right schema are kept until the grace period is over.
+func TestAlgorandBuffer_ExtraAppGrace(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.CreatedApps[0].CreatedAtRound = 50
+	c.Account.CreatedApps[1].CreatedAtRound = 150
+	cfg := ManageConfig{ExtraAppGrace: time.Minute}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 6, buffer.AppId)
+	assert.Len(t, c.Account.CreatedApps, 2)
+
+	now := time.Now()
+	buffer.now = func() time.Time { return now }
+
+	now = now.Add(time.Second * 30)
+	assert.Nil(t, buffer.ReconcileOnce(context.Background()))
+	assert.Len(t, c.Account.CreatedApps, 2)
+
+	now = now.Add(time.Second * 31)
+	assert.Nil(t, buffer.ReconcileOnce(context.Background()))
+	assert.True(t, client.ValidAccount(c.Account))
+	assert.EqualValues(t, 6, c.Account.CreatedApps[0].Id)
+}
+
+// Apps with an invalid schema are deleted right away, even during the grace period.
+func TestAlgorandBuffer_ExtraAppGraceInvalidSchema(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyAppsWithSchema(models.ApplicationStateSchema{}, 6, 18, 32)
+	l, g := client.GenerateSchemasModel()
+	c.Account.CreatedApps[0].Params = models.ApplicationParams{GlobalStateSchema: g, LocalStateSchema: l}
+	c.Account.CreatedApps[1].Params = models.ApplicationParams{GlobalStateSchema: g, LocalStateSchema: l}
+
+	cfg := ManageConfig{ExtraAppGrace: time.Hour}
+	_, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.Len(t, c.Account.CreatedApps, 2)
+	assert.EqualValues(t, 18, c.Account.CreatedApps[1].Id)
+}
+
+// The pinned app is kept, even if it's not the app that was created first.
+func TestAlgorandBuffer_PinnedApp(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18, 32)
+	c.Account.CreatedApps[0].CreatedAtRound = 50
+	c.Account.CreatedApps[1].CreatedAtRound = 150
+	c.Account.CreatedApps[2].CreatedAtRound = 200
+	cfg := ManageConfig{PinnedAppId: 18, ExtraAppGrace: time.Minute}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 18, buffer.AppId)
+
+	now := time.Now().Add(time.Minute)
+	buffer.now = func() time.Time { return now }
+	assert.Nil(t, buffer.ReconcileOnce(context.Background()))
+	assert.True(t, client.ValidAccount(c.Account))
+	assert.EqualValues(t, 18, c.Account.CreatedApps[0].Id)
+}
+
+// storeRecordingMock records the key-value pairs of every store of the buffer.
+type storeRecordingMock struct {
+	*client.AlgorandMock
+	stored [][]models.TealKeyValue
+}
+
+func (m *storeRecordingMock) StoreGlobalsResult(acc crypto.Account, appId uint64, kv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	m.stored = append(m.stored, append([]models.TealKeyValue(nil), kv...))
+	return m.AlgorandMock.StoreGlobalsResult(acc, appId, kv)
+}
+
+// Pairs must be submitted in the order of the input slice, across transactions.
+func TestAlgorandBuffer_PutOrdered(t *testing.T) {
+	c := &storeRecordingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	pairs := make([]KV, 0)
+	for _, k := range []string{"z", "b", "y", "a", "x", "c", "w", "d", "v", "e", "b"} {
+		pairs = append(pairs, KV{Key: k, Value: "v" + strconv.Itoa(len(pairs))})
+	}
+	assert.Nil(t, buffer.PutOrdered(context.Background(), pairs))
+
+	assert.Len(t, c.stored, 2)
+	assert.Len(t, c.stored[0], client.MaxKVArgs)
+	submitted := make([]KV, 0)
+	for _, txn := range c.stored {
+		for _, kv := range txn {
+			submitted = append(submitted, KV{Key: kv.Key, Value: kv.Value.Bytes})
+		}
+	}
+	assert.Equal(t, pairs, submitted)
+
+	// the last value of a duplicate key wins
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "v10", d["b"])
+}
+
+// A key never occurs twice in a transaction, because approval.teal would apply the
+// first value last.
+func TestAlgorandBuffer_PutOrderedDuplicateInBatch(t *testing.T) {
+	c := &storeRecordingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	assert.Nil(t, buffer.PutOrdered(context.Background(), []KV{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "a", Value: "3"}}))
+	assert.Len(t, c.stored, 2)
+	assert.Len(t, c.stored[0], 2)
+	assert.Equal(t, []models.TealKeyValue{{Key: "a", Value: models.TealValue{Bytes: "3"}}}, c.stored[1])
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "3", "b": "2"}, d)
+}
+
+func TestAlgorandBuffer_PutOrderedTooBig(t *testing.T) {
+	c := &storeRecordingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	err = buffer.PutOrdered(context.Background(), []KV{{Key: "k", Value: strings.Repeat("v", 128)}})
+	assert.NotNil(t, err)
+	assert.Len(t, c.stored, 0)
+}
+
+// Every batch of a write is tagged with the note, and gets its own lease
+func TestAlgorandBuffer_PutElementsWithOptions(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	data := make(map[string]string)
+	for i := 0; i < client.MaxKVArgs+1; i++ {
+		data[strconv.Itoa(i)] = "v"
+	}
+	opts := client.WriteOptions{Note: []byte("batch-1"), Lease: [32]byte{1}}
+	assert.Nil(t, buffer.PutElementsWithOptions(context.Background(), data, opts))
+	assert.Len(t, c.Groups, 2)
+	for i, group := range c.Groups {
+		assert.Len(t, group, 2)
+		assert.Equal(t, "put", string(group[0].Note))
+		assert.Equal(t, opts.ForBatch(i).Lease, group[0].Lease)
+		assert.Equal(t, opts.Note, group[1].Note)
+		assert.Empty(t, group[1].ApplicationArgs)
+	}
+	assert.Len(t, c.App.Params.GlobalState, client.MaxKVArgs+1)
+
+	// without options, nothing changes
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "b"}))
+	assert.Len(t, c.Groups, 2)
+}
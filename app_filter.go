@@ -0,0 +1,31 @@
+package siam
+
+import (
+	"encoding/base64"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// CreatedBy returns an AppFilter (see ManageConfig) that matches apps created by the
+// given address.
+func CreatedBy(address string) func(models.Application) bool {
+	return func(app models.Application) bool {
+		return app.Params.Creator == address
+	}
+}
+
+// HasMarker returns an AppFilter (see ManageConfig) that matches apps whose global
+// state holds the given marker key-value pair. Store the marker with PutElements after
+// creating the app, to tell it apart from other apps with the same schema.
+func HasMarker(key, value string) func(models.Application) bool {
+	encodedKey := base64.StdEncoding.EncodeToString([]byte(key))
+	encodedValue := base64.StdEncoding.EncodeToString([]byte(value))
+	return func(app models.Application) bool {
+		for _, kv := range app.Params.GlobalState {
+			if kv.Key == encodedKey && kv.Value.Bytes == encodedValue {
+				return true
+			}
+		}
+		return false
+	}
+}
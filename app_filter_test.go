@@ -0,0 +1,66 @@
+//go:build unit
+
+package siam
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func marker(key, value string) models.TealKeyValue {
+	return models.TealKeyValue{
+		Key:   base64.StdEncoding.EncodeToString([]byte(key)),
+		Value: models.TealValue{Bytes: base64.StdEncoding.EncodeToString([]byte(value))},
+	}
+}
+
+// A decoy app with the right schema but without the marker is neither adopted nor deleted.
+func TestAlgorandBuffer_AppFilterDecoy(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.CreatedApps[0].CreatedAtRound = 50
+	c.Account.CreatedApps[1].CreatedAtRound = 150
+	c.Account.CreatedApps[1].Params.GlobalState = []models.TealKeyValue{marker("oracle", "price-feed")}
+
+	cfg := ManageConfig{AppFilter: HasMarker("oracle", "price-feed")}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 18, buffer.AppId)
+	assert.Len(t, c.Account.CreatedApps, 2)
+}
+
+// Without a filter, the app created first is adopted and the other one is deleted.
+func TestAlgorandBuffer_AppFilterDefault(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.CreatedApps[0].CreatedAtRound = 50
+	c.Account.CreatedApps[1].CreatedAtRound = 150
+	c.Account.CreatedApps[1].Params.GlobalState = []models.TealKeyValue{marker("oracle", "price-feed")}
+
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 6, buffer.AppId)
+	assert.True(t, client.ValidAccount(c.Account))
+}
+
+// Unrelated apps don't prevent the creation of the buffer's app.
+func TestAlgorandBuffer_AppFilterCreation(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyAppsWithSchema(models.ApplicationStateSchema{}, 6)
+	c.Account.CreatedApps[0].Params.Creator = "someone"
+
+	cfg := ManageConfig{AppFilter: CreatedBy("someone")}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 4512, buffer.AppId)
+}
+
+func TestCreatedBy(t *testing.T) {
+	app := models.Application{Params: models.ApplicationParams{Creator: "creator"}}
+	assert.True(t, CreatedBy("creator")(app))
+	assert.False(t, CreatedBy("other")(app))
+}
@@ -0,0 +1,37 @@
+package siam
+
+// managedApp is the app the buffer manages, as returned by AppID.
+type managedApp struct {
+	id    uint64
+	valid bool
+}
+
+// publishApp atomically replaces the app returned by AppID.
+func (ab *AlgorandBuffer) publishApp(id uint64, valid bool) {
+	ab.managed.Store(managedApp{id: id, valid: valid})
+}
+
+// AppID returns the ID of the app the buffer manages, and whether the target account
+// has reached a valid state with it. The management loop (see Manage) updates both
+// together whenever it creates or deletes the app, so unlike the AppId field, it's
+// safe to call concurrently with the loop. Returns 0 and false while the buffer has
+// no app.
+func (ab *AlgorandBuffer) AppID() (uint64, bool) {
+	app, _ := ab.managed.Load().(managedApp)
+	return app.id, app.valid
+}
+
+// currentAppId returns the ID of the app the buffer publishes to. Unlike the AppId
+// field, it's safe to call concurrently with the management loop, so the buffer uses
+// it for all of its requests.
+func (ab *AlgorandBuffer) currentAppId() uint64 {
+	id, _ := ab.appId.Load().(uint64)
+	return id
+}
+
+// storeAppId sets the app the buffer publishes to. The AppId field is kept in sync
+// for callers that read it directly.
+func (ab *AlgorandBuffer) storeAppId(id uint64) {
+	ab.appId.Store(id)
+	ab.AppId = id
+}
@@ -0,0 +1,109 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_AppID(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	id, valid := buffer.AppID()
+	assert.EqualValues(t, 4512, id)
+	assert.True(t, valid)
+
+	// the app vanished, and a new one can't be created
+	c.Account.CreatedApps = nil
+	c.SetError(true, (*client.AlgorandMock).CreateApplication)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	id, valid = buffer.AppID()
+	assert.EqualValues(t, 0, id)
+	assert.False(t, valid)
+
+	c.ClearFunctionErrors()
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	id, valid = buffer.AppID()
+	assert.EqualValues(t, 4512, id)
+	assert.True(t, valid)
+}
+
+// Replacing an invalid app updates the app ID
+func TestAlgorandBuffer_AppIDAfterDeletion(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	id, _ := buffer.AppID()
+	assert.EqualValues(t, 6, id)
+
+	c.AddDummyApps(18)
+	c.Account.CreatedApps[0].Params.GlobalStateSchema = models.ApplicationStateSchema{}
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	id, valid := buffer.AppID()
+	assert.EqualValues(t, 18, id)
+	assert.True(t, valid)
+}
+
+// AppID can be read while the management loop runs (run with -race)
+func TestAlgorandBuffer_AppIDConcurrent(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			id, valid := buffer.AppID()
+			assert.True(t, id != 0 || !valid)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, buffer.manageCycle(context.Background()))
+	}
+	wg.Wait()
+}
+
+// Writes and reconciliations can run concurrently with each other, while the buffer
+// replaces its app and observes extra apps (run with -race)
+func TestAlgorandBuffer_ReconcileConcurrent(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	buffer.config.ExtraAppGrace = time.Minute
+	_, err := l.CreateApplication(buffer.AccountCrypt, client.ApproveTeal, client.ClearTeal)
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 2; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				// fails if the app is deleted in the meantime
+				_ = buffer.ReconcileOnce(context.Background())
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		id, _ := buffer.AppID()
+		_ = l.DeleteApplication(buffer.AccountCrypt, id)
+		_ = buffer.PutElements(context.Background(), map[string]string{"k": strconv.Itoa(i)})
+	}
+	wg.Wait()
+
+	assert.Nil(t, buffer.ReconcileOnce(context.Background()))
+	id, valid := buffer.AppID()
+	assert.True(t, valid)
+	assert.Equal(t, buffer.AppId, id)
+}
@@ -0,0 +1,106 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// PutElementsAtomic stores the given key-value pairs like PutElements, but submits
+// the transactions in atomic groups: either all pairs of a group are stored, or none.
+// A group holds up to ManageConfig.GroupSize transactions of ManageConfig.BatchSize
+// pairs, i.e. up to AtomicKeyLimit pairs (128 by default). Writes that need more
+// transactions are split across several groups, which are submitted one after another.
+// Operations across groups are NOT atomic: if a group fails, the groups before it
+// remain stored. With ManageConfig.StrictAtomic, such writes return ErrGroupTooLarge
+// instead, so that every write is atomic as a whole.
+//
+// Empty values are stored regardless of ManageConfig.EmptyValuePolicy, and writes
+// aren't suppressed by ManageConfig.MinWriteInterval, since that would break atomicity.
+func (ab *AlgorandBuffer) PutElementsAtomic(ctx context.Context, data map[string]string) error {
+	m := make(map[string][]byte, len(data))
+	for k, v := range data {
+		value, err := ab.config.ValueEncoding.decode(v)
+		if err != nil {
+			return err
+		}
+		m[k] = value
+	}
+	batches, err := ab.rawBatches(m)
+	if err != nil {
+		return err
+	}
+	return ab.storeGroups(ctx, batches, ab.config.StrictAtomic)
+}
+
+// storeGroups submits one transaction for each batch, in atomic groups of
+// ManageConfig.GroupSize transactions. If strict is set, batches that don't fit into a
+// single group return ErrGroupTooLarge instead.
+func (ab *AlgorandBuffer) storeGroups(ctx context.Context, batches [][]models.TealKeyValue, strict bool) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	if err := ab.beginWrite(); err != nil {
+		return err
+	}
+	defer ab.active.Done()
+	if err := ab.validateSpec(batches); err != nil {
+		return err
+	}
+	if err := ab.checkCapacity(ctx, batches); err != nil {
+		return err
+	}
+	batches, err := ab.withModified(ctx, batches)
+	if err != nil {
+		return err
+	}
+	if strict && len(batches) > ab.groupSize() {
+		return fmt.Errorf("%w: %d transactions, at most %d", ErrGroupTooLarge, len(batches), ab.groupSize())
+	}
+	if err := ab.spendTxnFees(ctx, writeTxnSizes(batches, nil)...); err != nil {
+		return err
+	}
+	results := make([]models.PendingTransactionInfoResponse, 0, len(batches))
+	defer func() { ab.observeResults(&ab.metrics.storeTxns, results) }()
+	for _, group := range partitionBatches(batches, ab.groupSize()) {
+		start := ab.now()
+		infos, err := client.StoreGlobalsGroup(ab.Client, ab.AccountCrypt, ab.currentAppId(), group)
+		if err != nil {
+			return ab.observeSubmitError(err)
+		}
+		ab.observeLatency(start)
+		for _, kvArray := range group {
+			ab.recordWrites(kvArray, start)
+			ab.trackKeys(kvArray)
+		}
+		results = append(results, infos...)
+	}
+	if err := ab.awaitStoreFinality(ctx, results); err != nil {
+		return err
+	}
+	ab.warnNearLimits(ctx, batches)
+	return nil
+}
+
+// AtomicKeyLimit returns the maximum number of key-value pairs that PutElementsAtomic
+// stores in a single atomic group: ManageConfig.GroupSize transactions of
+// ManageConfig.BatchSize pairs each. With ManageConfig.TrackModified, every pair takes
+// two slots, so the limit is halved.
+func (ab *AlgorandBuffer) AtomicKeyLimit() int {
+	return ab.groupSize() * ab.batchSize()
+}
+
+// partitionBatches splits the batches into consecutive groups of at most size batches.
+func partitionBatches(batches [][]models.TealKeyValue, size int) [][][]models.TealKeyValue {
+	groups := make([][][]models.TealKeyValue, 0, (len(batches)+size-1)/size)
+	for len(batches) > size {
+		groups = append(groups, batches[:size])
+		batches = batches[size:]
+	}
+	if len(batches) > 0 {
+		groups = append(groups, batches)
+	}
+	return groups
+}
@@ -0,0 +1,114 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func atomicData(n int) map[string]string {
+	data := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		data[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+	return data
+}
+
+// Large atomic writes are split into groups of the configured size, each with a
+// valid group ID.
+func TestAlgorandBuffer_PutElementsAtomic(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	cfg := ManageConfig{BatchSize: 2, GroupSize: 4}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	data := atomicData(36)
+	assert.Nil(t, buffer.PutElementsAtomic(context.Background(), data))
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, stored)
+
+	// 18 transactions are split into 4 + 4 + 4 + 4 + 2
+	assert.Len(t, c.Groups, 5)
+	for i, group := range c.Groups {
+		if i < 4 {
+			assert.Len(t, group, 4)
+		} else {
+			assert.Len(t, group, 2)
+		}
+		ungrouped := make([]types.Transaction, len(group))
+		copy(ungrouped, group)
+		for j := range ungrouped {
+			ungrouped[j].Group = types.Digest{}
+		}
+		gid, err := crypto.ComputeGroupID(ungrouped)
+		assert.Nil(t, err)
+		for _, txn := range group {
+			assert.Equal(t, gid, txn.Group)
+			assert.LessOrEqual(t, len(txn.ApplicationArgs), 2*cfg.BatchSize)
+		}
+	}
+	assert.NotEqual(t, c.Groups[0][0].Group, c.Groups[1][0].Group)
+}
+
+// If a transaction of a group is rejected, no pair of the group is stored.
+func TestAlgorandBuffer_PutElementsAtomicRejected(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	buffer.config.BatchSize = 4
+	assert.Nil(t, buffer.PutElements(context.Background(), atomicData(60)))
+
+	// the second transaction exceeds the schema
+	data := map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6", "g": "7", "h": "8"}
+	assert.NotNil(t, buffer.PutElementsAtomic(context.Background(), data))
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, stored, 60)
+
+	assert.Nil(t, buffer.PutElementsAtomic(context.Background(), map[string]string{"a": "1", "b": "2"}))
+	stored, err = buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, stored, 62)
+}
+
+// With StrictAtomic, writes that don't fit into a single group are rejected before
+// any transaction is submitted.
+func TestAlgorandBuffer_PutElementsAtomicStrict(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	cfg := ManageConfig{BatchSize: 2, GroupSize: 4, StrictAtomic: true}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, buffer.AtomicKeyLimit())
+
+	err = buffer.PutElementsAtomic(context.Background(), atomicData(9))
+	assert.ErrorIs(t, err, ErrGroupTooLarge)
+	assert.Len(t, c.Groups, 0)
+	assert.Len(t, c.App.Params.GlobalState, 0)
+
+	data := atomicData(8)
+	assert.Nil(t, buffer.PutElementsAtomic(context.Background(), data))
+	assert.Len(t, c.Groups, 1)
+	assert.Len(t, c.Groups[0], 4)
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, stored)
+}
+
+func TestAlgorandBuffer_AtomicKeyLimit(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	assert.Equal(t, client.MaxGroupSize*client.MaxKVArgs, buffer.AtomicKeyLimit())
+	buffer.config.TrackModified = true
+	assert.Equal(t, client.MaxGroupSize*client.MaxKVArgs/2, buffer.AtomicKeyLimit())
+}
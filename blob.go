@@ -0,0 +1,187 @@
+package siam
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// A blob is a value that is too large for a single key of the global state. It's
+// split into chunks that are stored under the keys "<name>/0", "<name>/1", ... The
+// header key "<name>/h" holds the number of chunks (2 bytes) and the total length
+// of the blob (4 bytes), both big-endian.
+const (
+	blobHeaderSuffix = "/h"
+	blobHeaderLength = 6
+)
+
+// ErrBlobNotFound is returned if the global state has no header for a blob.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// ErrBlobIncomplete is returned if chunks of a blob are missing, or if the chunks
+// don't match the header. This happens when a blob has been partially written.
+var ErrBlobIncomplete = errors.New("blob is incomplete")
+
+// blobHeaderKey returns the key of the blob header.
+func blobHeaderKey(name string) string {
+	return name + blobHeaderSuffix
+}
+
+// blobChunkKey returns the key of the i-th chunk of the blob.
+func blobChunkKey(name string, i int) string {
+	return name + "/" + strconv.Itoa(i)
+}
+
+// ErrBlobTooLarge is returned by SplitValue, if a value needs more chunks than a blob
+// header can count.
+var ErrBlobTooLarge = errors.New("blob too large")
+
+// SplitValue splits a value that exceeds MaxValueBytes into chunks, and returns them as
+// a blob with the given name: the chunks are keyed "<name>/0", "<name>/1", ..., and
+// "<name>/h" holds the header. Every chunk fills its key-value pair up to MaxValueBytes.
+// The keys are global state keys, so store the result with PutElementsRaw of a buffer
+// with raw keys (see ManageConfig.KeyEncoding), and read it back with ReassembleBlob.
+// Returns ErrKeyTooLong if the name leaves no room for the chunks.
+func SplitValue(name string, value []byte) (map[string][]byte, error) {
+	header := blobHeaderKey(name)
+	if len(header) > MaxKeyBytes {
+		return nil, fmt.Errorf("%w {%s}: key cannot exceed %d bytes", ErrKeyTooLong, header, MaxKeyBytes)
+	}
+	blob := make(map[string][]byte)
+	for rest := value; len(rest) > 0; {
+		chunk := len(blob)
+		if chunk == math.MaxUint16 {
+			return nil, fmt.Errorf("%w {%s}: %d bytes", ErrBlobTooLarge, name, len(value))
+		}
+		key := blobChunkKey(name, chunk)
+		if len(key) > MaxKeyBytes {
+			return nil, fmt.Errorf("%w {%s}: key cannot exceed %d bytes", ErrKeyTooLong, key, MaxKeyBytes)
+		}
+		n := MaxValueBytes - len(key)
+		if n > len(rest) {
+			n = len(rest)
+		}
+		blob[key] = rest[:n]
+		rest = rest[n:]
+	}
+	blob[header] = encodeBlobHeader(len(blob), len(value))
+	return blob, nil
+}
+
+// encodeBlobHeader returns the header value of a blob.
+func encodeBlobHeader(chunks int, length int) []byte {
+	header := make([]byte, blobHeaderLength)
+	binary.BigEndian.PutUint16(header, uint16(chunks))
+	binary.BigEndian.PutUint32(header[2:], uint32(length))
+	return header
+}
+
+// ReassembleBlob reconstructs the blob with the given name from a global state with
+// raw keys (e.g. from client.ReadGlobalState). It validates that all chunks exist,
+// and that their total length matches the header. Returns ErrBlobNotFound if the
+// header is missing, or ErrBlobIncomplete if the blob is incomplete.
+func ReassembleBlob(state map[string][]byte, name string) ([]byte, error) {
+	header, ok := state[blobHeaderKey(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w {%s}", ErrBlobNotFound, name)
+	}
+	if len(header) != blobHeaderLength {
+		return nil, fmt.Errorf("%w {%s}: invalid header", ErrBlobIncomplete, name)
+	}
+	chunks := int(binary.BigEndian.Uint16(header))
+	length := int(binary.BigEndian.Uint32(header[2:]))
+
+	blob := make([]byte, 0, length)
+	for i := 0; i < chunks; i++ {
+		chunk, ok := state[blobChunkKey(name, i)]
+		if !ok {
+			return nil, fmt.Errorf("%w {%s}: chunk %d is missing", ErrBlobIncomplete, name, i)
+		}
+		blob = append(blob, chunk...)
+	}
+	if len(blob) != length {
+		return nil, fmt.Errorf("%w {%s}: expected %d bytes, got %d", ErrBlobIncomplete, name, length, len(blob))
+	}
+	return blob, nil
+}
+
+// PutBlob stores data as a blob with the given name (see SplitValue), e.g. a JSON
+// document that exceeds MaxValueBytes. The chunks and the header are stored in a
+// single atomic group (see PutElementsAtomic), with the header in the last
+// transaction, so a failed write doesn't leave a partial blob behind. Chunks of a
+// larger blob previously stored under the name are deleted afterwards. The blob takes
+// a key per chunk and one for the header, and the keys aren't affected by
+// ManageConfig.KeyEncoding. Returns ErrBufferFull if the keys exceed the capacity of
+// the buffer, or ErrGroupTooLarge if they exceed AtomicKeyLimit, regardless of
+// ManageConfig.StrictAtomic.
+func (ab *AlgorandBuffer) PutBlob(ctx context.Context, name string, data []byte) error {
+	blob, err := SplitValue(name, data)
+	if err != nil {
+		return err
+	}
+	state, err := ab.userState(ctx)
+	if err != nil {
+		return err
+	}
+	chunks := len(blob) - 1
+	pairs := make([]KV, 0, len(blob))
+	for i := 0; i < chunks; i++ {
+		key := blobChunkKey(name, i)
+		pairs = append(pairs, KV{Key: key, Value: string(blob[key])})
+	}
+	header := blobHeaderKey(name)
+	pairs = append(pairs, KV{Key: header, Value: string(blob[header])})
+
+	partitions := partitionPairs(pairs, ab.batchSize())
+	batches := make([][]models.TealKeyValue, 0, len(partitions))
+	for _, p := range partitions {
+		kvArray := make([]models.TealKeyValue, 0, len(p))
+		for _, kv := range p {
+			kvArray = append(kvArray, models.TealKeyValue{Key: kv.Key, Value: models.TealValue{Bytes: kv.Value}})
+		}
+		batches = append(batches, kvArray)
+	}
+	if err := ab.storeGroups(ctx, batches, true); err != nil {
+		return err
+	}
+
+	stale := make([]string, 0)
+	for key := range state {
+		if i, ok := blobChunkIndex(name, key); ok && i >= chunks {
+			stale = append(stale, ab.config.KeyEncoding.fromState([]byte(key)))
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return ab.DeleteElements(ctx, stale...)
+}
+
+// GetBlob returns the blob with the given name (see PutBlob). Returns ErrBlobNotFound if
+// no blob is stored under the name, or ErrBlobIncomplete if it has been partially
+// written (see ReassembleBlob).
+func (ab *AlgorandBuffer) GetBlob(ctx context.Context, name string) ([]byte, error) {
+	if ab.isStopped() {
+		return nil, ErrStopped
+	}
+	state, err := ab.userState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ReassembleBlob(state, name)
+}
+
+// blobChunkIndex returns the index of the chunk, if key is a chunk key of the blob.
+func blobChunkIndex(name string, key string) (int, bool) {
+	if !strings.HasPrefix(key, name+"/") {
+		return 0, false
+	}
+	i, err := strconv.Atoi(key[len(name)+1:])
+	return i, err == nil && i >= 0 && blobChunkKey(name, i) == key
+}
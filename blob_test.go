@@ -0,0 +1,184 @@
+//go:build unit
+
+package siam
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blobState returns a global state that holds the given chunks as a blob.
+func blobState(name string, chunks ...string) map[string][]byte {
+	state := map[string][]byte{"other": []byte("value")}
+	length := 0
+	for i, c := range chunks {
+		state[blobChunkKey(name, i)] = []byte(c)
+		length += len(c)
+	}
+	state[blobHeaderKey(name)] = encodeBlobHeader(len(chunks), length)
+	return state
+}
+
+func TestReassembleBlob(t *testing.T) {
+	state := blobState("doc", `{"price":`, `42,"ts":`, `1650000000}`)
+	blob, err := ReassembleBlob(state, "doc")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"price":42,"ts":1650000000}`, string(blob))
+
+	blob, err = ReassembleBlob(blobState("empty"), "empty")
+	assert.Nil(t, err)
+	assert.Len(t, blob, 0)
+}
+
+func TestReassembleBlob_MissingChunk(t *testing.T) {
+	state := blobState("doc", "aaa", "bbb", "ccc")
+	delete(state, blobChunkKey("doc", 1))
+	_, err := ReassembleBlob(state, "doc")
+	assert.ErrorIs(t, err, ErrBlobIncomplete)
+}
+
+func TestReassembleBlob_LengthMismatch(t *testing.T) {
+	state := blobState("doc", "aaa", "bbb")
+	state[blobChunkKey("doc", 1)] = []byte("b")
+	_, err := ReassembleBlob(state, "doc")
+	assert.ErrorIs(t, err, ErrBlobIncomplete)
+
+	state[blobHeaderKey("doc")] = []byte{1}
+	_, err = ReassembleBlob(state, "doc")
+	assert.ErrorIs(t, err, ErrBlobIncomplete)
+}
+
+func TestReassembleBlob_NotFound(t *testing.T) {
+	_, err := ReassembleBlob(blobState("doc", "aaa"), "other")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestSplitValue(t *testing.T) {
+	value := bytes.Repeat([]byte("0123456789"), 100)
+	blob, err := SplitValue("doc", value)
+	assert.Nil(t, err)
+	// nine chunks of up to 123 bytes next to their keys, and the header
+	assert.Len(t, blob, 10)
+	for k, v := range blob {
+		assert.LessOrEqual(t, len(k)+len(v), MaxValueBytes, k)
+	}
+	reassembled, err := ReassembleBlob(blob, "doc")
+	assert.Nil(t, err)
+	assert.Equal(t, value, reassembled)
+
+	blob, err = SplitValue("empty", nil)
+	assert.Nil(t, err)
+	reassembled, err = ReassembleBlob(blob, "empty")
+	assert.Nil(t, err)
+	assert.Len(t, reassembled, 0)
+
+	_, err = SplitValue(strings.Repeat("n", MaxKeyBytes-1), value)
+	assert.ErrorIs(t, err, ErrKeyTooLong)
+}
+
+// Split values can be stored and read back with a buffer.
+func TestSplitValue_Store(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	value := []byte(strings.Repeat("x", 300))
+	blob, err := SplitValue("doc", value)
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.PutElementsRaw(context.Background(), blob))
+	state, err := buffer.GetBufferRaw(context.Background())
+	assert.Nil(t, err)
+	reassembled, err := ReassembleBlob(state, "doc")
+	assert.Nil(t, err)
+	assert.Equal(t, value, reassembled)
+}
+
+func TestAlgorandBuffer_PutBlob(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	doc := []byte(`{"prices":[` + strings.Repeat(`{"pair":"ALGO/USD","price":0.42},`, 64) + `{}]}`)
+	assert.Nil(t, buffer.PutBlob(context.Background(), "doc", doc))
+	assert.Len(t, c.Groups, 1)
+	blob, err := buffer.GetBlob(context.Background(), "doc")
+	assert.Nil(t, err)
+	assert.Equal(t, doc, blob)
+
+	// chunks of the previous blob are deleted
+	assert.Nil(t, buffer.PutBlob(context.Background(), "doc", []byte("short")))
+	blob, err = buffer.GetBlob(context.Background(), "doc")
+	assert.Nil(t, err)
+	assert.Equal(t, "short", string(blob))
+	state, _ := buffer.GetBufferRaw(context.Background())
+	assert.Len(t, state, 2)
+
+	_, err = buffer.GetBlob(context.Background(), "other")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestAlgorandBuffer_PutBlobCapacity(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	err = buffer.PutBlob(context.Background(), "doc", bytes.Repeat([]byte("x"), 64*MaxValueBytes))
+	assert.ErrorIs(t, err, ErrBufferFull)
+	assert.Len(t, c.App.Params.GlobalState, 0)
+}
+
+// Blobs that don't fit into a single atomic group aren't split across groups.
+func TestAlgorandBuffer_PutBlobGroupTooLarge(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{GroupSize: 2})
+	assert.Nil(t, err)
+	assert.False(t, buffer.config.StrictAtomic)
+
+	// 25 chunks and the header need 4 transactions
+	err = buffer.PutBlob(context.Background(), "doc", bytes.Repeat([]byte("x"), 25*(MaxValueBytes-len("doc/00"))))
+	assert.ErrorIs(t, err, ErrGroupTooLarge)
+	assert.Len(t, c.Groups, 0)
+	assert.Len(t, c.App.Params.GlobalState, 0)
+
+	// blobs that fit are still stored
+	assert.Nil(t, buffer.PutBlob(context.Background(), "doc", bytes.Repeat([]byte("x"), 500)))
+	assert.Len(t, c.Groups, 1)
+}
+
+// Blobs with missing chunks are detected.
+func TestAlgorandBuffer_GetBlobIncomplete(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	assert.Nil(t, buffer.PutBlob(context.Background(), "doc", bytes.Repeat([]byte("x"), 500)))
+	assert.Nil(t, buffer.DeleteElements(context.Background(), blobChunkKey("doc", 2)))
+	_, err = buffer.GetBlob(context.Background(), "doc")
+	assert.ErrorIs(t, err, ErrBlobIncomplete)
+}
+
+func TestBlobChunkIndex(t *testing.T) {
+	i, ok := blobChunkIndex("doc", "doc/12")
+	assert.True(t, ok)
+	assert.Equal(t, 12, i)
+	for _, key := range []string{"doc/h", "doc/01", "doc/-1", "docs/1", "doc"} {
+		_, ok = blobChunkIndex("doc", key)
+		assert.False(t, ok, key)
+	}
+}
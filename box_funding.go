@@ -0,0 +1,74 @@
+package siam
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/m2q/algo-siam/client"
+)
+
+// BoxSpec declares a box of the buffer's app (see ManageConfig.Boxes).
+type BoxSpec struct {
+	// Name is the name of the box.
+	Name string
+
+	// Size is the size of the box in bytes.
+	Size uint64
+}
+
+// AppAccountMinBalance returns the minimum balance in microAlgos the account of the
+// buffer's app has to keep for the boxes of ManageConfig.Boxes.
+func (ab *AlgorandBuffer) AppAccountMinBalance() uint64 {
+	mbr := uint64(client.MinBalance)
+	for _, box := range ab.config.Boxes {
+		mbr += client.BoxMinBalance(box.Name, box.Size)
+	}
+	return mbr
+}
+
+// FundBoxes tops up the account of the buffer's app to AppAccountMinBalance, if its
+// balance is lower. The payment is sent by ManageConfig.BoxTreasury, or by the target
+// account. Returns an error of type BoxesUnsupported, if the node doesn't support
+// box storage.
+func (ab *AlgorandBuffer) FundBoxes(ctx context.Context) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	if err := ab.RequireBoxes(ctx); err != nil {
+		return err
+	}
+	appAddr := crypto.GetApplicationAddress(ab.currentAppId()).String()
+	infoCtx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	info, err := ab.Client.AccountInformation(appAddr, infoCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	required := ab.AppAccountMinBalance()
+	if info.Amount >= required {
+		return nil
+	}
+
+	payer := ab.AccountCrypt
+	if ab.config.BoxTreasury != nil {
+		payer = *ab.config.BoxTreasury
+	}
+	if err := ab.spendFees(ctx, 1); err != nil {
+		return err
+	}
+	params, err := ab.SuggestedParams(ctx)
+	if err != nil {
+		return err
+	}
+	txn, err := future.MakePaymentTxn(payer.Address.String(), appAddr, required-info.Amount, nil, "", params)
+	if err != nil {
+		return err
+	}
+	ctx, cancel = context.WithTimeout(ctx, ab.writeTimeout())
+	defer cancel()
+	if _, err := ab.Client.ExecuteTransaction(payer, txn, ctx); err != nil {
+		return ab.observeSubmitError(err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_FundBoxes(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	buffer.config.Boxes = []BoxSpec{{Name: "scores", Size: 1024}}
+	buffer.config.AutoFundBoxes = true
+	required := uint64(client.MinBalance + client.BoxFlatMinBalance + client.BoxByteMinBalance*(6+1024))
+	assert.Equal(t, required, buffer.AppAccountMinBalance())
+
+	appAddr := crypto.GetApplicationAddress(buffer.AppId)
+	before := l.Balance(buffer.AccountCrypt.Address)
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, required, l.Balance(appAddr))
+	assert.Equal(t, before-required-client.MinTxnFee, l.Balance(buffer.AccountCrypt.Address))
+
+	// a funded app account isn't topped up again
+	assert.Nil(t, buffer.FundBoxes(context.Background()))
+	assert.Equal(t, required, l.Balance(appAddr))
+
+	// larger boxes are topped up by the difference
+	buffer.config.Boxes = append(buffer.config.Boxes, BoxSpec{Name: "logs", Size: 100})
+	assert.Nil(t, buffer.FundBoxes(context.Background()))
+	assert.Equal(t, buffer.AppAccountMinBalance(), l.Balance(appAddr))
+}
+
+func TestAlgorandBuffer_FundBoxesFromTreasury(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	treasury := crypto.GenerateAccount()
+	l.Fund(treasury.Address, 1000000)
+	buffer.config.Boxes = []BoxSpec{{Name: "scores", Size: 64}}
+	buffer.config.BoxTreasury = &treasury
+
+	before := l.Balance(buffer.AccountCrypt.Address)
+	assert.Nil(t, buffer.FundBoxes(context.Background()))
+	required := buffer.AppAccountMinBalance()
+	assert.Equal(t, required, l.Balance(crypto.GetApplicationAddress(buffer.AppId)))
+	assert.Equal(t, 1000000-required-client.MinTxnFee, l.Balance(treasury.Address))
+	assert.Equal(t, before, l.Balance(buffer.AccountCrypt.Address))
+}
+
+// A successful payment isn't counted as a failed transaction.
+func TestAlgorandBuffer_FundBoxesMetrics(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	metrics := &recordingMetrics{}
+	buffer.config.Metrics = metrics
+	buffer.config.Boxes = []BoxSpec{{Name: "scores", Size: 64}}
+	assert.Nil(t, buffer.FundBoxes(context.Background()))
+	assert.Equal(t, buffer.AppAccountMinBalance(), l.Balance(crypto.GetApplicationAddress(buffer.AppId)))
+	assert.EqualValues(t, 0, buffer.metrics.failedTxns)
+	assert.Empty(t, metrics.failed)
+}
+
+func TestAlgorandBuffer_FundBoxesUnsupported(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.NodeStatus.LastVersion = "https://github.com/algorandfoundation/specs/tree/bc36005dbd776e6d1eaf0c560619bb183215645c"
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	buffer.config.Boxes = []BoxSpec{{Name: "scores", Size: 64}}
+	var unsupported *BoxesUnsupported
+	assert.ErrorAs(t, buffer.FundBoxes(context.Background()), &unsupported)
+}
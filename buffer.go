@@ -0,0 +1,41 @@
+package siam
+
+import "context"
+
+// Buffer is the key-value store of an AlgorandBuffer, without the management of its
+// application. Depend on it instead of *AlgorandBuffer to inject an InMemoryBuffer in
+// the tests of code that consumes a buffer.
+type Buffer interface {
+	// GetBuffer returns the stored key-value pairs.
+	GetBuffer(ctx context.Context) (map[string]string, error)
+
+	// GetBufferRaw returns the stored key-value pairs, with []byte values.
+	GetBufferRaw(ctx context.Context) (map[string][]byte, error)
+
+	// GetBytes returns the bytes stored under key. Returns ErrKeyNotFound, if the key
+	// isn't stored.
+	GetBytes(ctx context.Context, key string) ([]byte, error)
+
+	// PutElements stores the given key-value pairs. Existing keys are overridden.
+	PutElements(ctx context.Context, data map[string]string) error
+
+	// PutElementsRaw stores the given key-value pairs, with []byte values.
+	PutElementsRaw(ctx context.Context, data map[string][]byte) error
+
+	// DeleteElements deletes the given keys.
+	DeleteElements(ctx context.Context, keys ...string) error
+
+	// Contains returns true if all given key-value pairs are stored.
+	Contains(ctx context.Context, m map[string]string) (bool, error)
+
+	// AchieveDesiredState turns the stored pairs into the desired ones.
+	AchieveDesiredState(ctx context.Context, desired map[string]string) error
+
+	// Capacity returns the number of stored keys and the maximum number of keys.
+	Capacity(ctx context.Context) (used int, total int, err error)
+}
+
+var (
+	_ Buffer = (*AlgorandBuffer)(nil)
+	_ Buffer = (*InMemoryBuffer)(nil)
+)
@@ -0,0 +1,74 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// Capacity returns the number of keys stored in the buffer, and the number of keys it
+// can hold. Reserved keys (e.g. HeartbeatKey) and their slots don't count. Writes that
+// would store more than total keys return ErrBufferFull.
+func (ab *AlgorandBuffer) Capacity(ctx context.Context) (used int, total int, err error) {
+	if ab.isStopped() {
+		return 0, 0, ErrStopped
+	}
+	state, err := ab.userState(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(state), ab.capacity(), nil
+}
+
+// userState returns the global state of the application with state keys, without
+// reserved and companion keys.
+func (ab *AlgorandBuffer) userState(ctx context.Context) (map[string][]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	state, err := client.ReadGlobalState(ab.Client, ab.currentAppId(), ctx)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range ab.reservedKeys() {
+		delete(state, key)
+	}
+	for key := range state {
+		if ab.isCompanionKey(key) {
+			delete(state, key)
+		}
+	}
+	return state, nil
+}
+
+// checkCapacity returns ErrBufferFull, if storing the batches would exceed the
+// capacity of the buffer, unless keys are evicted to make room (see
+// ManageConfig.EvictionPolicy). Only keys that aren't stored yet take up capacity. The
+// state is read before every write, so that keys written by others are accounted for.
+func (ab *AlgorandBuffer) checkCapacity(ctx context.Context, batches [][]models.TealKeyValue) error {
+	state, err := ab.userState(ctx)
+	if err != nil {
+		return err
+	}
+	added := make(map[string]bool)
+	for _, kvArray := range batches {
+		for _, kv := range kvArray {
+			if _, ok := state[kv.Key]; !ok {
+				added[kv.Key] = true
+			}
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	excess := len(state) + len(added) - ab.capacity()
+	if excess <= 0 {
+		return nil
+	}
+	if ab.config.EvictionPolicy != EvictNone && len(added) <= ab.capacity() {
+		return ab.evictFor(ctx, state, batches, excess)
+	}
+	return fmt.Errorf("%w: %d new keys exceed the remaining capacity of %d keys",
+		ErrBufferFull, len(added), ab.capacity()-len(state))
+}
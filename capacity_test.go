@@ -0,0 +1,48 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// A write that exceeds the capacity writes nothing, while updates of stored keys
+// don't take up capacity.
+func TestAlgorandBuffer_BufferFull(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	cfg := ManageConfig{HeartbeatInterval: time.Minute}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	used, total, err := buffer.Capacity(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, used)
+	assert.Equal(t, client.GlobalBytes-1, total)
+
+	data := make(map[string]string)
+	for i := 0; i < total-2; i++ {
+		data[strconv.Itoa(i)] = "v"
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+	used, _, err = buffer.Capacity(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, total-2, used)
+
+	err = buffer.PutElements(context.Background(), map[string]string{"0": "w", "a": "1", "b": "2", "c": "3"})
+	assert.ErrorIs(t, err, ErrBufferFull)
+	err = buffer.PutElementsAtomic(context.Background(), map[string]string{"a": "1", "b": "2", "c": "3"})
+	assert.ErrorIs(t, err, ErrBufferFull)
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, stored)
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"0": "w", "a": "1", "b": "2"}))
+	used, total, err = buffer.Capacity(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, total, used)
+}
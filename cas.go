@@ -0,0 +1,39 @@
+package siam
+
+import "context"
+
+// PutElementsCAS stores the desired key-value pairs like PutElements, but only if the
+// current value of each key equals the expected value (compare-and-swap). Keys of
+// desired that are absent from expected must not currently exist. Keys of expected
+// that aren't in desired are only compared. Returns false without writing, if a
+// comparison fails. Use it to build read-modify-write cycles on top of the buffer.
+//
+// The comparison uses a fresh read of the global state right before the transactions
+// are built. Writes of other writers between the read and the confirmation of the
+// transactions aren't detected, so the window for lost updates is small, but not zero.
+func (ab *AlgorandBuffer) PutElementsCAS(ctx context.Context, expected, desired map[string]string) (bool, error) {
+	if err := ab.checkWritable(); err != nil {
+		return false, err
+	}
+	current, err := ab.GetBuffer(ctx)
+	if err != nil {
+		return false, err
+	}
+	for k, v := range expected {
+		if actual, ok := current[k]; !ok || actual != v {
+			return false, nil
+		}
+	}
+	for k := range desired {
+		if _, ok := expected[k]; ok {
+			continue
+		}
+		if _, exists := current[k]; exists {
+			return false, nil
+		}
+	}
+	if err := ab.PutElements(ctx, desired); err != nil {
+		return false, err
+	}
+	return true, nil
+}
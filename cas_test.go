@@ -0,0 +1,48 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_PutElementsCAS(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	// keys absent from expected must not exist
+	ok, err := buffer.PutElementsCAS(ctx, nil, map[string]string{"a": "1"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = buffer.PutElementsCAS(ctx, nil, map[string]string{"a": "5"})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	ok, err = buffer.PutElementsCAS(ctx, map[string]string{"a": "1"}, map[string]string{"a": "2", "b": "1"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// stale expectations don't write
+	ok, err = buffer.PutElementsCAS(ctx, map[string]string{"a": "1"}, map[string]string{"a": "3"})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	ok, err = buffer.PutElementsCAS(ctx, map[string]string{"a": "2", "c": "1"}, map[string]string{"a": "3"})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	stored, err := buffer.GetBuffer(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "2", "b": "1"}, stored)
+
+	c.SetError(true, (*client.AlgorandMock).GetApplicationByID)
+	_, err = buffer.PutElementsCAS(ctx, map[string]string{"a": "2"}, map[string]string{"a": "3"})
+	assert.NotNil(t, err)
+}
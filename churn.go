@@ -0,0 +1,66 @@
+package siam
+
+import (
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// suppressChurn removes the pairs from the batches, whose keys have been written less
+// than ManageConfig.MinWriteInterval ago. The keys of removed pairs are recorded (see
+// LastSuppressed). Batches that only consisted of removed pairs are dropped.
+func (ab *AlgorandBuffer) suppressChurn(batches [][]models.TealKeyValue) [][]models.TealKeyValue {
+	interval := ab.config.MinWriteInterval
+	if interval <= 0 {
+		return batches
+	}
+	now := ab.now()
+	suppressed := make([]string, 0)
+
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	for k, written := range ab.lastWrites {
+		if now.Sub(written) >= interval {
+			delete(ab.lastWrites, k)
+		}
+	}
+	filtered := make([][]models.TealKeyValue, 0, len(batches))
+	for _, kvArray := range batches {
+		kept := make([]models.TealKeyValue, 0, len(kvArray))
+		for _, kv := range kvArray {
+			if _, recent := ab.lastWrites[kv.Key]; recent {
+				suppressed = append(suppressed, ab.config.KeyEncoding.fromState([]byte(kv.Key)))
+				continue
+			}
+			kept = append(kept, kv)
+		}
+		if len(kept) > 0 || len(kvArray) == 0 {
+			filtered = append(filtered, kept)
+		}
+	}
+	ab.lastSuppressed = suppressed
+	return filtered
+}
+
+// recordWrites remembers the time at which the keys have been written.
+func (ab *AlgorandBuffer) recordWrites(kvArray []models.TealKeyValue, written time.Time) {
+	if ab.config.MinWriteInterval <= 0 {
+		return
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	for _, kv := range kvArray {
+		ab.lastWrites[kv.Key] = written
+	}
+}
+
+// LastSuppressed returns the keys that were left out of the last store (see
+// PutElements), because they had been written less than ManageConfig.MinWriteInterval
+// before. Returns an empty slice, if no keys were suppressed.
+func (ab *AlgorandBuffer) LastSuppressed() []string {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	keys := make([]string, len(ab.lastSuppressed))
+	copy(keys, ab.lastSuppressed)
+	return keys
+}
@@ -0,0 +1,66 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// Writes to a key within MinWriteInterval of its last write are suppressed.
+func TestAlgorandBuffer_MinWriteInterval(t *testing.T) {
+	c := &storeRecordingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	cfg := ManageConfig{MinWriteInterval: time.Minute}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	now := time.Unix(1000, 0)
+	buffer.now = func() time.Time { return now }
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"price": "1"}))
+	assert.Len(t, buffer.LastSuppressed(), 0)
+
+	// hammering the same key
+	now = now.Add(time.Second * 10)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"price": "2"}))
+	assert.Equal(t, []string{"price"}, buffer.LastSuppressed())
+	assert.Len(t, c.stored, 1)
+
+	// other keys are still written
+	now = now.Add(time.Second * 10)
+	assert.Nil(t, buffer.PutOrdered(context.Background(), []KV{{Key: "price", Value: "3"}, {Key: "volume", Value: "9"}}))
+	assert.Equal(t, []string{"price"}, buffer.LastSuppressed())
+	assert.Len(t, c.stored, 2)
+	assert.Len(t, c.stored[1], 1)
+	assert.Equal(t, "volume", c.stored[1][0].Key)
+
+	d, _ := buffer.GetBuffer(context.Background())
+	assert.Equal(t, "1", d["price"])
+
+	// interval has passed
+	now = now.Add(time.Second * 40)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"price": "4"}))
+	assert.Len(t, buffer.LastSuppressed(), 0)
+	d, _ = buffer.GetBuffer(context.Background())
+	assert.Equal(t, "4", d["price"])
+}
+
+// Without MinWriteInterval, nothing is suppressed.
+func TestAlgorandBuffer_NoMinWriteInterval(t *testing.T) {
+	c := &storeRecordingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"price": "1"}))
+	}
+	assert.Len(t, c.stored, 3)
+	assert.Len(t, buffer.LastSuppressed(), 0)
+}
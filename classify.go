@@ -0,0 +1,81 @@
+package siam
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// ErrorClass categorizes the errors of the management loop (see Manage). The class
+// decides how the loop retries a failed cycle.
+type ErrorClass int
+
+const (
+	// Unclassified makes ManageConfig.ClassifyError fall back to DefaultClassifyError.
+	Unclassified ErrorClass = iota
+
+	// Transient errors (e.g. timeouts) are retried after the usual SleepInterval.
+	Transient
+
+	// Permanent errors won't go away by retrying. The management loop returns right
+	// away, as if ManageConfig.MaxRetries had been exceeded.
+	Permanent
+
+	// RateLimited errors mean that the node throttles requests. The next cycle waits for
+	// ManageConfig.PoolFullBackoff.
+	RateLimited
+
+	// PoolFull errors mean that the transaction pool of the node is full. The next cycle
+	// waits for ManageConfig.PoolFullBackoff.
+	PoolFull
+
+	// FeeTooLow errors mean that the node rejected a transaction because of its fee.
+	// Cached suggested params are dropped, so that the next cycle uses current fees.
+	FeeTooLow
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case Transient:
+		return "transient"
+	case Permanent:
+		return "permanent"
+	case RateLimited:
+		return "rate-limited"
+	case PoolFull:
+		return "pool-full"
+	case FeeTooLow:
+		return "fee-too-low"
+	}
+	return "unclassified"
+}
+
+// DefaultClassifyError is the built-in classification of errors. Errors of the client
+// (client.ErrRateLimited, client.ErrPoolFull) and of the buffer (ErrReadOnly,
+// ErrStopped) are recognized with errors.Is. Fee rejections are recognized by the
+// message of algod. All other errors are Transient.
+func DefaultClassifyError(err error) ErrorClass {
+	switch {
+	case errors.Is(err, client.ErrRateLimited):
+		return RateLimited
+	case errors.Is(err, client.ErrPoolFull):
+		return PoolFull
+	case errors.Is(err, ErrReadOnly), errors.Is(err, ErrStopped):
+		return Permanent
+	case err != nil && strings.Contains(err.Error(), "less than the minimum"):
+		return FeeTooLow
+	}
+	return Transient
+}
+
+// classifyError returns the class of err, according to ManageConfig.ClassifyError
+// and DefaultClassifyError.
+func (ab *AlgorandBuffer) classifyError(err error) ErrorClass {
+	if ab.config.ClassifyError != nil {
+		if class := ab.config.ClassifyError(err); class != Unclassified {
+			return class
+		}
+	}
+	return DefaultClassifyError(err)
+}
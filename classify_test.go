@@ -0,0 +1,106 @@
+//go:build unit
+
+package siam
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultClassifyError(t *testing.T) {
+	assert.Equal(t, RateLimited, DefaultClassifyError(fmt.Errorf("store: %w", client.ErrRateLimited)))
+	assert.Equal(t, PoolFull, DefaultClassifyError(withPhase(PhaseStore, client.ErrPoolFull)))
+	assert.Equal(t, Permanent, DefaultClassifyError(ErrReadOnly))
+	assert.Equal(t, Permanent, DefaultClassifyError(ErrStopped))
+	assert.Equal(t, FeeTooLow, DefaultClassifyError(errors.New("transaction fee 0 is less than the minimum 1000")))
+	assert.Equal(t, Transient, DefaultClassifyError(errors.New("node offline")))
+	assert.Equal(t, "rate-limited", RateLimited.String())
+	assert.Equal(t, "unclassified", Unclassified.String())
+}
+
+// A custom classifier reclassifies a provider-specific error, and falls back to the
+// built-in classifier for all others.
+func TestAlgorandBuffer_ClassifyError(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	cfg := ManageConfig{SleepInterval: time.Second}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	throttled := errors.New("provider: slow down")
+	assert.Equal(t, Transient, buffer.classifyError(throttled))
+	assert.Equal(t, time.Second, buffer.cycleDelay(throttled))
+
+	buffer.config.ClassifyError = func(err error) ErrorClass {
+		if strings.Contains(err.Error(), "slow down") {
+			return RateLimited
+		}
+		return Unclassified
+	}
+	assert.Equal(t, RateLimited, buffer.classifyError(throttled))
+	assert.Equal(t, poolFullFactor*time.Second, buffer.cycleDelay(throttled))
+	assert.Equal(t, PoolFull, buffer.classifyError(client.ErrPoolFull))
+	assert.Equal(t, time.Second, buffer.cycleDelay(errors.New("node offline")))
+}
+
+// Manage doesn't retry errors that are classified as Permanent, even without
+// MaxRetries.
+func TestAlgorandBuffer_ClassifyPermanent(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	cfg := ManageConfig{
+		SleepInterval: time.Millisecond,
+		ClassifyError: func(err error) ErrorClass {
+			if strings.Contains(err.Error(), "stub") {
+				return Permanent
+			}
+			return Unclassified
+		},
+	}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+
+	done := make(chan struct{})
+	go func() {
+		buffer.Manage()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Manage retried a permanent error")
+	}
+	iterations, _, _ := buffer.LoopStats()
+	assert.EqualValues(t, 1, iterations)
+}
+
+// Errors classified as FeeTooLow drop the cached suggested params before the retry.
+func TestAlgorandBuffer_ClassifyFeeTooLow(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	cfg := ManageConfig{
+		SleepInterval:  time.Millisecond,
+		MaxRetries:     1,
+		ParamsCacheTTL: time.Hour,
+		ClassifyError: func(err error) ErrorClass {
+			if strings.Contains(err.Error(), "stub") {
+				return FeeTooLow
+			}
+			return Unclassified
+		},
+	}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	buffer.params = paramsCache{params: types.SuggestedParams{Fee: 1}, fetched: time.Now()}
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+
+	buffer.Manage()
+	assert.Equal(t, paramsCache{}, buffer.params)
+	iterations, _, _ := buffer.LoopStats()
+	assert.EqualValues(t, 2, iterations)
+}
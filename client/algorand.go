@@ -3,7 +3,6 @@ package client
 import (
 	"context"
 	"encoding/base64"
-	"fmt"
 	"github.com/algorand/go-algorand-sdk/future"
 	"time"
 
@@ -17,6 +16,18 @@ import (
 const MaxArgs = 16
 const MaxKVArgs = 8
 
+// Minimum balance and fee parameters of the Algorand protocol in microAlgos
+const (
+	MinBalance            = 100000
+	AppMinBalance         = 100000
+	SchemaMinBalance      = 25000
+	SchemaUintMinBalance  = 3500
+	SchemaBytesMinBalance = 25000
+	BoxFlatMinBalance     = 2500
+	BoxByteMinBalance     = 400
+	MinTxnFee             = 1000
+)
+
 const AlgorandDefaultTimeout time.Duration = time.Second * 30
 const AlgorandDefaultMinSleep time.Duration = time.Second * 5
 
@@ -34,11 +45,32 @@ type AlgorandClient interface {
 	PendingTransactionInformation(string, context.Context) (models.PendingTransactionInfoResponse, types.SignedTxn, error)
 	TealCompile([]byte, context.Context) (models.CompileResponse, error)
 
+	// PendingTransactionsByAddress returns up to max transactions of the given address
+	// that are in the transaction pool of the node. If max is zero, all of them are
+	// returned.
+	PendingTransactionsByAddress(string, uint64, context.Context) ([]types.SignedTxn, error)
+
+	// SimulateTransaction runs the transaction through the dryrun endpoint of the node,
+	// without submitting it, so no fees are paid. The transaction isn't signed. The
+	// response reports whether the programs approve it (see DryrunError). Like
+	// TealCompile, this requires a node with the developer API enabled.
+	SimulateTransaction(crypto.Account, types.Transaction, context.Context) (models.DryrunResponse, error)
+
+	// DisassembleProgram disassembles the given program bytecode back to TEAL source.
+	// Like TealCompile, this requires a node with the developer API enabled.
+	DisassembleProgram([]byte, context.Context) (string, error)
+
 	// ExecuteTransaction executes a given transaction, waits for the response,
 	// and returns potential errors. Also returns an info response of the successful
 	// or unsuccessful transaction.
 	ExecuteTransaction(crypto.Account, types.Transaction, context.Context) (models.PendingTransactionInfoResponse, error)
 
+	// ExecuteGroup executes the given transactions as an atomic group of at most
+	// MaxGroupSize transactions. It assigns the group ID, submits the signed
+	// transactions together, and waits for their confirmation. Either all of them are
+	// confirmed, or none. Returns the info responses in order of the transactions.
+	ExecuteGroup(crypto.Account, []types.Transaction, context.Context) ([]models.PendingTransactionInfoResponse, error)
+
 	// DeleteApplication deletes an application with given ID from a given account.
 	// If the account has no apps, or none of its apps have the correct ID, then an
 	// error is returned.
@@ -56,6 +88,41 @@ type AlgorandClient interface {
 	DeleteGlobals(crypto.Account, uint64, ...string) error
 }
 
+// ResultClient is implemented by clients that return the info response of the
+// confirmed transactions of StoreGlobals and DeleteGlobals (e.g. containing logs of
+// the app). All clients of this package implement it. A type that embeds a
+// ResultClient and overrides StoreGlobals or DeleteGlobals has to override the
+// corresponding method of ResultClient as well.
+type ResultClient interface {
+	// StoreGlobalsResult is like StoreGlobals, but returns the info response of the
+	// confirmed transaction.
+	StoreGlobalsResult(crypto.Account, uint64, []models.TealKeyValue) (models.PendingTransactionInfoResponse, error)
+
+	// DeleteGlobalsResult is like DeleteGlobals, but returns the info response of
+	// the confirmed transaction.
+	DeleteGlobalsResult(crypto.Account, uint64, ...string) (models.PendingTransactionInfoResponse, error)
+}
+
+// StoreGlobalsResult stores the given key-value pairs with a.StoreGlobals. If a is a
+// ResultClient, the info response of the confirmed transaction is returned, and an
+// empty one otherwise.
+func StoreGlobalsResult(a AlgorandClient, acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	if r, ok := a.(ResultClient); ok {
+		return r.StoreGlobalsResult(acc, appId, tkv)
+	}
+	return models.PendingTransactionInfoResponse{}, a.StoreGlobals(acc, appId, tkv)
+}
+
+// DeleteGlobalsResult deletes the given keys with a.DeleteGlobals. If a is a
+// ResultClient, the info response of the confirmed transaction is returned, and an
+// empty one otherwise.
+func DeleteGlobalsResult(a AlgorandClient, acc crypto.Account, appId uint64, keys ...string) (models.PendingTransactionInfoResponse, error) {
+	if r, ok := a.(ResultClient); ok {
+		return r.DeleteGlobalsResult(acc, appId, keys...)
+	}
+	return models.PendingTransactionInfoResponse{}, a.DeleteGlobals(acc, appId, keys...)
+}
+
 // GeneratePrivateKey64 returns a random, base64-encoded private key.
 func GeneratePrivateKey64() string {
 	acc := crypto.GenerateAccount()
@@ -64,16 +131,15 @@ func GeneratePrivateKey64() string {
 
 // ValidAccount returns true if the given account is a valid AlgorandBuffer target
 // and ready to store data in a single application
+// with the DefaultSchema. See SchemaSpec.ValidAccount for custom schemas.
 func ValidAccount(account models.Account) bool {
-	return len(account.CreatedApps) == 1 && FulfillsSchema(account.CreatedApps[0])
+	return DefaultSchema.ValidAccount(account)
 }
 
 // GenerateSchemas generates application state schemas for the Algorand oracle application.
 // It returns an object of type types.StateSchema.
 func GenerateSchemas() (types.StateSchema, types.StateSchema) {
-	globalSchema := types.StateSchema{NumUint: uint64(GlobalInts), NumByteSlice: uint64(GlobalBytes)}
-	localSchema := types.StateSchema{NumUint: uint64(LocalInts), NumByteSlice: uint64(LocalBytes)}
-	return localSchema, globalSchema
+	return DefaultSchema.Schemas()
 }
 
 // GenerateSchemasModel generates application state schemas for the Algorand oracle
@@ -87,17 +153,9 @@ func GenerateSchemasModel() (models.ApplicationStateSchema, models.ApplicationSt
 
 // FulfillsSchema returns true if the given application has correct global state schemas.
 // You can get the correct schemas from the functions GenerateSchemas and GenerateSchemasModel.
+// See SchemaSpec.Fulfills for custom schemas.
 func FulfillsSchema(app models.Application) bool {
-	if app.Id == 0 {
-		return false
-	}
-	if app.Params.GlobalStateSchema.NumByteSlice != 64 {
-		return false
-	}
-	if app.Params.GlobalStateSchema.NumUint != 0 {
-		return false
-	}
-	return true
+	return DefaultSchema.Fulfills(app)
 }
 
 // GenerateApplicationCallTx generates a mostly empty application call transaction, with the
@@ -123,12 +181,14 @@ func GenerateApplicationCallTx(id uint64, a crypto.Account, p types.SuggestedPar
 	)
 }
 
-func CompileProgram(client AlgorandClient, program []byte) (compiledProgram []byte) {
-	compileResponse, err := client.TealCompile(program, context.Background())
+// CompileProgram compiles the given TEAL program with the node. If compilation fails,
+// it logs the error to the Logger of the client (see AlgorandClientWrapper.Logger) and
+// returns nil. Use CompileProgramE to handle the error instead.
+func CompileProgram(client AlgorandClient, program []byte) []byte {
+	compiledProgram, err := CompileProgramE(client, program)
 	if err != nil {
-		fmt.Printf("Issue with compile: %s\n", err)
-		return
+		loggerOf(client).Errorf("issue with compile: %s", err)
+		return nil
 	}
-	compiledProgram, _ = base64.StdEncoding.DecodeString(compileResponse.Result)
 	return compiledProgram
 }
@@ -0,0 +1,75 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// apiKeyNode accepts requests that have the API key in the X-API-Key header, like
+// hosted node providers.
+func apiKeyNode(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"missing api key"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/status":
+			_, _ = w.Write([]byte(`{"last-round":42}`))
+		case "/v2/teal/disassemble":
+			_, _ = w.Write([]byte(`{"result":"#pragma version 4\n"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewClientWithAuthHeader(t *testing.T) {
+	server := apiKeyNode(t)
+	c, err := NewClientWithAuthHeader(server.URL, "X-API-Key", "secret")
+	assert.Nil(t, err)
+
+	// a null response means the node is healthy
+	_, unmarshalErr := c.HealthCheck(context.Background()).(*json.InvalidUnmarshalError)
+	assert.True(t, unmarshalErr)
+	status, err := c.Status(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 42, status.LastRound)
+	_, err = c.DisassembleProgram([]byte{0x04}, context.Background())
+	assert.Nil(t, err)
+
+	// the default header is rejected by the provider
+	c, err = NewClientWithAuthHeader(server.URL, "", "secret")
+	assert.Nil(t, err)
+	_, err = c.Status(context.Background())
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestNewIndexerClientWithAuthHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"application":{"id":5}}`))
+	}))
+	t.Cleanup(server.Close)
+	i, err := NewIndexerClientWithAuthHeader(server.URL, "X-API-Key", "secret")
+	assert.Nil(t, err)
+
+	app, err := i.LookupApplicationByID(5, context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 5, app.Id)
+}
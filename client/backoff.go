@@ -0,0 +1,34 @@
+package client
+
+import "time"
+
+// BackoffPolicy determines how long the client waits between two polls for the
+// confirmation of a transaction. The first poll waits BaseDelay, and each following
+// poll waits Multiplier times longer, up to MaxDelay.
+type BackoffPolicy struct {
+	// BaseDelay is the time to wait after the first poll.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the time to wait between two polls. If zero, the delay isn't
+	// capped.
+	MaxDelay time.Duration
+
+	// Multiplier is the factor by which the delay grows after each poll. Values
+	// below 1 keep the delay constant.
+	Multiplier float64
+}
+
+// delay returns the time to wait after the given poll, starting with 0.
+func (p BackoffPolicy) delay(poll int) time.Duration {
+	d := p.BaseDelay
+	for i := 0; i < poll; i++ {
+		if p.Multiplier <= 1 || (p.MaxDelay > 0 && d >= p.MaxDelay) {
+			break
+		}
+		d = time.Duration(float64(d) * p.Multiplier)
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
@@ -0,0 +1,22 @@
+package client
+
+import "github.com/algorand/go-algorand-sdk/client/v2/common/models"
+
+// MinAccountBalance returns the minimum balance in microAlgos of an account that
+// created the given apps. Every app raises the minimum balance based on its global
+// state schema.
+func MinAccountBalance(apps []models.Application) uint64 {
+	mbr := uint64(MinBalance)
+	for _, app := range apps {
+		g := app.Params.GlobalStateSchema
+		mbr += AppMinBalance + (SchemaMinBalance+SchemaUintMinBalance)*g.NumUint +
+			(SchemaMinBalance+SchemaBytesMinBalance)*g.NumByteSlice
+	}
+	return mbr
+}
+
+// BoxMinBalance returns the minimum balance in microAlgos that a box with the given
+// name and size adds to the account of its application.
+func BoxMinBalance(name string, size uint64) uint64 {
+	return BoxFlatMinBalance + BoxByteMinBalance*(uint64(len(name))+size)
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CompileError is returned by CompileProgramE, if the node rejects a program.
+type CompileError struct {
+	// Line is the line of the TEAL source the node reported the error for, or zero if
+	// the node didn't report a line.
+	Line int
+
+	// Message is the error message of the node.
+	Message string
+
+	Err error
+}
+
+func (e *CompileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("compile error at line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("compile error: %s", e.Message)
+}
+
+func (e *CompileError) Unwrap() error {
+	return e.Err
+}
+
+// compileLine matches the problems algod reports for TEAL sources, e.g.
+// "3: unknown opcode: foo".
+var compileLine = regexp.MustCompile(`^(\d+): (.+)$`)
+
+// CompileProgramE compiles the given TEAL program with the node, like CompileProgram,
+// but returns the error instead of logging it. Errors of the node are returned as
+// CompileError, with the line of the first problem of the source, if the node reports
+// it.
+func CompileProgramE(client AlgorandClient, program []byte) ([]byte, error) {
+	compileResponse, err := client.TealCompile(program, context.Background())
+	if err != nil {
+		return nil, compileError(err)
+	}
+	compiled, err := base64.StdEncoding.DecodeString(compileResponse.Result)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compile result: %w", err)
+	}
+	return compiled, nil
+}
+
+// compileError converts an error of TealCompile to a CompileError. The algod client
+// returns errors like `HTTP 400: {"message":"3: unknown opcode: foo"}`.
+func compileError(err error) error {
+	msg := err.Error()
+	if i := strings.Index(msg, "{"); i >= 0 {
+		var body struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal([]byte(msg[i:]), &body) == nil && body.Message != "" {
+			msg = body.Message
+		}
+	}
+	msg = strings.TrimSpace(msg)
+	e := &CompileError{Message: msg, Err: err}
+	first := strings.SplitN(msg, "\n", 2)[0]
+	if m := compileLine.FindStringSubmatch(first); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Message = m[2]
+	}
+	return e
+}
@@ -0,0 +1,84 @@
+//go:build unit
+
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileProgramE_ReportsLine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"3: unknown opcode: foo\n"}`))
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateAlgorandClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+
+	compiled, err := CompileProgramE(c, []byte("#pragma version 5\nint 1\nfoo"))
+	assert.Nil(t, compiled)
+	var compileErr *CompileError
+	assert.True(t, errors.As(err, &compileErr))
+	assert.Equal(t, 3, compileErr.Line)
+	assert.Equal(t, "unknown opcode: foo", compileErr.Message)
+	assert.Contains(t, err.Error(), "line 3")
+}
+
+func TestCompileProgramE_NoLine(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.SetError(true, (*AlgorandMock).TealCompile)
+	_, err := CompileProgramE(m, []byte("#pragma version 5"))
+	var compileErr *CompileError
+	assert.True(t, errors.As(err, &compileErr))
+	assert.Equal(t, 0, compileErr.Line)
+}
+
+func TestCompileProgramE_Decodes(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.CompileResponse.Result = base64.StdEncoding.EncodeToString([]byte{0x05, 0x81, 0x01})
+	compiled, err := CompileProgramE(m, []byte("#pragma version 5\nint 1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x05, 0x81, 0x01}, compiled)
+
+	m.CompileResponse.Result = "not base64!"
+	_, err = CompileProgramE(m, []byte("#pragma version 5\nint 1"))
+	assert.NotNil(t, err)
+}
+
+func TestCreateApplication_CompileError(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.SetError(true, (*AlgorandMock).TealCompile)
+	id, err := CreateApplicationWithSchema(m, crypto.GenerateAccount(), "#pragma version 5", "#pragma version 5", DefaultSchema)
+	var compileErr *CompileError
+	assert.True(t, errors.As(err, &compileErr))
+	assert.Contains(t, err.Error(), "approval program")
+	assert.Equal(t, uint64(0), id)
+}
+
+func TestCreateApplicationCompiled(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	acc := crypto.GenerateAccount()
+	approval, clear := []byte{0x05, 0x81, 0x01}, []byte{0x05, 0x81, 0x01}
+	global := types.StateSchema{NumUint: 1, NumByteSlice: 30}
+	id, err := CreateApplicationCompiled(m, acc, approval, clear, global, types.StateSchema{})
+	assert.Nil(t, err)
+	assert.NotEqual(t, uint64(0), id)
+	assert.Equal(t, models.ApplicationStateSchema{NumUint: 1, NumByteSlice: 30}, m.App.Params.GlobalStateSchema)
+
+	// the programs are submitted as given, without compiling them
+	assert.Equal(t, 0, m.CallCount((*AlgorandMock).TealCompile))
+	call, ok := m.LastCall((*AlgorandMock).ExecuteTransaction)
+	assert.True(t, ok)
+	txn := call.Args[1].(types.Transaction)
+	assert.Equal(t, approval, txn.ApprovalProgram)
+	assert.Equal(t, clear, txn.ClearStateProgram)
+}
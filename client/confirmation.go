@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// defaultWaitRounds is the number of rounds ExecuteTransaction waits for a
+// transaction to be confirmed.
+const defaultWaitRounds = 5
+
+// ErrConfirmationTimeout is returned if a transaction hasn't been confirmed within
+// the rounds the client waits for it.
+var ErrConfirmationTimeout = errors.New("timed out waiting for confirmation")
+
+// ConfirmationTimeoutError is returned if a transaction hasn't been confirmed within
+// the rounds the client waits for it. It matches ErrConfirmationTimeout.
+type ConfirmationTimeoutError struct {
+	TxID string
+
+	// LastRound is the last round the client observed while waiting.
+	LastRound uint64
+}
+
+func (e *ConfirmationTimeoutError) Error() string {
+	return fmt.Sprintf("%s {%s} at round %d", ErrConfirmationTimeout, e.TxID, e.LastRound)
+}
+
+func (e *ConfirmationTimeoutError) Unwrap() error {
+	return ErrConfirmationTimeout
+}
+
+// PoolRejectionError is returned if the node rejected a transaction from its
+// transaction pool. Message is the pool error reported by the node.
+type PoolRejectionError struct {
+	TxID    string
+	Message string
+}
+
+func (e *PoolRejectionError) Error() string {
+	return fmt.Sprintf("transaction rejected: %s", e.Message)
+}
+
+// ConfirmationTimeoutHook is called if a transaction hasn't been confirmed in time.
+// seenInPool is true if the node reported the transaction as pending at least once.
+// resubmissions is the number of times the transaction has already been resubmitted.
+// Return true to resubmit the transaction. A transaction that has never been seen was
+// most likely dropped from the pool, so resubmitting it is the fix.
+type ConfirmationTimeoutHook func(txID string, seenInPool bool, resubmissions int) bool
+
+// ResubmitUnseenOnce resubmits a transaction once, if it has never been seen in the
+// pool. This is the default ConfirmationTimeoutHook of an AlgorandClientWrapper.
+func ResubmitUnseenOnce(txID string, seenInPool bool, resubmissions int) bool {
+	return !seenInPool && resubmissions == 0
+}
+
+// submitAndConfirm submits the signed transaction and waits for its confirmation. If
+// it isn't confirmed in time, the hook decides if the same transaction is resubmitted.
+func submitAndConfirm(c AlgorandClient, signedTxn []byte, hook ConfirmationTimeoutHook, backoff *BackoffPolicy, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	for resubmissions := 0; ; resubmissions++ {
+		txID, err := c.SendRawTransaction(signedTxn, ctx)
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+		info, seen, err := waitForConfirmation(c, txID, defaultWaitRounds, backoff, ctx)
+		if !errors.Is(err, ErrConfirmationTimeout) || hook == nil || !hook(txID, seen, resubmissions) {
+			return info, err
+		}
+	}
+}
+
+// waitForConfirmation waits up to waitRounds rounds for the transaction to be confirmed.
+// It also reports if the transaction has been seen in the transaction pool. Without a
+// backoff policy, the node is polled once per round. With one, it's polled after the
+// delays of the policy, which also bounds the polling of nodes with short block times.
+func waitForConfirmation(c AlgorandClient, txID string, waitRounds uint64, backoff *BackoffPolicy, ctx context.Context) (info models.PendingTransactionInfoResponse, seen bool, err error) {
+	status, err := c.Status(ctx)
+	if err != nil {
+		return info, false, err
+	}
+	lastRound := status.LastRound
+	round := lastRound
+	for poll := 0; round < lastRound+waitRounds; poll++ {
+		info, _, err = c.PendingTransactionInformation(txID, ctx)
+		// Errors are ignored, since nodes behind a load balancer might not know the
+		// transaction yet
+		if err == nil {
+			seen = true
+			if info.PoolError != "" {
+				return info, seen, &PoolRejectionError{TxID: txID, Message: info.PoolError}
+			}
+			if info.ConfirmedRound > 0 {
+				return info, seen, nil
+			}
+		}
+		if backoff == nil {
+			status, err = c.StatusAfterBlock(round+1, ctx)
+		} else if err = sleepContext(ctx, backoff.delay(poll)); err == nil {
+			status, err = c.Status(ctx)
+		}
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, seen, err
+		}
+		if status.LastRound > round {
+			round = status.LastRound
+		} else if backoff == nil {
+			// nodes that don't advance are given one poll per round
+			round++
+		}
+	}
+	return models.PendingTransactionInfoResponse{}, seen, &ConfirmationTimeoutError{TxID: txID, LastRound: round}
+}
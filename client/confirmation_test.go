@@ -0,0 +1,219 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// droppingMock drops the first drops submissions of a transaction. Dropped
+// transactions are never reported as pending.
+type droppingMock struct {
+	*AlgorandMock
+	drops     int
+	submitted int
+	round     uint64
+}
+
+func (m *droppingMock) Status(context.Context) (models.NodeStatus, error) {
+	return models.NodeStatus{LastRound: m.round}, nil
+}
+
+func (m *droppingMock) StatusAfterBlock(round uint64, _ context.Context) (models.NodeStatus, error) {
+	m.round = round
+	return models.NodeStatus{LastRound: m.round}, nil
+}
+
+func (m *droppingMock) SendRawTransaction([]byte, context.Context) (string, error) {
+	m.submitted++
+	return "txid", nil
+}
+
+func (m *droppingMock) PendingTransactionInformation(string, context.Context) (models.PendingTransactionInfoResponse, types.SignedTxn, error) {
+	if m.submitted <= m.drops {
+		return models.PendingTransactionInfoResponse{}, types.SignedTxn{}, errors.New("HTTP 404: txn not found")
+	}
+	return models.PendingTransactionInfoResponse{ConfirmedRound: m.round}, types.SignedTxn{}, nil
+}
+
+// A dropped transaction is resubmitted once by default.
+func TestSubmitAndConfirm_ResubmitDropped(t *testing.T) {
+	m := &droppingMock{AlgorandMock: CreateAlgorandClientMock("", ""), drops: 1}
+	info, err := submitAndConfirm(m, []byte("signed"), ResubmitUnseenOnce, nil, context.Background())
+	assert.Nil(t, err)
+	assert.True(t, info.ConfirmedRound > 0)
+	assert.Equal(t, 2, m.submitted)
+}
+
+func TestSubmitAndConfirm_ResubmitOnlyOnce(t *testing.T) {
+	m := &droppingMock{AlgorandMock: CreateAlgorandClientMock("", ""), drops: 2}
+	_, err := submitAndConfirm(m, []byte("signed"), ResubmitUnseenOnce, nil, context.Background())
+	assert.ErrorIs(t, err, ErrConfirmationTimeout)
+	assert.Equal(t, 2, m.submitted)
+}
+
+// Transactions that have been seen in the pool are not resubmitted by default.
+func TestSubmitAndConfirm_SeenNotResubmitted(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	calls := make([]bool, 0)
+	hook := func(txID string, seenInPool bool, resubmissions int) bool {
+		calls = append(calls, seenInPool)
+		return ResubmitUnseenOnce(txID, seenInPool, resubmissions)
+	}
+	_, err := submitAndConfirm(m, []byte("signed"), hook, nil, context.Background())
+	assert.ErrorIs(t, err, ErrConfirmationTimeout)
+	assert.Equal(t, []bool{true}, calls)
+}
+
+// A custom hook decides how often transactions are resubmitted.
+func TestSubmitAndConfirm_CustomHook(t *testing.T) {
+	m := &droppingMock{AlgorandMock: CreateAlgorandClientMock("", ""), drops: 3}
+	hook := func(_ string, _ bool, resubmissions int) bool {
+		return resubmissions < 3
+	}
+	_, err := submitAndConfirm(m, []byte("signed"), hook, nil, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 4, m.submitted)
+}
+
+// rejectingMock reports every transaction with a pool error.
+type rejectingMock struct {
+	*droppingMock
+}
+
+func (m *rejectingMock) PendingTransactionInformation(string, context.Context) (models.PendingTransactionInfoResponse, types.SignedTxn, error) {
+	return models.PendingTransactionInfoResponse{PoolError: "overspend"}, types.SignedTxn{}, nil
+}
+
+func TestWaitForConfirmation_PoolRejection(t *testing.T) {
+	m := &rejectingMock{&droppingMock{AlgorandMock: CreateAlgorandClientMock("", "")}}
+	_, seen, err := waitForConfirmation(m, "txid", defaultWaitRounds, nil, context.Background())
+	assert.True(t, seen)
+	var rejection *PoolRejectionError
+	assert.ErrorAs(t, err, &rejection)
+	assert.Equal(t, "overspend", rejection.Message)
+	assert.Equal(t, "txid", rejection.TxID)
+}
+
+func TestWaitForConfirmation_TimeoutCarriesRound(t *testing.T) {
+	m := &droppingMock{AlgorandMock: CreateAlgorandClientMock("", ""), drops: 1, round: 10}
+	m.submitted = 1
+	_, seen, err := waitForConfirmation(m, "txid", defaultWaitRounds, nil, context.Background())
+	assert.False(t, seen)
+	assert.ErrorIs(t, err, ErrConfirmationTimeout)
+	var timeout *ConfirmationTimeoutError
+	assert.ErrorAs(t, err, &timeout)
+	assert.Equal(t, uint64(10+defaultWaitRounds), timeout.LastRound)
+}
+
+// advancingMock advances one round per status request, without blocking.
+type advancingMock struct {
+	*droppingMock
+	statusCalls int
+}
+
+func (m *advancingMock) Status(context.Context) (models.NodeStatus, error) {
+	m.statusCalls++
+	m.round++
+	return models.NodeStatus{LastRound: m.round}, nil
+}
+
+func (m *advancingMock) StatusAfterBlock(uint64, context.Context) (models.NodeStatus, error) {
+	panic("StatusAfterBlock must not be used with a backoff policy")
+}
+
+func TestWaitForConfirmation_Backoff(t *testing.T) {
+	m := &advancingMock{droppingMock: &droppingMock{AlgorandMock: CreateAlgorandClientMock("", ""), drops: 1}}
+	m.submitted = 1
+	backoff := &BackoffPolicy{BaseDelay: time.Microsecond, MaxDelay: time.Millisecond, Multiplier: 2}
+	_, _, err := waitForConfirmation(m, "txid", defaultWaitRounds, backoff, context.Background())
+	assert.ErrorIs(t, err, ErrConfirmationTimeout)
+	// the initial status, and one per round
+	assert.Equal(t, 1+defaultWaitRounds, m.statusCalls)
+
+	m.submitted = 2
+	info, _, err := waitForConfirmation(m, "txid", defaultWaitRounds, backoff, context.Background())
+	assert.Nil(t, err)
+	assert.True(t, info.ConfirmedRound > 0)
+}
+
+func TestWaitForConfirmation_BackoffCancelled(t *testing.T) {
+	m := &advancingMock{droppingMock: &droppingMock{AlgorandMock: CreateAlgorandClientMock("", ""), drops: 1}}
+	m.submitted = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := waitForConfirmation(m, "txid", defaultWaitRounds, &BackoffPolicy{BaseDelay: time.Hour}, ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// The transaction is confirmed after the node advanced three rounds.
+func TestWaitForConfirmation_ScriptedRounds(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.QueueResponse((*AlgorandMock).Status, models.NodeStatus{LastRound: 10})
+	m.QueueResponses((*AlgorandMock).StatusAfterBlock,
+		models.NodeStatus{LastRound: 11}, models.NodeStatus{LastRound: 12}, models.NodeStatus{LastRound: 13})
+	m.QueueResponses((*AlgorandMock).PendingTransactionInformation,
+		errors.New("HTTP 404: txn not found"),
+		models.PendingTransactionInfoResponse{},
+		models.PendingTransactionInfoResponse{},
+		models.PendingTransactionInfoResponse{ConfirmedRound: 13})
+
+	info, seen, err := waitForConfirmation(m, "txid", defaultWaitRounds, nil, context.Background())
+	assert.Nil(t, err)
+	assert.True(t, seen)
+	assert.EqualValues(t, 13, info.ConfirmedRound)
+}
+
+// A node that never confirms the transaction times out at the last round it reported.
+func TestWaitForConfirmation_ScriptedTimeout(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.QueueResponse((*AlgorandMock).Status, models.NodeStatus{LastRound: 10})
+	m.QueueResponses((*AlgorandMock).StatusAfterBlock, models.NodeStatus{LastRound: 12}, models.NodeStatus{LastRound: 15})
+	m.NodeStatus = models.NodeStatus{LastRound: 15}
+
+	_, _, err := waitForConfirmation(m, "txid", defaultWaitRounds, nil, context.Background())
+	var timeout *ConfirmationTimeoutError
+	assert.ErrorAs(t, err, &timeout)
+	assert.EqualValues(t, 15, timeout.LastRound)
+}
+
+// Against a node that produces blocks, unconfirmed transactions time out after the
+// rounds they are waited for.
+func TestWaitForConfirmation_SimulatedRounds(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.NodeStatus = models.NodeStatus{LastRound: 10}
+	m.SimulateRounds = true
+	m.BlockTime = time.Millisecond
+
+	_, seen, err := waitForConfirmation(m, "txid", defaultWaitRounds, nil, context.Background())
+	var timeout *ConfirmationTimeoutError
+	assert.ErrorAs(t, err, &timeout)
+	assert.True(t, seen)
+	assert.True(t, timeout.LastRound >= 10+defaultWaitRounds)
+
+	// the context bounds slow blocks
+	m.BlockTime = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = waitForConfirmation(m, "txid", defaultWaitRounds, nil, ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBackoffPolicy_Delay(t *testing.T) {
+	p := BackoffPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 3}
+	assert.Equal(t, 100*time.Millisecond, p.delay(0))
+	assert.Equal(t, 300*time.Millisecond, p.delay(1))
+	assert.Equal(t, 900*time.Millisecond, p.delay(2))
+	assert.Equal(t, time.Second, p.delay(3))
+	assert.Equal(t, time.Second, p.delay(50))
+
+	constant := BackoffPolicy{BaseDelay: time.Second}
+	assert.Equal(t, time.Second, constant.delay(5))
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+)
+
+// ConsensusFuture is the consensus version of development networks that run features
+// before they are released.
+const ConsensusFuture = "future"
+
+// boxVersions are the consensus versions that support box storage. Box storage was
+// introduced with ConsensusV36. Versions are identified by the URL of the spec they
+// implement, so they can't be compared numerically. Versions released after this
+// package are unknown, and must be added here.
+var boxVersions = map[string]bool{
+	"https://github.com/algorandfoundation/specs/tree/44fa607d6051730f5264526bf3c108d51f0eadb6": true, // V36
+	"https://github.com/algorandfoundation/specs/tree/1ac4dd1f85470e1fb36c8a65520e1313d7dab9d5": true, // V37
+	"https://github.com/algorandfoundation/specs/tree/abd3d4823c6f77349fc04c3af7b1e99fe4df699f": true, // V38
+	"https://github.com/algorandfoundation/specs/tree/925a46433742afb0b51bb939354bd907fa88bf95": true, // V39
+	"https://github.com/algorandfoundation/specs/tree/236dcc18c9c507d794813ab768e467ea42d1b4d9": true, // V40
+	"https://github.com/algorandfoundation/specs/tree/953304de35264fc3ef91bcd05c123242015eeaed": true, // V41
+	ConsensusFuture: true,
+}
+
+// ConsensusVersion returns the consensus version of the last round the node has seen.
+func ConsensusVersion(c AlgorandClient, ctx context.Context) (string, error) {
+	status, err := c.Status(ctx)
+	if err != nil {
+		return "", err
+	}
+	return status.LastVersion, nil
+}
+
+// SupportsBoxes returns true if the given consensus version supports box storage.
+// Versions before ConsensusV36 and unknown versions are reported as unsupported.
+func SupportsBoxes(version string) bool {
+	return boxVersions[version]
+}
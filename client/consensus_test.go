@@ -0,0 +1,39 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsensusVersion(t *testing.T) {
+	versions := map[string]bool{
+		"v7": false,
+		"https://github.com/algorandfoundation/specs/tree/bc36005dbd776e6d1eaf0c560619bb183215645c": false, // V30
+		"https://github.com/algorandfoundation/specs/tree/85e6db1fdbdef00aa232c75199e10dc5fe9498f6": false, // V31
+		"https://github.com/algorandfoundation/specs/tree/433d8e9a7274b6fca703d91213e05c7e6a589e69": false, // V35
+		"https://github.com/algorandfoundation/specs/tree/44fa607d6051730f5264526bf3c108d51f0eadb6": true,  // V36
+		"https://github.com/algorandfoundation/specs/tree/abd3d4823c6f77349fc04c3af7b1e99fe4df699f": true,  // V38
+		"unknown":       false,
+		ConsensusFuture: true,
+		"":              false,
+	}
+	c := CreateAlgorandClientMock("", "")
+	for version, boxes := range versions {
+		c.NodeStatus.LastVersion = version
+		v, err := ConsensusVersion(c, context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, version, v)
+		assert.Equal(t, boxes, SupportsBoxes(v), version)
+	}
+}
+
+func TestConsensusVersion_Error(t *testing.T) {
+	c := CreateAlgorandClientMock("", "")
+	c.SetError(true, (*AlgorandMock).Status)
+	_, err := ConsensusVersion(c, context.Background())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// DelegatedSigner implements AccountSigner with a delegated logic signature. The owner
+// of an account signs a program once, and whoever holds the logic signature can sign
+// transactions of the account that the program approves. Use it to give automated
+// sub-processes limited write authority, without handing out the private key.
+type DelegatedSigner struct {
+	Account crypto.LogicSigAccount
+	address types.Address
+}
+
+// NewDelegatedSigner creates an AccountSigner from a delegated logic signature (see
+// crypto.MakeLogicSigAccountDelegated).
+func NewDelegatedSigner(lsa crypto.LogicSigAccount) (*DelegatedSigner, error) {
+	if !lsa.IsDelegated() {
+		return nil, errors.New("logic signature is not delegated")
+	}
+	addr, err := lsa.Address()
+	if err != nil {
+		return nil, err
+	}
+	return &DelegatedSigner{Account: lsa, address: addr}, nil
+}
+
+func (s *DelegatedSigner) Address() types.Address {
+	return s.address
+}
+
+func (s *DelegatedSigner) SignTransaction(txn types.Transaction) ([]byte, error) {
+	_, signedTxn, err := crypto.SignLogicSigAccountTransaction(s.Account, txn)
+	return signedTxn, err
+}
+
+// DelegatedWriteTeal returns the TEAL source of a program that approves NoOp calls of
+// the app with the given ID (i.e. writes to the buffer), until the given round. The
+// program rejects rekeys and all other transaction types, so a delegated signer can't
+// take over the account or transfer funds. Note that transaction fees aren't limited.
+func DelegatedWriteTeal(appId uint64, lastRound uint64) string {
+	return fmt.Sprintf(`#pragma version 4
+txn TypeEnum
+int appl
+==
+txn ApplicationID
+int %d
+==
+&&
+txn OnCompletion
+int NoOp
+==
+&&
+txn LastValid
+int %d
+<=
+&&
+txn RekeyTo
+global ZeroAddress
+==
+&&
+`, appId, lastRound)
+}
+
+// DelegateWrites signs the program of DelegatedWriteTeal with the key of acc, and
+// returns a DelegatedSigner that can sign writes to the app until lastRound. The
+// program is compiled by the node of c.
+func DelegateWrites(c AlgorandClient, acc crypto.Account, appId uint64, lastRound uint64) (*DelegatedSigner, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
+	response, err := c.TealCompile([]byte(DelegatedWriteTeal(appId, lastRound)), ctx)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	program, err := base64.StdEncoding.DecodeString(response.Result)
+	if err != nil {
+		return nil, err
+	}
+	lsa, err := crypto.MakeLogicSigAccountDelegated(program, nil, acc.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewDelegatedSigner(lsa)
+}
@@ -0,0 +1,56 @@
+//go:build unit
+
+package client
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// A delegated logic signature signs a store transaction on behalf of the account
+func TestDelegatedSigner(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	c := CreateAlgorandClientMock("", "")
+	// "#pragma version 4; pushint 1"
+	program := []byte{0x04, 0x81, 0x01}
+	c.CompileResponse.Result = base64.StdEncoding.EncodeToString(program)
+
+	signer, err := DelegateWrites(c, acc, 6, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, acc.Address, signer.Address())
+
+	params := types.SuggestedParams{Fee: MinTxnFee, FlatFee: true, FirstRoundValid: 10, LastRoundValid: 20}
+	args := [][]byte{[]byte("key"), []byte("value")}
+	txn, err := future.MakeApplicationNoOpTx(6, args, nil, nil, nil, params, acc.Address, []byte("put"),
+		types.Digest{}, [32]byte{}, types.Address{})
+	assert.Nil(t, err)
+	b, err := signer.SignTransaction(txn)
+	assert.Nil(t, err)
+
+	var stx types.SignedTxn
+	assert.Nil(t, msgpack.Decode(b, &stx))
+	assert.Equal(t, program, stx.Lsig.Logic)
+	assert.True(t, stx.AuthAddr.IsZero())
+	assert.True(t, crypto.VerifyLogicSig(stx.Lsig, acc.Address))
+	assert.False(t, crypto.VerifyLogicSig(stx.Lsig, crypto.GenerateAccount().Address))
+}
+
+func TestDelegatedSigner_NotDelegated(t *testing.T) {
+	lsa := crypto.MakeLogicSigAccountEscrow([]byte{0x04, 0x81, 0x01}, nil)
+	_, err := NewDelegatedSigner(lsa)
+	assert.NotNil(t, err)
+}
+
+func TestDelegatedWriteTeal(t *testing.T) {
+	teal := DelegatedWriteTeal(6, 1000)
+	assert.True(t, strings.HasPrefix(teal, "#pragma version 4"))
+	assert.Contains(t, teal, "int 6\n")
+	assert.Contains(t, teal, "int 1000\n")
+}
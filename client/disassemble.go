@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common"
+)
+
+// nodeEndpoint holds the connection details of a node, for requests that the algod
+// client of the go-algorand-sdk doesn't support.
+type nodeEndpoint struct {
+	url string
+	// authHeader is the header of the token. If empty, DefaultAuthHeader is used.
+	authHeader string
+	token      string
+	headers    []*common.Header
+}
+
+// post sends body to the given path of the node, and decodes the JSON response.
+// Errors are formatted like the ones of the algod client (e.g. "HTTP 400: ...").
+func (e *nodeEndpoint) post(ctx context.Context, path string, body []byte, response interface{}) error {
+	url := strings.TrimSuffix(e.url, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-binary")
+	authHeader := e.authHeader
+	if authHeader == "" {
+		authHeader = DefaultAuthHeader
+	}
+	req.Header.Set(authHeader, e.token)
+	for _, h := range e.headers {
+		req.Header.Set(h.Key, h.Value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, b)
+	}
+	return json.Unmarshal(b, response)
+}
+
+// DisassembleProgram sends the bytecode to the disassemble endpoint of the node. The
+// go-algorand-sdk doesn't support the endpoint, so the wrapper must have been created
+// with CreateAlgorandClientWrapper, NewClientWithHeaders or NewClientWithAuthHeader.
+func (a *AlgorandClientWrapper) DisassembleProgram(bytecode []byte, ctx context.Context) (source string, err error) {
+	if a.endpoint == nil {
+		return "", errors.New("disassembling requires a client created with CreateAlgorandClientWrapper, NewClientWithHeaders or NewClientWithAuthHeader")
+	}
+	err = a.request(ctx, func(ctx context.Context) error {
+		var response struct {
+			Result string `json:"result"`
+		}
+		err := a.endpoint.post(ctx, "/v2/teal/disassemble", bytecode, &response)
+		source = response.Result
+		return err
+	})
+	return source, err
+}
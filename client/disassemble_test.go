@@ -0,0 +1,42 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandClientWrapper_DisassembleProgram(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/teal/disassemble", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid program"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"#pragma version 4\npushint 1\n"}`))
+	}))
+	t.Cleanup(server.Close)
+	headers := []*common.Header{{Key: "X-API-Key", Value: "secret"}}
+	c, err := NewClientWithHeaders(server.URL, "", headers)
+	assert.Nil(t, err)
+
+	source, err := c.DisassembleProgram([]byte{0x04, 0x81, 0x01}, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "#pragma version 4\npushint 1\n", source)
+
+	_, err = c.DisassembleProgram(nil, context.Background())
+	assert.Contains(t, err.Error(), "HTTP 400")
+
+	_, err = (&AlgorandClientWrapper{}).DisassembleProgram([]byte{0x04}, context.Background())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// The values of the exclude parameter of algod's account information endpoint. algod
+// can't exclude single fields: ExcludeAll leaves out the assets, created assets, local
+// states and created apps of the account, ExcludeNone leaves out nothing.
+const (
+	ExcludeAll  = "all"
+	ExcludeNone = "none"
+)
+
+// ExcludingClient is implemented by clients that can leave fields out of account
+// information. All clients of this package implement it.
+type ExcludingClient interface {
+	// AccountInformationExcluding returns account information without the fields
+	// given by the exclude values. algod only supports ExcludeAll and ExcludeNone,
+	// other values return an error. Excluding all fields reduces the response payload
+	// for accounts that hold many assets.
+	AccountInformationExcluding(string, []string, context.Context) (models.Account, error)
+}
+
+// AccountInformationExcluding returns the information of the account with the given
+// address, without the fields given by the exclude values, if a is an
+// ExcludingClient. Otherwise, the full account information is returned.
+func AccountInformationExcluding(a AlgorandClient, addr string, exclude []string, ctx context.Context) (models.Account, error) {
+	if e, ok := a.(ExcludingClient); ok {
+		return e.AccountInformationExcluding(addr, exclude, ctx)
+	}
+	if _, err := excludeParam(exclude); err != nil {
+		return models.Account{}, err
+	}
+	return a.AccountInformation(addr, ctx)
+}
+
+// excludeParam returns the exclude parameter of an account information request that
+// excludes the given fields. Returns an error for values algod doesn't support.
+func excludeParam(exclude []string) (string, error) {
+	param := ExcludeNone
+	for _, field := range exclude {
+		switch field {
+		case ExcludeAll:
+			param = ExcludeAll
+		case ExcludeNone:
+		default:
+			return "", fmt.Errorf("unsupported exclude value %q, algod only supports %q and %q", field, ExcludeAll, ExcludeNone)
+		}
+	}
+	return param, nil
+}
+
+// excludeFields removes the fields of acc that algod leaves out for the given exclude
+// values.
+func excludeFields(acc models.Account, exclude []string) (models.Account, error) {
+	param, err := excludeParam(exclude)
+	if err != nil {
+		return models.Account{}, err
+	}
+	if param == ExcludeAll {
+		acc.Assets = nil
+		acc.CreatedAssets = nil
+		acc.AppsLocalState = nil
+		acc.CreatedApps = nil
+	}
+	return acc, nil
+}
@@ -0,0 +1,53 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Only the exclude values algod supports are sent.
+func TestAlgorandClientWrapper_AccountInformationExcluding(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/v2/accounts/ABC", r.URL.Path)
+		assert.Equal(t, ExcludeAll, r.URL.Query().Get("exclude"))
+		_, _ = w.Write([]byte(`{"address":"ABC","amount":5}`))
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateAlgorandClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+
+	acc, err := c.AccountInformationExcluding("ABC", []string{ExcludeAll}, context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 5, acc.Amount)
+
+	_, err = c.AccountInformationExcluding("ABC", []string{"assets", "created-assets"}, context.Background())
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+// Clients that aren't an ExcludingClient return the full account
+func TestAccountInformationExcluding(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.CreateDummyApps(6)
+	plain := struct{ AlgorandClient }{m}
+
+	acc, err := AccountInformationExcluding(plain, "", []string{ExcludeAll}, context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, acc.CreatedApps, 1)
+	assert.Equal(t, 0, m.CallCount((*AlgorandMock).AccountInformationExcluding))
+	_, err = AccountInformationExcluding(plain, "", []string{"assets"}, context.Background())
+	assert.NotNil(t, err)
+
+	acc, err = AccountInformationExcluding(WithTimeouts(m, Timeouts{}), "", []string{ExcludeAll}, context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, acc.CreatedApps, 0)
+	assert.Equal(t, 1, m.CallCount((*AlgorandMock).AccountInformationExcluding))
+}
@@ -0,0 +1,572 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// FakeLedger implements the AlgorandClient interface with an in-memory ledger.
+// Unlike AlgorandMock, it doesn't return canned responses: submitted transactions
+// are verified and applied to a consistent state of accounts, balances and
+// applications. Application calls follow the semantics of approval.teal. Every
+// accepted transaction (or atomic group) is confirmed in its own round.
+//
+// Use it for end-to-end tests of code that creates, writes and deletes apps.
+type FakeLedger struct {
+	// Signer signs all transactions of the ledger's client methods, if set (see
+	// AlgorandClientWrapper.Signer). By default, they're signed with the private key of
+	// the given account.
+	Signer AccountSigner
+
+	round     uint64
+	nextAppId uint64
+	accounts  map[types.Address]*fakeAccount
+	apps      map[uint64]*models.Application
+	pending   map[string]models.PendingTransactionInfoResponse
+	leases    map[txnLease]uint64
+	mu        sync.Mutex
+}
+
+// txnLease identifies the lease of a transaction. The ledger maps it to the last
+// valid round of the transaction that holds it.
+type txnLease struct {
+	sender types.Address
+	lease  [32]byte
+}
+
+// fakeAccount is the balance record of an account in the FakeLedger
+type fakeAccount struct {
+	amount   uint64
+	authAddr types.Address
+}
+
+// FakeLedgerVersion is the consensus version reported by the FakeLedger. It supports
+// box storage.
+const FakeLedgerVersion = ConsensusFuture
+
+// NewFakeLedger creates an empty FakeLedger.
+func NewFakeLedger() *FakeLedger {
+	return &FakeLedger{
+		round:     1,
+		nextAppId: 1,
+		accounts:  make(map[types.Address]*fakeAccount),
+		apps:      make(map[uint64]*models.Application),
+		pending:   make(map[string]models.PendingTransactionInfoResponse),
+		leases:    make(map[txnLease]uint64),
+	}
+}
+
+// Fund adds the given amount of microAlgos to the account with address addr.
+func (l *FakeLedger) Fund(addr types.Address, amount uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.account(addr).amount += amount
+}
+
+// Balance returns the balance of the account with address addr in microAlgos.
+func (l *FakeLedger) Balance(addr types.Address) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.account(addr).amount
+}
+
+// account returns the balance record of addr, creating an empty one if needed.
+func (l *FakeLedger) account(addr types.Address) *fakeAccount {
+	acc, ok := l.accounts[addr]
+	if !ok {
+		acc = &fakeAccount{}
+		l.accounts[addr] = acc
+	}
+	return acc
+}
+
+// createdApps returns all apps created by the given address.
+func (l *FakeLedger) createdApps(addr types.Address) []models.Application {
+	apps := make([]models.Application, 0)
+	for id := uint64(1); id < l.nextAppId; id++ {
+		if app, ok := l.apps[id]; ok && app.Params.Creator == addr.String() {
+			apps = append(apps, copyApplication(*app))
+		}
+	}
+	return apps
+}
+
+// minBalance returns the minimum balance of the given address, based on the apps
+// it created.
+func (l *FakeLedger) minBalance(addr types.Address) uint64 {
+	return MinAccountBalance(l.createdApps(addr))
+}
+
+func (l *FakeLedger) SuggestedParams(context.Context) (types.SuggestedParams, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return types.SuggestedParams{
+		Fee:             MinTxnFee,
+		FlatFee:         true,
+		MinFee:          MinTxnFee,
+		GenesisID:       "fake-ledger",
+		GenesisHash:     make([]byte, 32),
+		FirstRoundValid: types.Round(l.round),
+		LastRoundValid:  types.Round(l.round + 1000),
+	}, nil
+}
+
+func (l *FakeLedger) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (l *FakeLedger) Status(context.Context) (models.NodeStatus, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return models.NodeStatus{LastRound: l.round, LastVersion: FakeLedgerVersion}, nil
+}
+
+// StatusAfterBlock advances the ledger to the round after the given round, if it
+// hasn't been reached yet.
+func (l *FakeLedger) StatusAfterBlock(round uint64, _ context.Context) (models.NodeStatus, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.round <= round {
+		l.round = round + 1
+	}
+	return models.NodeStatus{LastRound: l.round}, nil
+}
+
+func (l *FakeLedger) AccountInformation(addr string, _ context.Context) (models.Account, error) {
+	a, err := types.DecodeAddress(addr)
+	if err != nil {
+		return models.Account{}, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	acc := models.Account{
+		Address:     addr,
+		Amount:      l.account(a).amount,
+		CreatedApps: l.createdApps(a),
+		Round:       l.round,
+	}
+	if !l.account(a).authAddr.IsZero() {
+		acc.AuthAddr = l.account(a).authAddr.String()
+	}
+	return acc, nil
+}
+
+func (l *FakeLedger) AccountInformationExcluding(addr string, exclude []string, ctx context.Context) (models.Account, error) {
+	acc, err := l.AccountInformation(addr, ctx)
+	if err != nil {
+		return acc, err
+	}
+	return excludeFields(acc, exclude)
+}
+
+func (l *FakeLedger) GetApplicationByID(id uint64, _ context.Context) (models.Application, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	app, ok := l.apps[id]
+	if !ok {
+		return models.Application{}, fmt.Errorf("application does not exist: %d", id)
+	}
+	return copyApplication(*app), nil
+}
+
+// SendRawTransaction decodes, verifies and applies a signed transaction, or an atomic
+// group of concatenated signed transactions. The transactions are confirmed
+// immediately in a new round. If a transaction of a group is rejected, none of them
+// are applied. Returns the ID of the first transaction.
+func (l *FakeLedger) SendRawTransaction(b []byte, _ context.Context) (string, error) {
+	stxns := make([]types.SignedTxn, 0, 1)
+	dec := msgpack.NewDecoder(bytes.NewReader(b))
+	for {
+		var stx types.SignedTxn
+		err := dec.Decode(&stx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		stxns = append(stxns, stx)
+	}
+	if len(stxns) == 0 {
+		return "", errors.New("empty transaction")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := verifyGroup(stxns); err != nil {
+		return "", err
+	}
+	accounts, apps := l.snapshot()
+	infos := make([]models.PendingTransactionInfoResponse, len(stxns))
+	for i, stx := range stxns {
+		info, err := l.applySigned(stx)
+		if err != nil {
+			l.accounts, l.apps = accounts, apps
+			return "", err
+		}
+		infos[i] = info
+	}
+	for _, stx := range stxns {
+		if stx.Txn.Lease != ([32]byte{}) {
+			l.leases[txnLease{sender: stx.Txn.Sender, lease: stx.Txn.Lease}] = uint64(stx.Txn.LastValid)
+		}
+	}
+	l.round++
+	for i, stx := range stxns {
+		infos[i].ConfirmedRound = l.round
+		infos[i].Transaction = stx
+		l.pending[crypto.GetTxID(stx.Txn)] = infos[i]
+	}
+	return crypto.GetTxID(stxns[0].Txn), nil
+}
+
+// applySigned verifies and applies a single signed transaction.
+func (l *FakeLedger) applySigned(stx types.SignedTxn) (models.PendingTransactionInfoResponse, error) {
+	if err := l.verify(stx); err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	lease := txnLease{sender: stx.Txn.Sender, lease: stx.Txn.Lease}
+	if lastValid, ok := l.leases[lease]; ok && l.round <= lastValid {
+		return models.PendingTransactionInfoResponse{}, fmt.Errorf("transaction using an overlapping lease (sender, lease): (%s, %x)", lease.sender, lease.lease)
+	}
+	return l.apply(stx.Txn)
+}
+
+// verifyGroup checks that the transactions are a valid atomic group, or a single
+// transaction without a group.
+func verifyGroup(stxns []types.SignedTxn) error {
+	if len(stxns) == 1 && stxns[0].Txn.Group == (types.Digest{}) {
+		return nil
+	}
+	txns := make([]types.Transaction, len(stxns))
+	for i, stx := range stxns {
+		txns[i] = stx.Txn
+	}
+	grouped, err := assignGroup(txns)
+	if err != nil {
+		return err
+	}
+	for _, txn := range txns {
+		if txn.Group != grouped[0].Group {
+			return fmt.Errorf("incomplete group: %v != %v", txn.Group, grouped[0].Group)
+		}
+	}
+	return nil
+}
+
+func (l *FakeLedger) PendingTransactionInformation(txID string, _ context.Context) (models.PendingTransactionInfoResponse, types.SignedTxn, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	info, ok := l.pending[txID]
+	if !ok {
+		return models.PendingTransactionInfoResponse{}, types.SignedTxn{}, fmt.Errorf("txn does not exist: %s", txID)
+	}
+	return info, info.Transaction, nil
+}
+
+// TealCompile returns the program source as its "compiled" program.
+func (l *FakeLedger) TealCompile(program []byte, _ context.Context) (models.CompileResponse, error) {
+	return models.CompileResponse{
+		Hash:   crypto.AddressFromProgram(program).String(),
+		Result: base64.StdEncoding.EncodeToString(program),
+	}, nil
+}
+
+// PendingTransactionsByAddress returns no transactions, because the ledger confirms
+// transactions right away.
+func (l *FakeLedger) PendingTransactionsByAddress(string, uint64, context.Context) ([]types.SignedTxn, error) {
+	return []types.SignedTxn{}, nil
+}
+
+// DisassembleProgram returns the program as its source, the inverse of TealCompile.
+func (l *FakeLedger) DisassembleProgram(bytecode []byte, _ context.Context) (string, error) {
+	return string(bytecode), nil
+}
+
+func (l *FakeLedger) ExecuteTransaction(acc crypto.Account, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	signedTxn, err := l.signer(acc).SignTransaction(txn)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	txID, err := l.SendRawTransaction(signedTxn, ctx)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	info, _, err := l.PendingTransactionInformation(txID, ctx)
+	return info, err
+}
+
+func (l *FakeLedger) ExecuteGroup(acc crypto.Account, txns []types.Transaction, ctx context.Context) ([]models.PendingTransactionInfoResponse, error) {
+	return executeGroup(l, l.signer(acc), txns, ctx)
+}
+
+// signer returns the Signer of the ledger, or a KeySigner of acc if none is set.
+func (l *FakeLedger) signer(acc crypto.Account) AccountSigner {
+	if l.Signer != nil {
+		return l.Signer
+	}
+	return NewKeySigner(acc)
+}
+
+func (l *FakeLedger) DeleteApplication(acc crypto.Account, appId uint64) error {
+	return deleteApplication(l, acc, appId)
+}
+
+func (l *FakeLedger) CreateApplication(acc crypto.Account, approve string, clear string) (uint64, error) {
+	return createApplication(l, acc, approve, clear)
+}
+
+func (l *FakeLedger) StoreGlobals(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) error {
+	_, err := storeGlobals(l, acc, appId, tkv)
+	return err
+}
+
+func (l *FakeLedger) StoreGlobalsResult(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	return storeGlobals(l, acc, appId, tkv)
+}
+
+func (l *FakeLedger) DeleteGlobals(acc crypto.Account, appId uint64, keys ...string) error {
+	_, err := deleteGlobals(l, acc, appId, keys...)
+	return err
+}
+
+func (l *FakeLedger) DeleteGlobalsResult(acc crypto.Account, appId uint64, keys ...string) (models.PendingTransactionInfoResponse, error) {
+	return deleteGlobals(l, acc, appId, keys...)
+}
+
+// verify checks the validity window and the signature of a signed transaction
+// against the auth address of its sender.
+func (l *FakeLedger) verify(stx types.SignedTxn) error {
+	txn := stx.Txn
+	if l.round < uint64(txn.FirstValid) || l.round > uint64(txn.LastValid) {
+		return fmt.Errorf("txn dead: round %d outside of %d--%d", l.round, txn.FirstValid, txn.LastValid)
+	}
+	auth := l.account(txn.Sender).authAddr
+	if auth.IsZero() {
+		auth = txn.Sender
+	}
+	signer := txn.Sender
+	if !stx.AuthAddr.IsZero() {
+		signer = stx.AuthAddr
+	}
+	if signer != auth {
+		return fmt.Errorf("should have been authorized by %s but was actually authorized by %s", auth, signer)
+	}
+	toBeSigned := append([]byte("TX"), msgpack.Encode(txn)...)
+	if !stx.Msig.Blank() {
+		if !crypto.VerifyMultisig(signer, toBeSigned, stx.Msig) {
+			return errors.New("invalid multisig signature")
+		}
+		return nil
+	}
+	if !ed25519.Verify(signer[:], toBeSigned, stx.Sig[:]) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// apply applies the effects of a transaction to the ledger. No state is changed
+// if the transaction is rejected.
+func (l *FakeLedger) apply(txn types.Transaction) (models.PendingTransactionInfoResponse, error) {
+	accounts, apps := l.snapshot()
+	info, err := l.applyUnchecked(txn)
+	if err == nil && l.account(txn.Sender).amount < l.minBalance(txn.Sender) {
+		err = fmt.Errorf("account %s balance %d below min %d", txn.Sender, l.account(txn.Sender).amount, l.minBalance(txn.Sender))
+	}
+	if err != nil {
+		l.accounts, l.apps = accounts, apps
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	return info, nil
+}
+
+func (l *FakeLedger) applyUnchecked(txn types.Transaction) (models.PendingTransactionInfoResponse, error) {
+	var info models.PendingTransactionInfoResponse
+	sender := l.account(txn.Sender)
+	if uint64(txn.Fee) < MinTxnFee {
+		return info, fmt.Errorf("transaction had fee %d, which is less than the minimum %d", txn.Fee, MinTxnFee)
+	}
+	if sender.amount < uint64(txn.Fee) {
+		return info, fmt.Errorf("overspend: account %s balance %d, fee %d", txn.Sender, sender.amount, txn.Fee)
+	}
+	sender.amount -= uint64(txn.Fee)
+
+	var err error
+	switch txn.Type {
+	case types.PaymentTx:
+		err = l.applyPayment(txn)
+	case types.ApplicationCallTx:
+		info, err = l.applyApplicationCall(txn)
+	default:
+		err = fmt.Errorf("unsupported transaction type %s", txn.Type)
+	}
+	if err != nil {
+		return info, err
+	}
+	if !txn.RekeyTo.IsZero() {
+		sender.authAddr = txn.RekeyTo
+		if txn.RekeyTo == txn.Sender {
+			sender.authAddr = types.Address{}
+		}
+	}
+	return info, nil
+}
+
+func (l *FakeLedger) applyPayment(txn types.Transaction) error {
+	sender := l.account(txn.Sender)
+	if sender.amount < uint64(txn.Amount) {
+		return fmt.Errorf("overspend: account %s balance %d, amount %d", txn.Sender, sender.amount, txn.Amount)
+	}
+	sender.amount -= uint64(txn.Amount)
+	l.account(txn.Receiver).amount += uint64(txn.Amount)
+	return nil
+}
+
+// snapshot returns deep copies of the accounts and apps of the ledger.
+func (l *FakeLedger) snapshot() (map[types.Address]*fakeAccount, map[uint64]*models.Application) {
+	accounts := make(map[types.Address]*fakeAccount, len(l.accounts))
+	for k, v := range l.accounts {
+		acc := *v
+		accounts[k] = &acc
+	}
+	apps := make(map[uint64]*models.Application, len(l.apps))
+	for k, v := range l.apps {
+		app := copyApplication(*v)
+		apps[k] = &app
+	}
+	return accounts, apps
+}
+
+// applyApplicationCall applies an application call following the semantics of
+// approval.teal: only the creator may call or delete the app, and the note of
+// a NoOp call decides whether the arguments are put or deleted. Like the stack loop of
+// approval.teal, the arguments are applied from last to first, so the first value
+// of a key that is put several times wins.
+func (l *FakeLedger) applyApplicationCall(txn types.Transaction) (models.PendingTransactionInfoResponse, error) {
+	var info models.PendingTransactionInfoResponse
+	id := uint64(txn.ApplicationID)
+	if id == 0 {
+		id = l.nextAppId
+		l.nextAppId++
+		g := txn.GlobalStateSchema
+		lc := txn.LocalStateSchema
+		l.apps[id] = &models.Application{
+			Id:             id,
+			CreatedAtRound: l.round + 1,
+			Params: models.ApplicationParams{
+				Creator:           txn.Sender.String(),
+				ApprovalProgram:   txn.ApprovalProgram,
+				ClearStateProgram: txn.ClearStateProgram,
+				GlobalStateSchema: models.ApplicationStateSchema{NumUint: g.NumUint, NumByteSlice: g.NumByteSlice},
+				LocalStateSchema:  models.ApplicationStateSchema{NumUint: lc.NumUint, NumByteSlice: lc.NumByteSlice},
+			},
+		}
+		info.ApplicationIndex = id
+		return info, nil
+	}
+
+	app, ok := l.apps[id]
+	if !ok {
+		return info, fmt.Errorf("application does not exist: %d", id)
+	}
+	if app.Params.Creator != txn.Sender.String() {
+		return info, errors.New("transaction rejected by ApprovalProgram")
+	}
+
+	switch txn.OnCompletion {
+	case types.DeleteApplicationOC:
+		delete(l.apps, id)
+		return info, nil
+	case types.NoOpOC:
+	default:
+		return info, errors.New("transaction rejected by ApprovalProgram")
+	}
+
+	args := txn.ApplicationArgs
+	if len(args) == 0 {
+		return info, nil
+	}
+	state := make([]models.TealKeyValue, len(app.Params.GlobalState))
+	copy(state, app.Params.GlobalState)
+	switch string(txn.Note) {
+	case "put":
+		if len(args)%2 != 0 {
+			return info, errors.New("transaction rejected by ApprovalProgram")
+		}
+		for i := len(args) - 2; i >= 0; i -= 2 {
+			state = putGlobal(state, args[i], args[i+1])
+			info.GlobalStateDelta = putDelta(info.GlobalStateDelta, args[i],
+				models.EvalDelta{Action: 1, Bytes: base64.StdEncoding.EncodeToString(args[i+1])})
+		}
+		if uint64(len(state)) > app.Params.GlobalStateSchema.NumByteSlice {
+			return models.PendingTransactionInfoResponse{}, fmt.Errorf("store bytes count %d exceeds schema bytes count %d",
+				len(state), app.Params.GlobalStateSchema.NumByteSlice)
+		}
+	case "delete":
+		for i := len(args) - 1; i >= 0; i-- {
+			state = deleteGlobal(state, args[i])
+			info.GlobalStateDelta = putDelta(info.GlobalStateDelta, args[i], models.EvalDelta{Action: 3})
+		}
+	default:
+		return info, errors.New("transaction rejected by ApprovalProgram")
+	}
+	app.Params.GlobalState = state
+	return info, nil
+}
+
+// putDelta sets the delta of key in the given global state delta. Like the delta of
+// algod, it holds one entry per key.
+func putDelta(delta []models.EvalDeltaKeyValue, key []byte, value models.EvalDelta) []models.EvalDeltaKeyValue {
+	k := base64.StdEncoding.EncodeToString(key)
+	for i, kv := range delta {
+		if kv.Key == k {
+			delta[i].Value = value
+			return delta
+		}
+	}
+	return append(delta, models.EvalDeltaKeyValue{Key: k, Value: value})
+}
+
+// putGlobal sets key to value in the given global state.
+func putGlobal(state []models.TealKeyValue, key []byte, value []byte) []models.TealKeyValue {
+	k := base64.StdEncoding.EncodeToString(key)
+	v := models.TealValue{Type: 1, Bytes: base64.StdEncoding.EncodeToString(value)}
+	for i, kv := range state {
+		if kv.Key == k {
+			state[i].Value = v
+			return state
+		}
+	}
+	return append(state, models.TealKeyValue{Key: k, Value: v})
+}
+
+// deleteGlobal removes key from the given global state.
+func deleteGlobal(state []models.TealKeyValue, key []byte) []models.TealKeyValue {
+	k := base64.StdEncoding.EncodeToString(key)
+	for i, kv := range state {
+		if kv.Key == k {
+			return append(state[:i], state[i+1:]...)
+		}
+	}
+	return state
+}
+
+// copyApplication returns a deep copy of the app's global state, so that callers
+// can't modify the ledger.
+func copyApplication(app models.Application) models.Application {
+	state := make([]models.TealKeyValue, len(app.Params.GlobalState))
+	copy(state, app.Params.GlobalState)
+	app.Params.GlobalState = state
+	return app
+}
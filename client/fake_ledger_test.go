@@ -0,0 +1,133 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ AlgorandClient = (*FakeLedger)(nil)
+
+func TestFakeLedger_CreateAndDelete(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+
+	id, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+	info, err := l.AccountInformation(acc.Address.String(), context.Background())
+	assert.Nil(t, err)
+	assert.True(t, ValidAccount(info))
+	assert.Equal(t, id, info.CreatedApps[0].Id)
+	assert.EqualValues(t, 10000000-MinTxnFee, info.Amount)
+
+	assert.Nil(t, l.DeleteApplication(acc, id))
+	info, _ = l.AccountInformation(acc.Address.String(), context.Background())
+	assert.Len(t, info.CreatedApps, 0)
+	_, err = l.GetApplicationByID(id, context.Background())
+	assert.NotNil(t, err)
+}
+
+// App creation must fail without state changes if the minimum balance isn't met
+func TestFakeLedger_MinBalance(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 1000000)
+
+	_, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.NotNil(t, err)
+	info, _ := l.AccountInformation(acc.Address.String(), context.Background())
+	assert.Len(t, info.CreatedApps, 0)
+	assert.EqualValues(t, 1000000, info.Amount)
+}
+
+// Only the creator may write to or delete the app
+func TestFakeLedger_OnlyCreator(t *testing.T) {
+	l := NewFakeLedger()
+	acc, other := crypto.GenerateAccount(), crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	l.Fund(other.Address, 10000000)
+	id, _ := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+
+	kv := []models.TealKeyValue{{Key: "k", Value: models.TealValue{Bytes: "v"}}}
+	assert.NotNil(t, l.StoreGlobals(other, id, kv))
+	assert.NotNil(t, l.DeleteApplication(other, id))
+	assert.Nil(t, l.StoreGlobals(acc, id, kv))
+
+	app, _ := l.GetApplicationByID(id, context.Background())
+	assert.Len(t, app.Params.GlobalState, 1)
+	assertEqualBase64(t, app.Params.GlobalState[0].Key, "k")
+	assertEqualBase64(t, app.Params.GlobalState[0].Value.Bytes, "v")
+}
+
+// Writing more keys than the schema allows rejects the whole transaction
+func TestFakeLedger_SchemaLimit(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	id, _ := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+
+	for i := 0; i < GlobalBytes; i += MaxKVArgs {
+		kv := make([]models.TealKeyValue, MaxKVArgs)
+		for j := range kv {
+			kv[j] = models.TealKeyValue{Key: string(rune('A' + i + j))}
+		}
+		assert.Nil(t, l.StoreGlobals(acc, id, kv))
+	}
+	err := l.StoreGlobals(acc, id, []models.TealKeyValue{{Key: "overflow"}})
+	assert.NotNil(t, err)
+	app, _ := l.GetApplicationByID(id, context.Background())
+	assert.Len(t, app.Params.GlobalState, GlobalBytes)
+}
+
+// Only one of two transactions with the same lease is confirmed
+func TestFakeLedger_Lease(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	id, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+
+	lease := [32]byte{1}
+	first := []models.TealKeyValue{{Key: "owner", Value: models.TealValue{Bytes: "a"}}}
+	second := []models.TealKeyValue{{Key: "owner", Value: models.TealValue{Bytes: "b"}}}
+	_, err = StoreGlobalsWithLease(l, acc, id, first, lease)
+	assert.Nil(t, err)
+	_, err = StoreGlobalsWithLease(l, acc, id, second, lease)
+	assert.NotNil(t, err)
+
+	state, err := ReadGlobalState(l, id, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "a", string(state["owner"]))
+
+	// transactions without lease never conflict
+	assert.Nil(t, l.StoreGlobals(acc, id, second))
+	assert.Nil(t, l.StoreGlobals(acc, id, first))
+}
+
+// Like approval.teal, the pairs of a transaction are applied from last to first, so the
+// first value of a duplicate key wins.
+func TestFakeLedger_DuplicateKey(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	id, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+
+	info, err := l.StoreGlobalsResult(acc, id, []models.TealKeyValue{
+		{Key: "a", Value: models.TealValue{Bytes: "1"}},
+		{Key: "b", Value: models.TealValue{Bytes: "2"}},
+		{Key: "a", Value: models.TealValue{Bytes: "3"}},
+	})
+	assert.Nil(t, err)
+	state, err := ReadGlobalState(l, id, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "1", string(state["a"]))
+	assert.Equal(t, "2", string(state["b"]))
+	assert.Len(t, info.GlobalStateDelta, 2)
+}
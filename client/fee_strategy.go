@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// FeeMode selects how a FeeStrategy computes the fee of a transaction.
+type FeeMode int
+
+const (
+	// FeeSuggested pays the fee computed from the suggested params of the node.
+	FeeSuggested FeeMode = iota
+
+	// FeeFlat pays FeeStrategy.Flat for every transaction, e.g. zero on a private
+	// network.
+	FeeFlat
+
+	// FeeMultiple pays FeeStrategy.Multiplier times the suggested fee, e.g. to get
+	// transactions confirmed faster during congestion.
+	FeeMultiple
+)
+
+// FeeStrategy determines the fees of transactions (see WithFeeStrategy). The
+// suggested fee is the fee of the transaction under the suggested params of the
+// node, i.e. its size times the fee per byte, but at least the minimum fee.
+type FeeStrategy struct {
+	Mode FeeMode
+
+	// Flat is the fee of every transaction in microAlgos, with FeeFlat.
+	Flat uint64
+
+	// Multiplier is the factor of the suggested fee, with FeeMultiple. Must be
+	// positive.
+	Multiplier float64
+
+	// Max caps the fee of a single transaction in microAlgos, so that a runaway
+	// multiplier can't drain the account. If zero, fees aren't capped.
+	Max uint64
+}
+
+// Validate returns an error, if the strategy can't compute fees.
+func (s FeeStrategy) Validate() error {
+	switch s.Mode {
+	case FeeSuggested, FeeFlat:
+	case FeeMultiple:
+		if s.Multiplier <= 0 || math.IsInf(s.Multiplier, 0) || math.IsNaN(s.Multiplier) {
+			return fmt.Errorf("fee multiplier must be positive, got %v", s.Multiplier)
+		}
+	default:
+		return fmt.Errorf("unknown fee mode %d", s.Mode)
+	}
+	return nil
+}
+
+// Fee returns the fee of a transaction with the given suggested fee.
+func (s FeeStrategy) Fee(suggested uint64) uint64 {
+	fee := suggested
+	switch s.Mode {
+	case FeeFlat:
+		fee = s.Flat
+	case FeeMultiple:
+		f := math.Ceil(float64(suggested) * s.Multiplier)
+		if f >= math.MaxUint64 {
+			fee = math.MaxUint64
+		} else {
+			fee = uint64(f)
+		}
+	}
+	if s.Max > 0 && fee > s.Max {
+		fee = s.Max
+	}
+	return fee
+}
+
+// feeClient applies a FeeStrategy to the transactions of the wrapped client.
+type feeClient struct {
+	AlgorandClient
+	strategy FeeStrategy
+}
+
+// WithFeeStrategy returns an AlgorandClient that sets the fee of every transaction it
+// executes according to s, before it's signed. The fee suggested by the node is the
+// fee the transaction was built with. The info responses carry the submitted
+// transaction, so the paid fee can be read from their Transaction field. Like
+// WithSubmitHook, wrap clients with WithFeeStrategy before WithTimeouts, not after.
+func WithFeeStrategy(c AlgorandClient, s FeeStrategy) AlgorandClient {
+	return &feeClient{AlgorandClient: c, strategy: s}
+}
+
+func (f *feeClient) ExecuteTransaction(acc crypto.Account, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	txn.Fee = types.MicroAlgos(f.strategy.Fee(uint64(txn.Fee)))
+	info, err := f.AlgorandClient.ExecuteTransaction(acc, txn, ctx)
+	if err == nil && info.Transaction.Txn.Type == "" {
+		info.Transaction.Txn = txn
+	}
+	return info, err
+}
+
+func (f *feeClient) ExecuteGroup(acc crypto.Account, txns []types.Transaction, ctx context.Context) ([]models.PendingTransactionInfoResponse, error) {
+	adjusted := make([]types.Transaction, len(txns))
+	for i, txn := range txns {
+		txn.Fee = types.MicroAlgos(f.strategy.Fee(uint64(txn.Fee)))
+		adjusted[i] = txn
+	}
+	infos, err := f.AlgorandClient.ExecuteGroup(acc, adjusted, ctx)
+	if err == nil {
+		for i := range infos {
+			if i < len(adjusted) && infos[i].Transaction.Txn.Type == "" {
+				infos[i].Transaction.Txn = adjusted[i]
+			}
+		}
+	}
+	return infos, err
+}
+
+func (f *feeClient) AccountInformationExcluding(addr string, exclude []string, ctx context.Context) (models.Account, error) {
+	return AccountInformationExcluding(f.AlgorandClient, addr, exclude, ctx)
+}
+
+func (f *feeClient) DeleteApplication(acc crypto.Account, appId uint64) error {
+	return deleteApplication(f, acc, appId)
+}
+
+func (f *feeClient) CreateApplication(acc crypto.Account, approve string, clear string) (uint64, error) {
+	return createApplication(f, acc, approve, clear)
+}
+
+func (f *feeClient) DeleteGlobals(acc crypto.Account, appId uint64, keys ...string) error {
+	_, err := deleteGlobals(f, acc, appId, keys...)
+	return err
+}
+
+func (f *feeClient) DeleteGlobalsResult(acc crypto.Account, appId uint64, keys ...string) (models.PendingTransactionInfoResponse, error) {
+	return deleteGlobals(f, acc, appId, keys...)
+}
+
+func (f *feeClient) StoreGlobals(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) error {
+	_, err := storeGlobals(f, acc, appId, tkv)
+	return err
+}
+
+func (f *feeClient) StoreGlobalsResult(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	return storeGlobals(f, acc, appId, tkv)
+}
@@ -0,0 +1,56 @@
+//go:build unit
+
+package client
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeStrategy_Fee(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		strategy FeeStrategy
+		want     uint64
+	}{
+		{"suggested", FeeStrategy{}, 1500},
+		{"flat", FeeStrategy{Mode: FeeFlat, Flat: 0}, 0},
+		{"multiple", FeeStrategy{Mode: FeeMultiple, Multiplier: 2.5}, 3750},
+		{"capped", FeeStrategy{Mode: FeeMultiple, Multiplier: 1000, Max: 10000}, 10000},
+		{"capped suggested", FeeStrategy{Max: 1000}, 1000},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Nil(t, tc.strategy.Validate())
+			assert.Equal(t, tc.want, tc.strategy.Fee(1500))
+		})
+	}
+	assert.NotNil(t, FeeStrategy{Mode: FeeMultiple}.Validate())
+	assert.NotNil(t, FeeStrategy{Mode: FeeMode(7)}.Validate())
+}
+
+// The fees of the strategy are paid, and the info responses carry the fee
+func TestWithFeeStrategy(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	c := WithFeeStrategy(l, FeeStrategy{Mode: FeeMultiple, Multiplier: 3})
+
+	appId, err := c.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+	before := l.Balance(acc.Address)
+	info, err := StoreGlobalsResult(c, acc, appId, []models.TealKeyValue{{Key: "k", Value: models.TealValue{Bytes: "v"}}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3*MinTxnFee, info.Transaction.Txn.Fee)
+	assert.Equal(t, before-3*MinTxnFee, l.Balance(acc.Address))
+
+	// the mock doesn't return transactions, so the submitted one is filled in
+	m := CreateAlgorandClientMock("", "")
+	appId, err = m.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+	info, err = StoreGlobalsResult(WithFeeStrategy(m, FeeStrategy{Mode: FeeFlat, Flat: 2000}), acc, appId, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2000, info.Transaction.Txn.Fee)
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// MaxGroupSize is the maximum number of transactions in an atomic group.
+const MaxGroupSize = 16
+
+// StoreGlobalsGroup stores the given batches of key-value pairs in an atomic group,
+// with one transaction per batch. Either all batches are stored, or none. Returns the
+// info responses of the confirmed transactions, in order of the batches.
+func StoreGlobalsGroup(a AlgorandClient, acc crypto.Account, appId uint64, batches [][]models.TealKeyValue) ([]models.PendingTransactionInfoResponse, error) {
+	if len(batches) == 0 || len(batches) > MaxGroupSize {
+		return nil, fmt.Errorf("group must contain between 1 and %d transactions, got %d", MaxGroupSize, len(batches))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
+	params, err := a.SuggestedParams(ctx)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("error getting suggested tx params: %w", err)
+	}
+	txns := make([]types.Transaction, len(batches))
+	for i, tkv := range batches {
+		txns[i], err = future.MakeApplicationNoOpTx(appId, kvArguments(tkv),
+			nil, nil, nil, params, acc.Address, []byte("put"), types.Digest{}, [32]byte{}, types.Address{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), timeoutsOf(a).store())
+	defer cancel()
+	return a.ExecuteGroup(acc, txns, ctx)
+}
+
+// assignGroup returns copies of the transactions with the ID of their group set.
+func assignGroup(txns []types.Transaction) ([]types.Transaction, error) {
+	if len(txns) == 0 || len(txns) > MaxGroupSize {
+		return nil, fmt.Errorf("group must contain between 1 and %d transactions, got %d", MaxGroupSize, len(txns))
+	}
+	grouped := make([]types.Transaction, len(txns))
+	copy(grouped, txns)
+	for i := range grouped {
+		grouped[i].Group = types.Digest{}
+	}
+	gid, err := crypto.ComputeGroupID(grouped)
+	if err != nil {
+		return nil, err
+	}
+	for i := range grouped {
+		grouped[i].Group = gid
+	}
+	return grouped, nil
+}
+
+// executeGroup assigns the group ID, signs the transactions with signer, submits them
+// together and waits for their confirmation.
+func executeGroup(c AlgorandClient, signer AccountSigner, txns []types.Transaction, ctx context.Context) ([]models.PendingTransactionInfoResponse, error) {
+	txns, err := assignGroup(txns)
+	if err != nil {
+		return nil, err
+	}
+	signed := make([]byte, 0)
+	for _, txn := range txns {
+		b, err := signer.SignTransaction(txn)
+		if err != nil {
+			return nil, err
+		}
+		signed = append(signed, b...)
+	}
+	if _, err = c.SendRawTransaction(signed, ctx); err != nil {
+		return nil, err
+	}
+	// all transactions of a group are confirmed in the same round
+	infos := make([]models.PendingTransactionInfoResponse, len(txns))
+	for i, txn := range txns {
+		infos[i], _, err = waitForConfirmation(c, crypto.GetTxID(txn), defaultWaitRounds, nil, ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
@@ -0,0 +1,61 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeLedger_Group(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	id, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+
+	batches := [][]models.TealKeyValue{
+		{{Key: "a", Value: models.TealValue{Bytes: "1"}}},
+		{{Key: "b", Value: models.TealValue{Bytes: "2"}}},
+	}
+	before, _ := l.Status(context.Background())
+	infos, err := StoreGlobalsGroup(l, acc, id, batches)
+	assert.Nil(t, err)
+	assert.Len(t, infos, 2)
+	// the group is confirmed in a single round
+	assert.Equal(t, before.LastRound+1, infos[0].ConfirmedRound)
+	assert.Equal(t, infos[0].ConfirmedRound, infos[1].ConfirmedRound)
+	assert.Equal(t, infos[0].Transaction.Txn.Group, infos[1].Transaction.Txn.Group)
+	state, err := ReadGlobalState(l, id, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, state)
+}
+
+// Transactions of an incomplete group are rejected.
+func TestFakeLedger_IncompleteGroup(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	params, err := l.SuggestedParams(context.Background())
+	assert.Nil(t, err)
+	txns := make([]types.Transaction, 2)
+	for i := range txns {
+		txns[i], err = future.MakePaymentTxn(acc.Address.String(), acc.Address.String(), uint64(i), nil, "", params)
+		assert.Nil(t, err)
+	}
+	txns, err = assignGroup(txns)
+	assert.Nil(t, err)
+	signed, err := NewKeySigner(acc).SignTransaction(txns[0])
+	assert.Nil(t, err)
+	_, err = l.SendRawTransaction(signed, context.Background())
+	assert.NotNil(t, err)
+
+	_, err = assignGroup(make([]types.Transaction, MaxGroupSize+1))
+	assert.NotNil(t, err)
+}
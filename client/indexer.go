@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common"
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+)
+
+// IndexerClient queries the history of the network from an indexer. Unlike algod,
+// which only keeps the current state, the indexer also knows deleted applications.
+type IndexerClient interface {
+	// LookupApplicationByID returns the application with the given ID, even if it has
+	// been deleted.
+	LookupApplicationByID(appId uint64, ctx context.Context) (models.Application, error)
+
+	// SearchForApplications returns all applications the given account ever created,
+	// including deleted ones.
+	SearchForApplications(creator string, ctx context.Context) ([]models.Application, error)
+
+	// LookupAccountAppLocalStates returns the local states of the given account,
+	// including those of applications it closed out of.
+	LookupAccountAppLocalStates(address string, ctx context.Context) ([]models.ApplicationLocalState, error)
+
+	// LookupApplicationCreation returns the transaction that created the application
+	// with the given ID.
+	LookupApplicationCreation(appId uint64, ctx context.Context) (models.Transaction, error)
+}
+
+// IndexerClientWrapper implements the IndexerClient interface by wrapping the original
+// indexer.Client
+type IndexerClientWrapper struct {
+	Client *indexer.Client
+}
+
+func CreateIndexerClientWrapper(URL string, token string) (*IndexerClientWrapper, error) {
+	c, err := indexer.MakeClient(URL, token)
+	return &IndexerClientWrapper{Client: c}, err
+}
+
+// NewIndexerClientWithHeaders creates an indexer client with a given set of headers,
+// like NewClientWithHeaders.
+func NewIndexerClientWithHeaders(URL string, token string, headers []*common.Header) (*IndexerClientWrapper, error) {
+	c, err := indexer.MakeClientWithHeaders(URL, token, headers)
+	return &IndexerClientWrapper{Client: c}, err
+}
+
+// NewIndexerClientWithAuthHeader creates an indexer client that sends the token in the
+// header with the given name, like NewClientWithAuthHeader. If header is empty, the
+// X-Indexer-API-Token header is used.
+func NewIndexerClientWithAuthHeader(URL string, header string, token string) (*IndexerClientWrapper, error) {
+	if header == "" {
+		header = "X-Indexer-API-Token"
+	}
+	c, err := common.MakeClient(URL, header, token)
+	return &IndexerClientWrapper{Client: (*indexer.Client)(c)}, err
+}
+
+func (i *IndexerClientWrapper) LookupApplicationByID(appId uint64, ctx context.Context) (models.Application, error) {
+	response, err := i.Client.LookupApplicationByID(appId).IncludeAll(true).Do(ctx)
+	return response.Application, err
+}
+
+func (i *IndexerClientWrapper) SearchForApplications(creator string, ctx context.Context) ([]models.Application, error) {
+	// the indexer can't filter applications by creator, but it lists the created apps of
+	// the account
+	_, account, err := i.Client.LookupAccountByID(creator).IncludeAll(true).Do(ctx)
+	return account.CreatedApps, err
+}
+
+func (i *IndexerClientWrapper) LookupAccountAppLocalStates(address string, ctx context.Context) ([]models.ApplicationLocalState, error) {
+	_, account, err := i.Client.LookupAccountByID(address).IncludeAll(true).Do(ctx)
+	return account.AppsLocalState, err
+}
+
+func (i *IndexerClientWrapper) LookupApplicationCreation(appId uint64, ctx context.Context) (models.Transaction, error) {
+	// transactions are sorted by round, so the creation is usually on the first page
+	search := i.Client.SearchForTransactions().ApplicationId(appId).TxType("appl")
+	for {
+		response, err := search.Do(ctx)
+		if err != nil {
+			return models.Transaction{}, err
+		}
+		for _, txn := range response.Transactions {
+			if txn.CreatedApplicationIndex == appId {
+				return txn, nil
+			}
+		}
+		if response.NextToken == "" || len(response.Transactions) == 0 {
+			return models.Transaction{}, fmt.Errorf("no creation transaction found for application %d", appId)
+		}
+		search.NextToken(response.NextToken)
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// IndexerMock implements the IndexerClient interface. All functions are simply
+// returning the corresponding public field.
+type IndexerMock struct {
+	AlwaysReturnError bool // When true, returns errors for every request
+	Apps              []models.Application
+	LocalStates       map[string][]models.ApplicationLocalState
+	Transactions      []models.Transaction
+}
+
+func (m *IndexerMock) LookupApplicationByID(appId uint64, _ context.Context) (models.Application, error) {
+	if m.AlwaysReturnError {
+		return models.Application{}, errors.New("error generated by a stub")
+	}
+	for _, app := range m.Apps {
+		if app.Id == appId {
+			return app, nil
+		}
+	}
+	return models.Application{}, errors.New("no application found for application-id")
+}
+
+func (m *IndexerMock) SearchForApplications(creator string, _ context.Context) ([]models.Application, error) {
+	if m.AlwaysReturnError {
+		return nil, errors.New("error generated by a stub")
+	}
+	apps := make([]models.Application, 0)
+	for _, app := range m.Apps {
+		if app.Params.Creator == creator {
+			apps = append(apps, app)
+		}
+	}
+	return apps, nil
+}
+
+func (m *IndexerMock) LookupAccountAppLocalStates(address string, _ context.Context) ([]models.ApplicationLocalState, error) {
+	if m.AlwaysReturnError {
+		return nil, errors.New("error generated by a stub")
+	}
+	return m.LocalStates[address], nil
+}
+
+func (m *IndexerMock) LookupApplicationCreation(appId uint64, _ context.Context) (models.Transaction, error) {
+	if m.AlwaysReturnError {
+		return models.Transaction{}, errors.New("error generated by a stub")
+	}
+	for _, txn := range m.Transactions {
+		if txn.CreatedApplicationIndex == appId {
+			return txn, nil
+		}
+	}
+	return models.Transaction{}, errors.New("no creation transaction found")
+}
@@ -0,0 +1,84 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// historicIndexer serves an account that created app 6 (deleted) and app 7, and an
+// app lookup of app 6.
+func historicIndexer(t *testing.T) *IndexerClientWrapper {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("include-all"))
+		switch r.URL.Path {
+		case "/v2/accounts/ABC":
+			_, _ = w.Write([]byte(`{"current-round":10,"account":{"address":"ABC",` +
+				`"created-apps":[{"id":6,"deleted":true},{"id":7}],"apps-local-state":[{"id":8}]}}`))
+		case "/v2/applications/6":
+			_, _ = w.Write([]byte(`{"current-round":10,"application":{"id":6,"deleted":true,"created-at-round":3}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"no application found for application-id"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateIndexerClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+	return c
+}
+
+func TestIndexerClientWrapper(t *testing.T) {
+	c := historicIndexer(t)
+	ctx := context.Background()
+
+	apps, err := c.SearchForApplications("ABC", ctx)
+	assert.Nil(t, err)
+	assert.Len(t, apps, 2)
+	assert.True(t, apps[0].Deleted)
+
+	app, err := c.LookupApplicationByID(6, ctx)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, app.CreatedAtRound)
+	_, err = c.LookupApplicationByID(9, ctx)
+	assert.NotNil(t, err)
+
+	local, err := c.LookupAccountAppLocalStates("ABC", ctx)
+	assert.Nil(t, err)
+	assert.Len(t, local, 1)
+	assert.EqualValues(t, 8, local[0].Id)
+}
+
+// The creation transaction is found on a later page of the search.
+func TestIndexerClientWrapper_LookupApplicationCreation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "/v2/transactions", r.URL.Path)
+		assert.Equal(t, "appl", q.Get("tx-type"))
+		if q.Get("application-id") != "6" {
+			_, _ = w.Write([]byte(`{"current-round":10,"transactions":[]}`))
+			return
+		}
+		switch q.Get("next") {
+		case "":
+			_, _ = w.Write([]byte(`{"current-round":10,"next-token":"page2","transactions":[{"id":"CALL"}]}`))
+		case "page2":
+			_, _ = w.Write([]byte(`{"current-round":10,"transactions":[{"id":"CREATE","created-application-index":6}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateIndexerClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+
+	txn, err := c.LookupApplicationCreation(6, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "CREATE", txn.Id)
+
+	_, err = c.LookupApplicationCreation(7, context.Background())
+	assert.NotNil(t, err)
+}
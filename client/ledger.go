@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// APDU constants of the Algorand Ledger app
+const (
+	ledgerCla            = 0x80
+	ledgerInsGetPubKey   = 0x03
+	ledgerInsSignMsgpack = 0x08
+	ledgerP1First        = 0x01
+	ledgerP1More         = 0x80
+	ledgerP2More         = 0x80
+	ledgerP2Last         = 0x00
+	ledgerChunkSize      = 250
+	ledgerStatusOK       = 0x9000
+	ledgerStatusDenied   = 0x6985
+)
+
+// ErrLedgerRejected is returned if the user declined a transaction on the device.
+var ErrLedgerRejected = errors.New("transaction rejected on ledger device")
+
+// LedgerTransport exchanges APDU messages with a Ledger device. The response
+// includes the two byte status word at the end. A USB HID transport is available
+// with the `ledger` build tag (see OpenLedgerHID).
+type LedgerTransport interface {
+	Exchange(apdu []byte) ([]byte, error)
+}
+
+// LedgerSigner implements AccountSigner by signing transactions with the Algorand
+// app of a Ledger hardware wallet. Every transaction has to be confirmed by the
+// user on the device.
+type LedgerSigner struct {
+	Transport LedgerTransport
+
+	// AccountIndex is the index of the account on the device (BIP-44 derivation).
+	AccountIndex uint32
+
+	address types.Address
+}
+
+// NewLedgerSigner creates a LedgerSigner for the account with the given index. The
+// public key of the account is requested from the device.
+func NewLedgerSigner(t LedgerTransport, accountIndex uint32) (*LedgerSigner, error) {
+	s := &LedgerSigner{Transport: t, AccountIndex: accountIndex}
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, accountIndex)
+	resp, err := s.exchange(ledgerInsGetPubKey, 0, 0, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < len(s.address) {
+		return nil, fmt.Errorf("ledger returned public key of invalid length %d", len(resp))
+	}
+	copy(s.address[:], resp)
+	return s, nil
+}
+
+func (s *LedgerSigner) Address() types.Address {
+	return s.address
+}
+
+// SignTransaction sends the msgpack-encoded transaction in chunks to the device and
+// waits for the user to confirm it. Returns ErrLedgerRejected if the user declines.
+func (s *LedgerSigner) SignTransaction(txn types.Transaction) ([]byte, error) {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, s.AccountIndex)
+	payload = append(payload, msgpack.Encode(txn)...)
+
+	var resp []byte
+	p1 := byte(ledgerP1First)
+	for len(payload) > 0 {
+		n := len(payload)
+		if n > ledgerChunkSize {
+			n = ledgerChunkSize
+		}
+		p2 := byte(ledgerP2Last)
+		if n < len(payload) {
+			p2 = ledgerP2More
+		}
+		var err error
+		resp, err = s.exchange(ledgerInsSignMsgpack, p1, p2, payload[:n])
+		if err != nil {
+			return nil, err
+		}
+		payload = payload[n:]
+		p1 = ledgerP1More
+	}
+
+	var sig types.Signature
+	if len(resp) < len(sig) {
+		return nil, fmt.Errorf("ledger returned signature of invalid length %d", len(resp))
+	}
+	copy(sig[:], resp)
+	return encodeSignedTxn(txn, sig, s.address), nil
+}
+
+// exchange sends a single APDU command and checks the returned status word. The
+// response is returned without status word.
+func (s *LedgerSigner) exchange(ins, p1, p2 byte, data []byte) ([]byte, error) {
+	apdu := append([]byte{ledgerCla, ins, p1, p2, byte(len(data))}, data...)
+	resp, err := s.Transport.Exchange(apdu)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 2 {
+		return nil, errors.New("ledger response is missing status word")
+	}
+	status := binary.BigEndian.Uint16(resp[len(resp)-2:])
+	switch status {
+	case ledgerStatusOK:
+		return resp[:len(resp)-2], nil
+	case ledgerStatusDenied:
+		return nil, ErrLedgerRejected
+	default:
+		return nil, fmt.Errorf("ledger returned status 0x%04x", status)
+	}
+}
@@ -0,0 +1,79 @@
+//go:build ledger && linux
+
+package client
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+)
+
+const (
+	hidPacketSize = 64
+	hidChannel    = 0x0101
+	hidTagAPDU    = 0x05
+)
+
+// HIDTransport implements LedgerTransport over a Linux hidraw device
+// (e.g. /dev/hidraw0) of a connected Ledger.
+type HIDTransport struct {
+	device *os.File
+}
+
+// OpenLedgerHID opens the hidraw device at the given path.
+func OpenLedgerHID(path string) (*HIDTransport, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &HIDTransport{device: f}, nil
+}
+
+// Close closes the underlying device.
+func (h *HIDTransport) Close() error {
+	return h.device.Close()
+}
+
+// Exchange frames the APDU into HID packets, writes them to the device and reads
+// the framed response.
+func (h *HIDTransport) Exchange(apdu []byte) ([]byte, error) {
+	// first packet carries the length of the APDU
+	data := make([]byte, 2, len(apdu)+2)
+	binary.BigEndian.PutUint16(data, uint16(len(apdu)))
+	data = append(data, apdu...)
+
+	for seq := 0; len(data) > 0; seq++ {
+		// hidraw expects the report ID as first byte
+		packet := make([]byte, hidPacketSize+1)
+		binary.BigEndian.PutUint16(packet[1:], hidChannel)
+		packet[3] = hidTagAPDU
+		binary.BigEndian.PutUint16(packet[4:], uint16(seq))
+		n := copy(packet[6:], data)
+		data = data[n:]
+		if _, err := h.device.Write(packet); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp []byte
+	length := -1
+	for seq := 0; length < 0 || len(resp) < length; seq++ {
+		packet := make([]byte, hidPacketSize)
+		if _, err := h.device.Read(packet); err != nil {
+			return nil, err
+		}
+		if binary.BigEndian.Uint16(packet) != hidChannel || packet[2] != hidTagAPDU {
+			return nil, errors.New("unexpected hid packet from ledger")
+		}
+		if int(binary.BigEndian.Uint16(packet[3:])) != seq {
+			return nil, errors.New("unexpected hid packet sequence from ledger")
+		}
+		payload := packet[5:]
+		if seq == 0 {
+			length = int(binary.BigEndian.Uint16(payload))
+			payload = payload[2:]
+		}
+		resp = append(resp, payload...)
+	}
+	return resp[:length], nil
+}
@@ -0,0 +1,100 @@
+//go:build unit
+
+package client
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ AccountSigner = (*LedgerSigner)(nil)
+var _ AccountSigner = (*KeySigner)(nil)
+
+// ledgerDeviceMock emulates the Algorand app of a Ledger device with a software key
+type ledgerDeviceMock struct {
+	account crypto.Account
+	reject  bool
+	buffer  []byte
+	apdus   [][]byte
+}
+
+func (l *ledgerDeviceMock) Exchange(apdu []byte) ([]byte, error) {
+	l.apdus = append(l.apdus, apdu)
+	ok := []byte{0x90, 0x00}
+	switch apdu[1] {
+	case ledgerInsGetPubKey:
+		return append(append([]byte{}, l.account.PublicKey...), ok...), nil
+	case ledgerInsSignMsgpack:
+		data := apdu[5:]
+		if apdu[2] == ledgerP1First {
+			l.buffer = nil
+			data = data[4:]
+		}
+		l.buffer = append(l.buffer, data...)
+		if apdu[3] == ledgerP2More {
+			return ok, nil
+		}
+		if l.reject {
+			return []byte{0x69, 0x85}, nil
+		}
+		sig := ed25519.Sign(l.account.PrivateKey, append([]byte("TX"), l.buffer...))
+		return append(sig, ok...), nil
+	}
+	return []byte{0x6d, 0x00}, nil
+}
+
+func ledgerTestTxn(acc crypto.Account, note []byte) types.Transaction {
+	p := types.SuggestedParams{Fee: 1000, FlatFee: true, FirstRoundValid: 1, LastRoundValid: 1000,
+		GenesisHash: make([]byte, 32)}
+	txn, _ := GenerateApplicationCallTx(5, acc, p, types.NoOpOC)
+	txn.Note = note
+	return txn
+}
+
+func TestLedgerSigner_SignTransaction(t *testing.T) {
+	device := &ledgerDeviceMock{account: crypto.GenerateAccount()}
+	signer, err := NewLedgerSigner(device, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, device.account.Address, signer.Address())
+
+	// large note forces the transaction to be split into several APDUs
+	txn := ledgerTestTxn(device.account, make([]byte, 600))
+	b, err := signer.SignTransaction(txn)
+	assert.Nil(t, err)
+	assert.Greater(t, len(device.apdus), 3)
+
+	// signature must equal the one of a software signer
+	expected, err := NewKeySigner(device.account).SignTransaction(txn)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, b)
+
+	var stx types.SignedTxn
+	assert.Nil(t, msgpack.Decode(b, &stx))
+	assert.Equal(t, types.Address{}, stx.AuthAddr)
+}
+
+func TestLedgerSigner_Rejected(t *testing.T) {
+	device := &ledgerDeviceMock{account: crypto.GenerateAccount(), reject: true}
+	signer, err := NewLedgerSigner(device, 0)
+	assert.Nil(t, err)
+	_, err = signer.SignTransaction(ledgerTestTxn(device.account, nil))
+	assert.ErrorIs(t, err, ErrLedgerRejected)
+}
+
+// If the ledger key differs from the sender, the ledger address is the auth address
+func TestLedgerSigner_AuthAddr(t *testing.T) {
+	device := &ledgerDeviceMock{account: crypto.GenerateAccount()}
+	signer, _ := NewLedgerSigner(device, 0)
+	sender := crypto.GenerateAccount()
+	b, err := signer.SignTransaction(ledgerTestTxn(sender, nil))
+	assert.Nil(t, err)
+
+	var stx types.SignedTxn
+	assert.Nil(t, msgpack.Decode(b, &stx))
+	assert.Equal(t, device.account.Address, stx.AuthAddr)
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// CloseOutApplication removes the local state of the account in the application with
+// a close-out call. The approval program is evaluated, and may reject the call, in
+// which case the local state is kept.
+func CloseOutApplication(a AlgorandClient, acc crypto.Account, appId uint64, ctx context.Context) error {
+	return leaveApplication(a, acc, appId, types.CloseOutOC, ctx)
+}
+
+// ClearApplicationState removes the local state of the account in the application with
+// a clear-state call. Per protocol, the local state is removed even if the clear
+// program fails, so this always succeeds for opted-in accounts.
+func ClearApplicationState(a AlgorandClient, acc crypto.Account, appId uint64, ctx context.Context) error {
+	return leaveApplication(a, acc, appId, types.ClearStateOC, ctx)
+}
+
+func leaveApplication(a AlgorandClient, acc crypto.Account, appId uint64, oc types.OnCompletion, ctx context.Context) error {
+	params, err := a.SuggestedParams(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting suggested tx params: %w", err)
+	}
+	var txn types.Transaction
+	if oc == types.CloseOutOC {
+		txn, err = future.MakeApplicationCloseOutTx(appId, nil, nil, nil, nil,
+			params, acc.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
+	} else {
+		txn, err = future.MakeApplicationClearStateTx(appId, nil, nil, nil, nil,
+			params, acc.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
+	}
+	if err != nil {
+		return err
+	}
+	_, err = a.ExecuteTransaction(acc, txn, ctx)
+	return err
+}
@@ -0,0 +1,46 @@
+package client
+
+// Logger receives the log messages of clients and buffers. Adapt it to the logging
+// library of your service (e.g. zap's SugaredLogger implements it).
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// NopLogger discards all messages. It's the default Logger.
+type NopLogger struct{}
+
+func (NopLogger) Debugf(string, ...interface{}) {}
+func (NopLogger) Infof(string, ...interface{})  {}
+func (NopLogger) Warnf(string, ...interface{})  {}
+func (NopLogger) Errorf(string, ...interface{}) {}
+
+// loggingClient is implemented by clients that have a Logger.
+type loggingClient interface {
+	logger() Logger
+}
+
+// loggerOf returns the Logger of the client, or a NopLogger if it doesn't have one.
+func loggerOf(a AlgorandClient) Logger {
+	if l, ok := a.(loggingClient); ok {
+		return l.logger()
+	}
+	return NopLogger{}
+}
+
+func (a *AlgorandClientWrapper) logger() Logger {
+	if a.Logger == nil {
+		return NopLogger{}
+	}
+	return a.Logger
+}
+
+func (h *hookedClient) logger() Logger {
+	return loggerOf(h.AlgorandClient)
+}
+
+func (t *timedClient) logger() Logger {
+	return loggerOf(t.AlgorandClient)
+}
@@ -0,0 +1,57 @@
+//go:build unit
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLogger records the messages logged at each level.
+type recordingLogger struct {
+	messages map[string][]string
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{messages: make(map[string][]string)}
+}
+
+func (l *recordingLogger) log(level string, format string, args ...interface{}) {
+	l.messages[level] = append(l.messages[level], fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) { l.log("debug", format, args...) }
+func (l *recordingLogger) Infof(format string, args ...interface{})  { l.log("info", format, args...) }
+func (l *recordingLogger) Warnf(format string, args ...interface{})  { l.log("warn", format, args...) }
+func (l *recordingLogger) Errorf(format string, args ...interface{}) { l.log("error", format, args...) }
+
+func TestCompileProgram_LogsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"1 error"}`))
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateAlgorandClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+	logger := newRecordingLogger()
+	c.Logger = logger
+
+	assert.Nil(t, CompileProgram(c, []byte("#pragma version 5")))
+	assert.Len(t, logger.messages["error"], 1)
+	assert.Contains(t, logger.messages["error"][0], "issue with compile")
+
+	// wrapped clients log to the logger of the wrapped client
+	assert.Nil(t, CompileProgram(WithTimeouts(WithSubmitHook(c, nil), Timeouts{}), []byte("#pragma version 5")))
+	assert.Len(t, logger.messages["error"], 2)
+}
+
+func TestCompileProgram_NoLogger(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.SetError(true, (*AlgorandMock).TealCompile)
+	assert.Nil(t, CompileProgram(m, []byte("#pragma version 5")))
+	assert.Equal(t, NopLogger{}, loggerOf(m))
+}
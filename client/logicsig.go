@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// ErrInvalidLogicSig is returned by ExecuteLogicSigTransaction, if the transaction
+// can't be signed with the logic signature, e.g. because its program is malformed, or
+// its delegation signature is invalid. The transaction wasn't submitted.
+var ErrInvalidLogicSig = errors.New("invalid logic signature")
+
+// ErrLogicSigRejected is returned by ExecuteLogicSigTransaction, if the node evaluated
+// the program of the logic signature, and the program rejected the transaction.
+var ErrLogicSigRejected = errors.New("rejected by logic")
+
+// ExecuteLogicSigTransaction executes the transaction like ExecuteTransaction, but
+// signs it with the given logic signature (delegated or escrow) instead of a private
+// key. It waits for the confirmation the same way. Errors of the logic signature wrap
+// ErrInvalidLogicSig or ErrLogicSigRejected, so they can be told apart from other errors
+// of the node with errors.Is.
+func (a *AlgorandClientWrapper) ExecuteLogicSigTransaction(lsig crypto.LogicSigAccount, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	_, signedTxn, err := crypto.SignLogicSigAccountTransaction(lsig, txn)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, fmt.Errorf("%w: %s", ErrInvalidLogicSig, err)
+	}
+	info, err := a.submitAndConfirm(signedTxn, ctx)
+	return info, logicSigRejectedError(err)
+}
+
+// logicSigRejectedError turns errors of the node about transactions that a logic
+// signature rejected into errors that wrap ErrLogicSigRejected.
+func logicSigRejectedError(err error) error {
+	if err != nil && strings.Contains(err.Error(), ErrLogicSigRejected.Error()) {
+		return fmt.Errorf("%w: %s", ErrLogicSigRejected, err)
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// lsigNode confirms submitted transactions, or rejects them with the given message.
+// Submitted transactions are recorded in submitted.
+func lsigNode(t *testing.T, reject string, submitted *[]types.SignedTxn) *AlgorandClientWrapper {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/transactions":
+			if reject != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"message":"` + reject + `"}`))
+				return
+			}
+			b, _ := io.ReadAll(r.Body)
+			var stx types.SignedTxn
+			assert.Nil(t, msgpack.Decode(b, &stx))
+			*submitted = append(*submitted, stx)
+			_, _ = w.Write([]byte(`{"txId":"ABC"}`))
+		case r.URL.Path == "/v2/status":
+			_, _ = w.Write([]byte(`{"last-round":10}`))
+		case strings.HasPrefix(r.URL.Path, "/v2/transactions/pending/"):
+			_, _ = w.Write(msgpack.Encode(models.PendingTransactionInfoResponse{ConfirmedRound: 11}))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateAlgorandClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+	return c
+}
+
+func lsigTxn(t *testing.T, sender types.Address) types.Transaction {
+	params := types.SuggestedParams{Fee: MinTxnFee, FlatFee: true, FirstRoundValid: 10, LastRoundValid: 20}
+	txn, err := future.MakeApplicationNoOpTx(6, nil, nil, nil, nil, params, sender, nil,
+		types.Digest{}, [32]byte{}, types.Address{})
+	assert.Nil(t, err)
+	return txn
+}
+
+func TestAlgorandClientWrapper_ExecuteLogicSigTransaction(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	// "#pragma version 4; pushint 1"
+	lsa, err := crypto.MakeLogicSigAccountDelegated([]byte{0x04, 0x81, 0x01}, nil, acc.PrivateKey)
+	assert.Nil(t, err)
+	var submitted []types.SignedTxn
+	c := lsigNode(t, "", &submitted)
+
+	info, err := c.ExecuteLogicSigTransaction(lsa, lsigTxn(t, acc.Address), context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 11, info.ConfirmedRound)
+	assert.Len(t, submitted, 1)
+	assert.Equal(t, lsa.Lsig.Logic, submitted[0].Lsig.Logic)
+	assert.Equal(t, types.Signature{}, submitted[0].Sig)
+}
+
+// Invalid logic signatures aren't submitted
+func TestAlgorandClientWrapper_ExecuteLogicSigTransaction_Invalid(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	lsa, err := crypto.MakeLogicSigAccountDelegated([]byte{0x04, 0x81, 0x01}, nil, acc.PrivateKey)
+	assert.Nil(t, err)
+	lsa.Lsig.Sig[0] ^= 0xff
+	var submitted []types.SignedTxn
+	c := lsigNode(t, "", &submitted)
+
+	_, err = c.ExecuteLogicSigTransaction(lsa, lsigTxn(t, acc.Address), context.Background())
+	assert.ErrorIs(t, err, ErrInvalidLogicSig)
+	assert.NotErrorIs(t, err, ErrLogicSigRejected)
+	assert.Empty(t, submitted)
+}
+
+func TestAlgorandClientWrapper_ExecuteLogicSigTransaction_Rejected(t *testing.T) {
+	lsa := crypto.MakeLogicSigAccountEscrow([]byte{0x04, 0x81, 0x00}, nil)
+	addr, err := lsa.Address()
+	assert.Nil(t, err)
+	c := lsigNode(t, "TransactionPool.Remember: transaction ABC: rejected by logic", nil)
+
+	_, err = c.ExecuteLogicSigTransaction(lsa, lsigTxn(t, addr), context.Background())
+	assert.ErrorIs(t, err, ErrLogicSigRejected)
+	assert.NotErrorIs(t, err, ErrInvalidLogicSig)
+
+	// other errors of the node aren't classified as ErrLogicSigRejected
+	assert.Nil(t, logicSigRejectedError(nil))
+	assert.NotErrorIs(t, logicSigRejectedError(assert.AnError), ErrLogicSigRejected)
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// RecordedCall is a call of an AlgorandMock method, as returned by Calls.
+type RecordedCall struct {
+	// Method is the name of the method, like "DeleteApplication"
+	Method string
+
+	// Args holds the arguments of the call, except for contexts
+	Args []interface{}
+
+	Time time.Time
+}
+
+// record appends a call of the method f to the calls of the mock.
+func (a *AlgorandMock) record(f interface{}, args ...interface{}) {
+	call := RecordedCall{Method: methodName(f), Args: args, Time: time.Now()}
+	a.callsMu.Lock()
+	a.calls = append(a.calls, call)
+	a.callsMu.Unlock()
+}
+
+// methodName returns the short name of the AlgorandMock method f.
+func methodName(f interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// Calls returns every call of the mock, in order. Calls that the mock makes itself,
+// like DeleteApplication for an ExecuteTransaction that deletes an app, are included.
+func (a *AlgorandMock) Calls() []RecordedCall {
+	a.callsMu.Lock()
+	defer a.callsMu.Unlock()
+	return append([]RecordedCall(nil), a.calls...)
+}
+
+// CallsOf returns the calls of the method f, like (*AlgorandMock).DeleteApplication,
+// in order.
+func (a *AlgorandMock) CallsOf(f interface{}) []RecordedCall {
+	name := methodName(f)
+	calls := make([]RecordedCall, 0)
+	for _, call := range a.Calls() {
+		if call.Method == name {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// CallCount returns the number of calls of the method f.
+func (a *AlgorandMock) CallCount(f interface{}) int {
+	return len(a.CallsOf(f))
+}
+
+// LastCall returns the last call of the method f. It returns false if f hasn't been
+// called.
+func (a *AlgorandMock) LastCall(f interface{}) (RecordedCall, bool) {
+	calls := a.CallsOf(f)
+	if len(calls) == 0 {
+		return RecordedCall{}, false
+	}
+	return calls[len(calls)-1], true
+}
+
+// ClearCalls drops the recorded calls.
+func (a *AlgorandMock) ClearCalls() {
+	a.callsMu.Lock()
+	a.calls = nil
+	a.callsMu.Unlock()
+}
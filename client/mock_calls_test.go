@@ -0,0 +1,55 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandMock_Calls(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.CreateDummyApps(6, 18)
+	account := crypto.GenerateAccount()
+
+	_, _ = m.Status(context.Background())
+	_ = m.DeleteApplication(account, 6)
+	_, _ = m.StatusAfterBlock(3, context.Background())
+	_ = m.DeleteApplication(account, 18)
+
+	calls := m.Calls()
+	assert.Len(t, calls, 4)
+	assert.Equal(t, "Status", calls[0].Method)
+	assert.Empty(t, calls[0].Args)
+	assert.Equal(t, []interface{}{uint64(3)}, calls[2].Args)
+	assert.False(t, calls[3].Time.Before(calls[0].Time))
+
+	assert.Equal(t, 2, m.CallCount((*AlgorandMock).DeleteApplication))
+	assert.Equal(t, 0, m.CallCount((*AlgorandMock).HealthCheck))
+	last, ok := m.LastCall((*AlgorandMock).DeleteApplication)
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{account, uint64(18)}, last.Args)
+	_, ok = m.LastCall((*AlgorandMock).HealthCheck)
+	assert.False(t, ok)
+
+	m.ClearCalls()
+	assert.Empty(t, m.Calls())
+}
+
+// Calls that the mock makes itself are recorded, and arguments aren't changed by
+// the call.
+func TestAlgorandMock_CallsNested(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.CreateDummyApps(6)
+	m.App = m.Account.CreatedApps[0]
+	account := crypto.GenerateAccount()
+
+	_, err := deleteGlobals(m, account, 6, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, m.CallCount((*AlgorandMock).ExecuteTransaction))
+	last, _ := m.LastCall((*AlgorandMock).DeleteGlobals)
+	assert.Equal(t, []string{"a"}, last.Args[2])
+}
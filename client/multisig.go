@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// ErrMultisigThreshold is returned if a multisig transaction is submitted with fewer
+// signatures than the threshold of its multisig account.
+var ErrMultisigThreshold = errors.New("not enough multisig signatures")
+
+// MultisigSigner collects the signatures of a multisig account for a transaction, e.g.
+// from several governance members that sign one after another. Once the threshold of
+// the account is met, the assembled transaction can be submitted with SubmitMultisig.
+// The sender of the transaction must be the multisig account, or an account rekeyed
+// to it.
+type MultisigSigner struct {
+	Account crypto.MultisigAccount
+	Txn     types.Transaction
+
+	// signed is the encoded transaction with the signatures collected so far
+	signed []byte
+}
+
+// NewMultisigSigner creates a MultisigSigner for the given transaction, without
+// signatures.
+func NewMultisigSigner(ma crypto.MultisigAccount, txn types.Transaction) (*MultisigSigner, error) {
+	if err := ma.Validate(); err != nil {
+		return nil, err
+	}
+	return &MultisigSigner{Account: ma, Txn: txn}, nil
+}
+
+// AppendSignature signs the transaction with the key of acc, which must be one of the
+// keys of the multisig account.
+func (s *MultisigSigner) AppendSignature(acc crypto.Account) error {
+	var signed []byte
+	var err error
+	if s.signed == nil {
+		_, signed, err = crypto.SignMultisigTransaction(acc.PrivateKey, s.Account, s.Txn)
+	} else {
+		_, signed, err = crypto.AppendMultisigTransaction(acc.PrivateKey, s.Account, s.signed)
+	}
+	if err != nil {
+		return fmt.Errorf("can't sign with %s: %w", acc.Address, err)
+	}
+	s.signed = signed
+	return nil
+}
+
+// Signatures returns the number of signatures collected so far.
+func (s *MultisigSigner) Signatures() int {
+	if s.signed == nil {
+		return 0
+	}
+	var stx types.SignedTxn
+	if err := msgpack.Decode(s.signed, &stx); err != nil {
+		return 0
+	}
+	n := 0
+	for _, subsig := range stx.Msig.Subsigs {
+		if subsig.Sig != (types.Signature{}) {
+			n++
+		}
+	}
+	return n
+}
+
+// SignedTransaction returns the encoded transaction with the collected signatures,
+// ready to be sent with SendRawTransaction. Returns ErrMultisigThreshold, if the
+// threshold of the multisig account isn't met yet.
+func (s *MultisigSigner) SignedTransaction() ([]byte, error) {
+	if n := s.Signatures(); n < int(s.Account.Threshold) {
+		return nil, fmt.Errorf("%w: got %d of %d", ErrMultisigThreshold, n, s.Account.Threshold)
+	}
+	return s.signed, nil
+}
+
+// SubmitMultisig submits the transaction of s, once the threshold of its multisig
+// account is met, and waits for its confirmation like ExecuteTransaction.
+func SubmitMultisig(c AlgorandClient, s *MultisigSigner, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	signedTxn, err := s.SignedTransaction()
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	return submitAndConfirm(c, signedTxn, ResubmitUnseenOnce, nil, ctx)
+}
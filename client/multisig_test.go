@@ -0,0 +1,46 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultisigSigner(t *testing.T) {
+	a, b := crypto.GenerateAccount(), crypto.GenerateAccount()
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{a.Address, b.Address})
+	assert.Nil(t, err)
+	addr, err := ma.Address()
+	assert.Nil(t, err)
+	l := NewFakeLedger()
+	l.Fund(addr, 10000000)
+	params, err := l.SuggestedParams(context.Background())
+	assert.Nil(t, err)
+	txn, err := future.MakePaymentTxn(addr.String(), a.Address.String(), 1000, nil, "", params)
+	assert.Nil(t, err)
+
+	s, err := NewMultisigSigner(ma, txn)
+	assert.Nil(t, err)
+	_, err = SubmitMultisig(l, s, context.Background())
+	assert.ErrorIs(t, err, ErrMultisigThreshold)
+	assert.Nil(t, s.AppendSignature(a))
+	_, err = s.SignedTransaction()
+	assert.ErrorIs(t, err, ErrMultisigThreshold)
+
+	assert.Nil(t, s.AppendSignature(b))
+	_, err = SubmitMultisig(l, s, context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1000, l.Balance(a.Address))
+}
+
+func TestNewMultisigSigner_InvalidAccount(t *testing.T) {
+	ma := crypto.MultisigAccount{Version: 1, Threshold: 2, Pks: nil}
+	_, err := NewMultisigSigner(ma, types.Transaction{})
+	assert.NotNil(t, err)
+}
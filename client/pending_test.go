@@ -0,0 +1,37 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandClientWrapper_PendingTransactionsByAddress(t *testing.T) {
+	addr := crypto.GenerateAccount().Address
+	pool := models.PendingTransactionsResponse{
+		TopTransactions:   []types.SignedTxn{{Txn: types.Transaction{Header: types.Header{Sender: addr, Note: []byte("put")}}}},
+		TotalTransactions: 1,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/accounts/"+addr.String()+"/transactions/pending", r.URL.Path)
+		assert.Equal(t, "5", r.URL.Query().Get("max"))
+		_, _ = w.Write(msgpack.Encode(pool))
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateAlgorandClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+
+	txns, err := c.PendingTransactionsByAddress(addr.String(), 5, context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, txns, 1)
+	assert.Equal(t, []byte("put"), txns[0].Txn.Note)
+}
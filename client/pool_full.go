@@ -0,0 +1,21 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPoolFull is returned by SendRawTransaction if the transaction pool of the node is
+// full. This happens under heavy congestion. The transaction wasn't accepted, so it's
+// safe to resubmit it later. Use errors.Is to check for it.
+var ErrPoolFull = errors.New("transaction pool is full")
+
+// poolFullError turns errors of the node about a full transaction pool into errors
+// that wrap ErrPoolFull.
+func poolFullError(err error) error {
+	if err != nil && strings.Contains(err.Error(), ErrPoolFull.Error()) {
+		return fmt.Errorf("%w: %s", ErrPoolFull, err)
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// congestedNode rejects the first n submitted transactions because its pool is full.
+func congestedNode(t *testing.T, n int) *AlgorandClientWrapper {
+	submits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		submits++
+		if submits <= n {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"TransactionPool.Remember: transaction pool is full"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"txId":"ABC"}`))
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateAlgorandClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+	return c
+}
+
+func TestAlgorandClientWrapper_PoolFull(t *testing.T) {
+	c := congestedNode(t, 1)
+	_, err := c.SendRawTransaction([]byte{0x80}, context.Background())
+	assert.ErrorIs(t, err, ErrPoolFull)
+	assert.Contains(t, err.Error(), "TransactionPool.Remember")
+
+	txID, err := c.SendRawTransaction([]byte{0x80}, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC", txID)
+}
+
+// Other rejections aren't classified as ErrPoolFull.
+func TestPoolFullError(t *testing.T) {
+	assert.Nil(t, poolFullError(nil))
+	assert.NotErrorIs(t, poolFullError(assert.AnError), ErrPoolFull)
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned if the node (or node provider) throttles requests with
+// HTTP 429 (Too Many Requests). Use errors.Is to check for it. The concrete error is
+// of type RateLimitError.
+var ErrRateLimited = errors.New("rate limited by node")
+
+// RateLimitError is returned for requests that were rejected with HTTP 429.
+type RateLimitError struct {
+	// RetryAfter is the duration the node asked to wait before retrying (the value of
+	// the Retry-After header). Zero, if unknown.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	msg := ErrRateLimited.Error()
+	if e.RetryAfter > 0 {
+		msg = fmt.Sprintf("%s (retry after %s)", msg, e.RetryAfter)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimitPolicy determines how an AlgorandClientWrapper retries requests that are
+// rejected with HTTP 429. Rate-limited requests haven't been processed by the node, so
+// it's safe to retry them.
+type RateLimitPolicy struct {
+	// MaxRetries is the number of retries of a single request before ErrRateLimited
+	// is returned.
+	MaxRetries int
+
+	// DefaultRetryAfter is the time to wait before retrying, if the response doesn't
+	// have a valid Retry-After header.
+	DefaultRetryAfter time.Duration
+}
+
+// rateLimitError turns errors of HTTP 429 responses into a RateLimitError. The
+// RetryAfter duration is parsed from the headers of the response, if known.
+func rateLimitError(err error, header http.Header) error {
+	if err != nil && strings.HasPrefix(err.Error(), "HTTP 429") {
+		return &RateLimitError{RetryAfter: parseRetryAfter(header.Get("Retry-After"), time.Now()), Err: err}
+	}
+	return err
+}
+
+// parseRetryAfter parses the value of a Retry-After header, given either in seconds or
+// as an HTTP date. Returns zero for empty or invalid values, and for dates before now.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+	return date.Sub(now)
+}
+
+// The go-algorand-sdk doesn't expose the headers of responses. It sends its requests
+// with http.DefaultTransport and the context of the call, so the headers of throttled
+// responses are captured by wrapping http.DefaultTransport. Only requests with a
+// capturedHeaders in their context are affected.
+var installCapture sync.Once
+
+// capturedHeaders holds the headers of the last HTTP 429 response of a request.
+type capturedHeaders struct {
+	mu     sync.Mutex
+	header http.Header
+}
+
+func (c *capturedHeaders) get() http.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.header
+}
+
+type capturedHeadersKey struct{}
+
+// headerCapture is the http.RoundTripper that records the headers of HTTP 429
+// responses in the capturedHeaders of the request context.
+type headerCapture struct {
+	base http.RoundTripper
+}
+
+func (t *headerCapture) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if c, ok := req.Context().Value(capturedHeadersKey{}).(*capturedHeaders); ok && err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		c.mu.Lock()
+		c.header = resp.Header.Clone()
+		c.mu.Unlock()
+	}
+	return resp, err
+}
+
+// withCapturedHeaders returns a context whose requests record the headers of HTTP 429
+// responses in the returned capturedHeaders.
+func withCapturedHeaders(ctx context.Context) (context.Context, *capturedHeaders) {
+	installCapture.Do(func() {
+		http.DefaultTransport = &headerCapture{base: http.DefaultTransport}
+	})
+	c := &capturedHeaders{}
+	return context.WithValue(ctx, capturedHeadersKey{}, c), c
+}
+
+// retryRateLimited calls f until it doesn't return ErrRateLimited, or until the
+// policy's retries are used up. Before each retry, it waits for the duration given by
+// the error, or the DefaultRetryAfter of the policy.
+func retryRateLimited(ctx context.Context, policy RateLimitPolicy, sleep func(context.Context, time.Duration) error, f func() error) error {
+	err := f()
+	for retry := 0; retry < policy.MaxRetries && errors.Is(err, ErrRateLimited); retry++ {
+		wait := policy.DefaultRetryAfter
+		var rateLimited *RateLimitError
+		if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+			wait = rateLimited.RetryAfter
+		}
+		if sleepErr := sleep(ctx, wait); sleepErr != nil {
+			return err
+		}
+		err = f()
+	}
+	return err
+}
+
+// sleepContext waits for the given duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,119 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// throttledNode responds with HTTP 429 to the first n requests.
+func throttledNode(t *testing.T, n int) (*AlgorandClientWrapper, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= n {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message":"too many requests"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"last-round":5}`))
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateAlgorandClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+	return c, &requests
+}
+
+func TestAlgorandClientWrapper_RateLimited(t *testing.T) {
+	c, requests := throttledNode(t, 1)
+	_, err := c.Status(context.Background())
+	assert.ErrorIs(t, err, ErrRateLimited)
+	var rateLimited *RateLimitError
+	assert.True(t, errors.As(err, &rateLimited))
+	assert.Equal(t, 1, *requests)
+}
+
+func TestAlgorandClientWrapper_RateLimitRetry(t *testing.T) {
+	c, requests := throttledNode(t, 2)
+	c.RateLimit = &RateLimitPolicy{MaxRetries: 3, DefaultRetryAfter: time.Second}
+	waits := make([]time.Duration, 0)
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	status, err := c.Status(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 5, status.LastRound)
+	assert.Equal(t, 3, *requests)
+	// the Retry-After header of the node takes precedence over the default
+	assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second}, waits)
+}
+
+func TestAlgorandClientWrapper_RateLimitedRetryAfter(t *testing.T) {
+	c, _ := throttledNode(t, 1)
+	_, err := c.Status(context.Background())
+	var rateLimited *RateLimitError
+	assert.True(t, errors.As(err, &rateLimited))
+	assert.Equal(t, 2*time.Second, rateLimited.RetryAfter)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("Tue, 01 Mar 2022 12:00:30 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Tue, 01 Mar 2022 11:59:00 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-1", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func TestAlgorandClientWrapper_RateLimitRetriesExhausted(t *testing.T) {
+	c, requests := throttledNode(t, 10)
+	c.RateLimit = &RateLimitPolicy{MaxRetries: 2, DefaultRetryAfter: time.Millisecond}
+	_, err := c.Status(context.Background())
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 3, *requests)
+}
+
+// The duration given by the error takes precedence over the default.
+func TestRetryRateLimited_RetryAfter(t *testing.T) {
+	calls := 0
+	f := func() error {
+		calls++
+		if calls == 1 {
+			return &RateLimitError{RetryAfter: 3 * time.Second, Err: errors.New("HTTP 429")}
+		}
+		return nil
+	}
+	waits := make([]time.Duration, 0)
+	sleep := func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	policy := RateLimitPolicy{MaxRetries: 1, DefaultRetryAfter: time.Second}
+	assert.Nil(t, retryRateLimited(context.Background(), policy, sleep, f))
+	assert.Equal(t, []time.Duration{3 * time.Second}, waits)
+}
+
+// Retrying stops when the context is done.
+func TestRetryRateLimited_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	f := func() error {
+		calls++
+		return &RateLimitError{RetryAfter: time.Hour}
+	}
+	policy := RateLimitPolicy{MaxRetries: 5}
+	assert.ErrorIs(t, retryRateLimited(ctx, policy, sleepContext, f), ErrRateLimited)
+	assert.Equal(t, 1, calls)
+}
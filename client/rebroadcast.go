@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// DefaultRebroadcastMaxFee is the fee cap of replacements in microAlgos (0.1 Algo), if
+// RebroadcastPolicy.MaxFee is zero.
+const DefaultRebroadcastMaxFee = 100000
+
+// RebroadcastPolicy determines how an AlgorandClientWrapper replaces transactions that
+// are stuck in the transaction pool, e.g. because their fee became too low after a
+// spike. A transaction is only valid for AfterRounds rounds. If it expires before it
+// is confirmed, it's rebuilt with twice the fee and a fresh validity window, and then
+// resubmitted. Because the old transaction has expired, it can't be committed anymore.
+//
+// The OnConfirmationTimeout hook of the client decides if an expired transaction is
+// replaced, and the ConfirmationBackoff determines the polls while waiting. Without a
+// hook, expired transactions are replaced until MaxAttempts are used up.
+type RebroadcastPolicy struct {
+	// AfterRounds is the number of rounds a transaction may stay pending before it
+	// is replaced.
+	AfterRounds uint64
+
+	// MaxAttempts is the number of replacements that are submitted before giving up.
+	// Then, the ConfirmationTimeoutError of the last replacement is returned.
+	MaxAttempts int
+
+	// MaxFee caps the fee of replacements in microAlgos, e.g. at the FeeStrategy.Max
+	// of the client. The fee of the original transaction is never lowered. If zero,
+	// DefaultRebroadcastMaxFee is used.
+	MaxFee uint64
+}
+
+// replacementFee returns the fee of the replacement of a transaction with the given
+// fee: twice the fee, but at least minFee and at most the cap of the policy.
+func (p RebroadcastPolicy) replacementFee(fee types.MicroAlgos, minFee uint64) types.MicroAlgos {
+	max := types.MicroAlgos(p.MaxFee)
+	if max == 0 {
+		max = DefaultRebroadcastMaxFee
+	}
+	next := fee * 2
+	if next < fee {
+		// overflow
+		next = max
+	}
+	if next < types.MicroAlgos(minFee) {
+		next = types.MicroAlgos(minFee)
+	}
+	if next > max {
+		next = max
+	}
+	if next < fee {
+		next = fee
+	}
+	return next
+}
+
+// executeWithRebroadcast signs and submits the transaction and waits for its
+// confirmation. Stuck transactions are replaced according to the policy, if hook is nil
+// or approves it.
+func executeWithRebroadcast(c AlgorandClient, signer AccountSigner, txn types.Transaction, policy RebroadcastPolicy, hook ConfirmationTimeoutHook, backoff *BackoffPolicy, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	for attempt := 0; ; attempt++ {
+		if txn.LastValid > txn.FirstValid+types.Round(policy.AfterRounds) {
+			txn.LastValid = txn.FirstValid + types.Round(policy.AfterRounds)
+		}
+		signedTxn, err := signer.SignTransaction(txn)
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+		txID, err := c.SendRawTransaction(signedTxn, ctx)
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+
+		// FirstValid isn't ahead of the node, so the transaction has expired once the
+		// wait is over
+		info, seen, err := waitForConfirmation(c, txID, policy.AfterRounds+1, backoff, ctx)
+		if !errors.Is(err, ErrConfirmationTimeout) {
+			return info, err
+		}
+		// the transaction might have been confirmed in the last round it was valid
+		if final, _, pendingErr := c.PendingTransactionInformation(txID, ctx); pendingErr == nil && final.ConfirmedRound > 0 {
+			return final, nil
+		}
+		if attempt >= policy.MaxAttempts || (hook != nil && !hook(txID, seen, attempt)) {
+			return info, err
+		}
+
+		params, err := c.SuggestedParams(ctx)
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+		txn.FirstValid, txn.LastValid = params.FirstRoundValid, params.LastRoundValid
+		txn.Fee = policy.replacementFee(txn.Fee, params.MinFee)
+	}
+}
@@ -0,0 +1,141 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// stuckMock keeps all transactions with a fee below minFee pending forever.
+type stuckMock struct {
+	*AlgorandMock
+	minFee    types.MicroAlgos
+	round     uint64
+	submitted []types.Transaction
+}
+
+func (m *stuckMock) Status(context.Context) (models.NodeStatus, error) {
+	return models.NodeStatus{LastRound: m.round}, nil
+}
+
+func (m *stuckMock) StatusAfterBlock(round uint64, _ context.Context) (models.NodeStatus, error) {
+	m.round = round + 1
+	return models.NodeStatus{LastRound: m.round}, nil
+}
+
+func (m *stuckMock) SuggestedParams(context.Context) (types.SuggestedParams, error) {
+	return types.SuggestedParams{Fee: 1000, FlatFee: true, MinFee: 1000,
+		FirstRoundValid: types.Round(m.round), LastRoundValid: types.Round(m.round + 1000)}, nil
+}
+
+func (m *stuckMock) SendRawTransaction(raw []byte, _ context.Context) (string, error) {
+	var stx types.SignedTxn
+	err := msgpack.Decode(raw, &stx)
+	if err != nil {
+		return "", err
+	}
+	m.submitted = append(m.submitted, stx.Txn)
+	return crypto.GetTxID(stx.Txn), nil
+}
+
+func (m *stuckMock) PendingTransactionInformation(txID string, _ context.Context) (models.PendingTransactionInfoResponse, types.SignedTxn, error) {
+	for _, txn := range m.submitted {
+		if crypto.GetTxID(txn) == txID && txn.Fee >= m.minFee {
+			return models.PendingTransactionInfoResponse{ConfirmedRound: m.round}, types.SignedTxn{}, nil
+		}
+	}
+	return models.PendingTransactionInfoResponse{}, types.SignedTxn{}, nil
+}
+
+func stuckTransaction(t *testing.T, m *stuckMock, acc crypto.Account) types.Transaction {
+	params, _ := m.SuggestedParams(context.Background())
+	txn, err := future.MakeApplicationNoOpTx(1, [][]byte{[]byte("key"), []byte("value")},
+		nil, nil, nil, params, acc.Address, []byte("put"), types.Digest{}, [32]byte{}, types.Address{})
+	assert.Nil(t, err)
+	return txn
+}
+
+// A transaction pending past the threshold is replaced with one that pays a higher fee.
+func TestExecuteWithRebroadcast(t *testing.T) {
+	m := &stuckMock{AlgorandMock: CreateAlgorandClientMock("", ""), minFee: 2000, round: 10}
+	acc := crypto.GenerateAccount()
+	txn := stuckTransaction(t, m, acc)
+
+	policy := RebroadcastPolicy{AfterRounds: 3, MaxAttempts: 2}
+	info, err := executeWithRebroadcast(m, NewKeySigner(acc), txn, policy, nil, nil, context.Background())
+	assert.Nil(t, err)
+	assert.True(t, info.ConfirmedRound > 0)
+
+	assert.Len(t, m.submitted, 2)
+	original, replacement := m.submitted[0], m.submitted[1]
+	assert.EqualValues(t, 1000, original.Fee)
+	assert.EqualValues(t, 2000, replacement.Fee)
+	assert.EqualValues(t, 13, original.LastValid)
+	assert.True(t, replacement.FirstValid > original.LastValid)
+	assert.Equal(t, original.ApplicationFields, replacement.ApplicationFields)
+}
+
+// Give up after the maximum number of replacements.
+func TestExecuteWithRebroadcast_MaxAttempts(t *testing.T) {
+	m := &stuckMock{AlgorandMock: CreateAlgorandClientMock("", ""), minFee: 10000, round: 10}
+	acc := crypto.GenerateAccount()
+	txn := stuckTransaction(t, m, acc)
+
+	policy := RebroadcastPolicy{AfterRounds: 2, MaxAttempts: 1}
+	_, err := executeWithRebroadcast(m, NewKeySigner(acc), txn, policy, nil, nil, context.Background())
+	var timeout *ConfirmationTimeoutError
+	assert.ErrorAs(t, err, &timeout)
+	assert.Equal(t, crypto.GetTxID(m.submitted[1]), timeout.TxID)
+	assert.Len(t, m.submitted, 2)
+}
+
+// The fee of replacements is capped.
+func TestExecuteWithRebroadcast_MaxFee(t *testing.T) {
+	m := &stuckMock{AlgorandMock: CreateAlgorandClientMock("", ""), minFee: 10000, round: 10}
+	acc := crypto.GenerateAccount()
+	txn := stuckTransaction(t, m, acc)
+
+	policy := RebroadcastPolicy{AfterRounds: 2, MaxAttempts: 3, MaxFee: 3000}
+	_, err := executeWithRebroadcast(m, NewKeySigner(acc), txn, policy, nil, nil, context.Background())
+	assert.ErrorIs(t, err, ErrConfirmationTimeout)
+	fees := make([]types.MicroAlgos, 0, len(m.submitted))
+	for _, txn := range m.submitted {
+		fees = append(fees, txn.Fee)
+	}
+	assert.Equal(t, []types.MicroAlgos{1000, 2000, 3000, 3000}, fees)
+}
+
+// The OnConfirmationTimeout hook decides if an expired transaction is replaced.
+func TestExecuteWithRebroadcast_Hook(t *testing.T) {
+	m := &stuckMock{AlgorandMock: CreateAlgorandClientMock("", ""), minFee: 2000, round: 10}
+	acc := crypto.GenerateAccount()
+	txn := stuckTransaction(t, m, acc)
+
+	calls := 0
+	hook := func(txID string, seenInPool bool, resubmissions int) bool {
+		calls++
+		assert.Equal(t, crypto.GetTxID(m.submitted[0]), txID)
+		assert.True(t, seenInPool)
+		return false
+	}
+	policy := RebroadcastPolicy{AfterRounds: 3, MaxAttempts: 2}
+	_, err := executeWithRebroadcast(m, NewKeySigner(acc), txn, policy, hook, nil, context.Background())
+	assert.ErrorIs(t, err, ErrConfirmationTimeout)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, m.submitted, 1)
+}
+
+func TestRebroadcastPolicy_ReplacementFee(t *testing.T) {
+	assert.EqualValues(t, DefaultRebroadcastMaxFee, RebroadcastPolicy{}.replacementFee(80000, 1000))
+	assert.EqualValues(t, 1000, RebroadcastPolicy{}.replacementFee(0, 1000))
+	// the original fee isn't lowered
+	assert.EqualValues(t, 5000, RebroadcastPolicy{MaxFee: 3000}.replacementFee(5000, 1000))
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// ErrNoAuthSigner is returned by RekeyedSigner, if none of its signers is the current
+// auth address of the sender.
+var ErrNoAuthSigner = errors.New("no signer for the auth address")
+
+// RekeyedSigner implements AccountSigner for accounts that are (or might be) rekeyed,
+// e.g. to a cold key. Before signing, it looks up the current auth address of the
+// sender with the node, and signs with the signer of that address. Transactions keep
+// signing with the right key after a rekey, as long as the signer of the new auth
+// address is known. Set it as AlgorandClientWrapper.Signer, so that it's used for all
+// transactions, including those of CreateApplication, StoreGlobals, DeleteGlobals and
+// DeleteApplication.
+type RekeyedSigner struct {
+	client  AlgorandClient
+	signers map[types.Address]AccountSigner
+	mu      sync.Mutex
+	last    types.Address
+}
+
+// NewRekeyedSigner creates a RekeyedSigner that looks up auth addresses with c, and
+// signs with one of the given signers. Include the signer of the account itself, if
+// the account might not be rekeyed.
+func NewRekeyedSigner(c AlgorandClient, signers ...AccountSigner) *RekeyedSigner {
+	s := &RekeyedSigner{client: c, signers: make(map[types.Address]AccountSigner, len(signers))}
+	for _, signer := range signers {
+		s.signers[signer.Address()] = signer
+	}
+	return s
+}
+
+// Address returns the auth address of the last signed transaction. It's zero before
+// the first transaction is signed.
+func (s *RekeyedSigner) Address() types.Address {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+func (s *RekeyedSigner) SignTransaction(txn types.Transaction) ([]byte, error) {
+	auth, err := s.AuthAddress(txn.Sender)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := s.signers[auth]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoAuthSigner, auth)
+	}
+	signedTxn, err := signer.SignTransaction(txn)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.last = auth
+	s.mu.Unlock()
+	return signedTxn, nil
+}
+
+// AuthAddress returns the address that currently signs for the given account: its auth
+// address if it's rekeyed, and the account itself otherwise.
+func (s *RekeyedSigner) AuthAddress(addr types.Address) (types.Address, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
+	info, err := s.client.AccountInformation(addr.String(), ctx)
+	cancel()
+	if err != nil {
+		return types.Address{}, err
+	}
+	if info.AuthAddr == "" {
+		return addr, nil
+	}
+	return types.DecodeAddress(info.AuthAddr)
+}
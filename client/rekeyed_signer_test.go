@@ -0,0 +1,69 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/stretchr/testify/assert"
+)
+
+// rekey rekeys acc to the address of to
+func rekey(t *testing.T, l *FakeLedger, acc, to crypto.Account) {
+	params, err := l.SuggestedParams(context.Background())
+	assert.Nil(t, err)
+	addr := acc.Address.String()
+	txn, err := future.MakePaymentTxn(addr, addr, 0, nil, "", params)
+	assert.Nil(t, err)
+	assert.Nil(t, txn.Rekey(to.Address.String()))
+	_, err = l.ExecuteTransaction(acc, txn, context.Background())
+	assert.Nil(t, err)
+}
+
+// After a rekey, transactions are signed by the new auth key
+func TestRekeyedSigner(t *testing.T) {
+	l := NewFakeLedger()
+	acc, cold := crypto.GenerateAccount(), crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	signer := NewRekeyedSigner(l, NewKeySigner(acc), NewKeySigner(cold))
+	l.Signer = signer
+
+	appId, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+	assert.Equal(t, acc.Address, signer.Address())
+
+	rekey(t, l, acc, cold)
+	assert.Equal(t, acc.Address, signer.Address(), "the rekey is signed by the account")
+	auth, err := signer.AuthAddress(acc.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, cold.Address, auth)
+
+	err = l.StoreGlobals(acc, appId, []models.TealKeyValue{{Key: "k", Value: models.TealValue{Bytes: "v"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, cold.Address, signer.Address())
+	err = l.DeleteGlobals(acc, appId, "k")
+	assert.Nil(t, err)
+	assert.Nil(t, l.DeleteApplication(acc, appId))
+
+	// the private key of the account no longer signs
+	l.Signer = nil
+	_, err = l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.NotNil(t, err)
+}
+
+func TestRekeyedSigner_UnknownAuthAddress(t *testing.T) {
+	l := NewFakeLedger()
+	acc, cold := crypto.GenerateAccount(), crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	rekey(t, l, acc, cold)
+
+	l.Signer = NewRekeyedSigner(l, NewKeySigner(acc))
+	_, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.True(t, errors.Is(err, ErrNoAuthSigner))
+	assert.Contains(t, err.Error(), cold.Address.String())
+}
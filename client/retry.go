@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// defaultRetryAttempts is the number of attempts of a RetryPolicy without Attempts.
+const defaultRetryAttempts = 3
+
+// defaultRetryBackoff is the backoff of a RetryPolicy without Backoff.
+var defaultRetryBackoff = BackoffPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 2 * time.Second, Multiplier: 2}
+
+// RetryPolicy determines how a client returned by WithRetries retries failed reads.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts of a read, including the first one.
+	// If zero, 3 attempts are made.
+	Attempts int
+
+	// Backoff determines the time to wait before each retry. If zero, the first retry
+	// waits 100ms, and each following one twice as long, up to 2s.
+	Backoff BackoffPolicy
+
+	// Retryable returns true for errors that may go away by retrying. If nil,
+	// IsTransient is used.
+	Retryable func(err error) bool
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.Attempts > 0 {
+		return p.Attempts
+	}
+	return defaultRetryAttempts
+}
+
+func (p RetryPolicy) backoff() BackoffPolicy {
+	if p.Backoff == (BackoffPolicy{}) {
+		return defaultRetryBackoff
+	}
+	return p.Backoff
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsTransient(err)
+}
+
+// IsTransient returns true for errors of requests that may succeed if they're
+// repeated: server errors of the node (HTTP 5xx), throttled requests (HTTP 429) and
+// network errors. Errors of cancelled contexts aren't transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) || strings.HasPrefix(err.Error(), "HTTP 5") {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// RetryError is returned by a client returned by WithRetries, if a read still failed
+// after it has been retried.
+type RetryError struct {
+	// Op is the name of the method, e.g. "Status".
+	Op string
+
+	// Attempts is the number of attempts that were made, including the first one.
+	Attempts int
+
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%s failed after %d attempts: %s", e.Op, e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryingClient retries the idempotent reads of the wrapped client.
+type retryingClient struct {
+	AlgorandClient
+	policy RetryPolicy
+}
+
+// WithRetries returns an AlgorandClient that retries failed reads of c with exponential
+// backoff, according to p: HealthCheck, Status, SuggestedParams, AccountInformation,
+// AccountInformationExcluding, GetApplicationByID and PendingTransactionInformation.
+// Retries stop when the context of the read is done. Submissions (e.g.
+// SendRawTransaction) are never retried, since a retry could submit a transaction
+// twice. Like WithSubmitHook, the application management methods (e.g. StoreGlobals)
+// build their transactions through the returned client, so that their reads are
+// retried. Wrap clients with WithRetries before WithSubmitHook and WithTimeouts.
+func WithRetries(c AlgorandClient, p RetryPolicy) AlgorandClient {
+	return &retryingClient{AlgorandClient: c, policy: p}
+}
+
+// retry calls f until it succeeds, it fails with an error that isn't retryable, the
+// attempts are used up, or ctx is done. If f has been retried, the error of the last
+// attempt is returned as RetryError.
+func (r *retryingClient) retry(ctx context.Context, op string, f func() error) error {
+	err := f()
+	attempts := 1
+	for ; attempts < r.policy.attempts() && err != nil && r.policy.retryable(err); attempts++ {
+		r.logger().Debugf("retrying %s after error: %s", op, err)
+		if sleepErr := sleepContext(ctx, r.policy.backoff().delay(attempts-1)); sleepErr != nil {
+			break
+		}
+		err = f()
+	}
+	if err != nil && attempts > 1 {
+		return &RetryError{Op: op, Attempts: attempts, Err: err}
+	}
+	return err
+}
+
+func (r *retryingClient) logger() Logger {
+	return loggerOf(r.AlgorandClient)
+}
+
+func (r *retryingClient) HealthCheck(ctx context.Context) error {
+	return r.retry(ctx, "HealthCheck", func() error {
+		return r.AlgorandClient.HealthCheck(ctx)
+	})
+}
+
+func (r *retryingClient) Status(ctx context.Context) (response models.NodeStatus, err error) {
+	err = r.retry(ctx, "Status", func() error {
+		response, err = r.AlgorandClient.Status(ctx)
+		return err
+	})
+	return response, err
+}
+
+func (r *retryingClient) SuggestedParams(ctx context.Context) (params types.SuggestedParams, err error) {
+	err = r.retry(ctx, "SuggestedParams", func() error {
+		params, err = r.AlgorandClient.SuggestedParams(ctx)
+		return err
+	})
+	return params, err
+}
+
+func (r *retryingClient) AccountInformation(addr string, ctx context.Context) (response models.Account, err error) {
+	err = r.retry(ctx, "AccountInformation", func() error {
+		response, err = r.AlgorandClient.AccountInformation(addr, ctx)
+		return err
+	})
+	return response, err
+}
+
+func (r *retryingClient) AccountInformationExcluding(addr string, exclude []string, ctx context.Context) (response models.Account, err error) {
+	err = r.retry(ctx, "AccountInformationExcluding", func() error {
+		response, err = AccountInformationExcluding(r.AlgorandClient, addr, exclude, ctx)
+		return err
+	})
+	return response, err
+}
+
+func (r *retryingClient) GetApplicationByID(id uint64, ctx context.Context) (response models.Application, err error) {
+	err = r.retry(ctx, "GetApplicationByID", func() error {
+		response, err = r.AlgorandClient.GetApplicationByID(id, ctx)
+		return err
+	})
+	return response, err
+}
+
+func (r *retryingClient) PendingTransactionInformation(txid string, ctx context.Context) (response models.PendingTransactionInfoResponse, stxn types.SignedTxn, err error) {
+	err = r.retry(ctx, "PendingTransactionInformation", func() error {
+		response, stxn, err = r.AlgorandClient.PendingTransactionInformation(txid, ctx)
+		return err
+	})
+	return response, stxn, err
+}
+
+func (r *retryingClient) DeleteApplication(acc crypto.Account, appId uint64) error {
+	return deleteApplication(r, acc, appId)
+}
+
+func (r *retryingClient) CreateApplication(acc crypto.Account, approve string, clear string) (uint64, error) {
+	return createApplication(r, acc, approve, clear)
+}
+
+func (r *retryingClient) DeleteGlobals(acc crypto.Account, appId uint64, keys ...string) error {
+	_, err := deleteGlobals(r, acc, appId, keys...)
+	return err
+}
+
+func (r *retryingClient) DeleteGlobalsResult(acc crypto.Account, appId uint64, keys ...string) (models.PendingTransactionInfoResponse, error) {
+	return deleteGlobals(r, acc, appId, keys...)
+}
+
+func (r *retryingClient) StoreGlobals(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) error {
+	_, err := storeGlobals(r, acc, appId, tkv)
+	return err
+}
+
+func (r *retryingClient) StoreGlobalsResult(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	return storeGlobals(r, acc, appId, tkv)
+}
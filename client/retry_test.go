@@ -0,0 +1,130 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyClient fails the first failures calls of Status, SuggestedParams and
+// SendRawTransaction with err.
+type flakyClient struct {
+	*AlgorandMock
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *flakyClient) fail() error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *flakyClient) Status(ctx context.Context) (models.NodeStatus, error) {
+	if err := f.fail(); err != nil {
+		return models.NodeStatus{}, err
+	}
+	return f.AlgorandMock.Status(ctx)
+}
+
+func (f *flakyClient) SuggestedParams(ctx context.Context) (types.SuggestedParams, error) {
+	if err := f.fail(); err != nil {
+		return types.SuggestedParams{}, err
+	}
+	return f.AlgorandMock.SuggestedParams(ctx)
+}
+
+func (f *flakyClient) SendRawTransaction(b []byte, ctx context.Context) (string, error) {
+	if err := f.fail(); err != nil {
+		return "", err
+	}
+	return f.AlgorandMock.SendRawTransaction(b, ctx)
+}
+
+var fastRetries = RetryPolicy{Backoff: BackoffPolicy{BaseDelay: time.Millisecond}}
+
+func TestWithRetries(t *testing.T) {
+	f := &flakyClient{AlgorandMock: CreateAlgorandClientMock("", ""), err: errors.New("HTTP 503: unavailable"), failures: 2}
+	c := WithRetries(f, fastRetries)
+	_, err := c.Status(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, f.calls)
+
+	// the last error reports the attempts
+	f.calls, f.failures = 0, 5
+	_, err = c.Status(context.Background())
+	var retryErr *RetryError
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, 3, retryErr.Attempts)
+	assert.Equal(t, "Status", retryErr.Op)
+	assert.Equal(t, f.err, errors.Unwrap(err))
+	assert.Equal(t, 3, f.calls)
+
+	// errors that aren't transient are returned right away
+	f.calls, f.err = 0, errors.New("HTTP 404: not found")
+	_, err = c.Status(context.Background())
+	assert.Equal(t, f.err, err)
+	assert.Equal(t, 1, f.calls)
+}
+
+// Submissions are never retried, but the reads of the management methods are.
+func TestWithRetries_Submissions(t *testing.T) {
+	f := &flakyClient{AlgorandMock: CreateAlgorandClientMock("", ""), err: errors.New("HTTP 500: internal"), failures: 1}
+	c := WithRetries(f, fastRetries)
+	_, err := c.SendRawTransaction(nil, context.Background())
+	assert.Equal(t, f.err, err)
+	assert.Equal(t, 1, f.calls)
+
+	f.CreateDummyApps(6)
+	f.App = f.Account.CreatedApps[0]
+	f.calls = 0
+	err = c.StoreGlobals(crypto.GenerateAccount(), 6, []models.TealKeyValue{{Key: "a", Value: models.TealValue{Bytes: "1"}}})
+	assert.Nil(t, err)
+	assert.Len(t, f.App.Params.GlobalState, 1)
+	assert.Equal(t, 2, f.calls)
+}
+
+// Retries stop when the context is done.
+func TestWithRetries_Context(t *testing.T) {
+	f := &flakyClient{AlgorandMock: CreateAlgorandClientMock("", ""), err: errors.New("HTTP 502: bad gateway"), failures: 5}
+	c := WithRetries(f, RetryPolicy{Attempts: 5, Backoff: BackoffPolicy{BaseDelay: time.Hour}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.Status(ctx)
+	var retryErr *RetryError
+	assert.False(t, errors.As(err, &retryErr))
+	assert.Equal(t, f.err, err)
+	assert.Equal(t, 1, f.calls)
+
+	// custom classification
+	f.calls, f.err = 0, errors.New("provider: try again")
+	c = WithRetries(f, RetryPolicy{Attempts: 2, Backoff: fastRetries.Backoff, Retryable: func(error) bool { return true }})
+	_, err = c.Status(context.Background())
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, 2, f.calls)
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, IsTransient(errors.New("HTTP 503: unavailable")))
+	assert.True(t, IsTransient(&RateLimitError{}))
+	assert.True(t, IsTransient(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.True(t, IsTransient(fmt.Errorf("read: %w", io.ErrUnexpectedEOF)))
+	assert.False(t, IsTransient(errors.New("HTTP 400: bad request")))
+	assert.False(t, IsTransient(context.DeadlineExceeded))
+	assert.False(t, IsTransient(errors.New("transaction rejected by ApprovalProgram")))
+	assert.False(t, IsTransient(nil))
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// maxGlobalKeys and maxLocalKeys are the maximum number of keys in the global and local
+// state of an application.
+const (
+	maxGlobalKeys = 64
+	maxLocalKeys  = 16
+)
+
+// SchemaSpec is the state schema of the buffer's application. The buffer stores its
+// key-value pairs in the global byte slices. Global ints (e.g. for a counter of a
+// custom contract) and the local schema are only allocated on creation.
+type SchemaSpec struct {
+	GlobalInts  uint64
+	GlobalBytes uint64
+	LocalInts   uint64
+	LocalBytes  uint64
+}
+
+// DefaultSchema is the schema of the approval.teal contract: 64 global byte slices.
+var DefaultSchema = SchemaSpec{
+	GlobalInts:  GlobalInts,
+	GlobalBytes: GlobalBytes,
+	LocalInts:   LocalInts,
+	LocalBytes:  LocalBytes,
+}
+
+// Validate returns an error if the schema can't be allocated, or leaves no byte
+// slices for the buffer.
+func (s SchemaSpec) Validate() error {
+	if s.GlobalBytes == 0 {
+		return fmt.Errorf("schema must contain global byte slices")
+	}
+	if s.GlobalInts+s.GlobalBytes > maxGlobalKeys {
+		return fmt.Errorf("global schema can't exceed %d keys, got %d", maxGlobalKeys, s.GlobalInts+s.GlobalBytes)
+	}
+	if s.LocalInts+s.LocalBytes > maxLocalKeys {
+		return fmt.Errorf("local schema can't exceed %d keys, got %d", maxLocalKeys, s.LocalInts+s.LocalBytes)
+	}
+	return nil
+}
+
+// Schemas returns the local and global state schema of the spec, in the order of
+// GenerateSchemas.
+func (s SchemaSpec) Schemas() (types.StateSchema, types.StateSchema) {
+	globalSchema := types.StateSchema{NumUint: s.GlobalInts, NumByteSlice: s.GlobalBytes}
+	localSchema := types.StateSchema{NumUint: s.LocalInts, NumByteSlice: s.LocalBytes}
+	return localSchema, globalSchema
+}
+
+// Fulfills returns true if the given application has the global state schema of the
+// spec. The local schema isn't checked, because the buffer doesn't use local state.
+func (s SchemaSpec) Fulfills(app models.Application) bool {
+	if app.Id == 0 {
+		return false
+	}
+	g := app.Params.GlobalStateSchema
+	return g.NumByteSlice == s.GlobalBytes && g.NumUint == s.GlobalInts
+}
+
+// ValidAccount returns true if the given account is a valid AlgorandBuffer target and
+// ready to store data in a single application with the schema of the spec.
+func (s SchemaSpec) ValidAccount(account models.Account) bool {
+	return len(account.CreatedApps) == 1 && s.Fulfills(account.CreatedApps[0])
+}
@@ -0,0 +1,36 @@
+//go:build unit
+
+package client
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaSpec_Fulfills(t *testing.T) {
+	spec := SchemaSpec{GlobalInts: 1, GlobalBytes: 30}
+	app := models.Application{Id: 6, Params: models.ApplicationParams{
+		GlobalStateSchema: models.ApplicationStateSchema{NumUint: 1, NumByteSlice: 30},
+	}}
+	assert.True(t, spec.Fulfills(app))
+	assert.False(t, FulfillsSchema(app))
+	assert.True(t, spec.ValidAccount(models.Account{CreatedApps: []models.Application{app}}))
+
+	_, g := GenerateSchemasModel()
+	app.Params.GlobalStateSchema = g
+	assert.False(t, spec.Fulfills(app))
+	assert.True(t, FulfillsSchema(app))
+
+	app.Id = 0
+	assert.False(t, DefaultSchema.Fulfills(app))
+}
+
+func TestSchemaSpec_Validate(t *testing.T) {
+	assert.Nil(t, DefaultSchema.Validate())
+	assert.Nil(t, SchemaSpec{GlobalInts: 1, GlobalBytes: 30, LocalBytes: 16}.Validate())
+	assert.NotNil(t, SchemaSpec{GlobalInts: 2}.Validate())
+	assert.NotNil(t, SchemaSpec{GlobalInts: 1, GlobalBytes: 64}.Validate())
+	assert.NotNil(t, SchemaSpec{GlobalBytes: 1, LocalInts: 10, LocalBytes: 7}.Validate())
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// AccountSigner signs transactions on behalf of an Algorand account. It abstracts
+// away where the signing key is kept, so that keys don't have to be held in memory
+// (e.g. when using a hardware wallet like the LedgerSigner).
+type AccountSigner interface {
+	// Address returns the address of the key that signs transactions. If it differs
+	// from a transaction's sender, the address is used as auth address.
+	Address() types.Address
+
+	// SignTransaction signs the given transaction and returns the msgpack-encoded
+	// signed transaction, ready to be sent with SendRawTransaction.
+	SignTransaction(types.Transaction) ([]byte, error)
+}
+
+// KeySigner implements AccountSigner with the private key of a crypto.Account.
+type KeySigner struct {
+	Account crypto.Account
+}
+
+// NewKeySigner creates an AccountSigner that signs with the private key of acc.
+func NewKeySigner(acc crypto.Account) *KeySigner {
+	return &KeySigner{Account: acc}
+}
+
+func (s *KeySigner) Address() types.Address {
+	return s.Account.Address
+}
+
+func (s *KeySigner) SignTransaction(txn types.Transaction) ([]byte, error) {
+	_, signedTxn, err := crypto.SignTransaction(s.Account.PrivateKey, txn)
+	return signedTxn, err
+}
+
+// encodeSignedTxn assembles a signed transaction from a given signature of the
+// signer with address addr.
+func encodeSignedTxn(txn types.Transaction, sig types.Signature, addr types.Address) []byte {
+	stx := types.SignedTxn{Sig: sig, Txn: txn}
+	if txn.Sender != addr {
+		stx.AuthAddr = addr
+	}
+	return msgpack.Encode(stx)
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/algorand/go-algorand-sdk/crypto"
 	"reflect"
 	"runtime"
+	"sync"
+	"time"
 
 	"github.com/algorand/go-algorand-sdk/types"
 
@@ -26,12 +28,52 @@ type AlgorandMock struct {
 	SignedTXN         types.SignedTxn
 	CompileResponse   models.CompileResponse
 	ErrorFunctions    map[string]bool
+
+	// AccountExclude holds the excluded fields of the last call to
+	// AccountInformationExcluding
+	AccountExclude []string
+
+	// Disassembly holds the TEAL source returned by DisassembleProgram, by
+	// base64-encoded bytecode
+	Disassembly map[string]string
+
+	// Groups holds the transactions of every call to ExecuteGroup, with their group
+	// IDs assigned
+	Groups [][]types.Transaction
+
+	// PendingTXNs holds the transaction pool returned by PendingTransactionsByAddress
+	PendingTXNs []types.SignedTxn
+
+	// DryrunResponse is returned by SimulateTransaction
+	DryrunResponse models.DryrunResponse
+
+	// RejectCloseOut makes close-out calls fail, like an approval program that rejects
+	// them. Clear-state calls always succeed.
+	RejectCloseOut bool
+
+	// Latency delays every call that takes a context, and every transaction of a
+	// group. If the context is done before, the call fails with the error of the
+	// context.
+	Latency time.Duration
+
+	// SimulateRounds makes StatusAfterBlock advance the LastRound of NodeStatus past
+	// the requested round, taking BlockTime per round, like a node would. Otherwise,
+	// StatusAfterBlock returns NodeStatus right away.
+	SimulateRounds bool
+	BlockTime      time.Duration
+
+	// responses holds the scripted responses of QueueResponse, by method name
+	responses map[string][]interface{}
+
+	callsMu sync.Mutex
+	calls   []RecordedCall
 }
 
 // wrapExecutionCondition wraps the execution of an AlgorandMock function and
 // returns the expected value i with a nil error by default.
 // AlgorandMock allows you to configure, which methods return errors or timeouts,
-// configurable by SetError(...) or AlwaysReturnError. wrapExecutionCondition
+// configurable by SetError(...) or AlwaysReturnError. Otherwise, the next response
+// queued by QueueResponse is returned instead of i. wrapExecutionCondition
 // implements this behavior.
 func (a *AlgorandMock) wrapExecutionCondition(i interface{}, def interface{}, f interface{}) (interface{}, error) {
 	funcName := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
@@ -44,15 +86,37 @@ func (a *AlgorandMock) wrapExecutionCondition(i interface{}, def interface{}, f
 			return def, err
 		}
 	}
+	if queue := a.responses[funcName]; len(queue) > 0 {
+		a.responses[funcName] = queue[1:]
+		if err, ok := queue[0].(error); ok {
+			return def, err
+		}
+		return queue[0], nil
+	}
 
 	return i, nil
 }
 
+// wait takes the Latency of a call, unless ctx is done before.
+func (a *AlgorandMock) wait(ctx context.Context) error {
+	if a.Latency <= 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return sleepContext(ctx, a.Latency)
+}
+
 func CreateAlgorandClientMock(URL string, token string) *AlgorandMock {
 	err := make(map[string]bool)
-	return &AlgorandMock{ErrorFunctions: err}
+	return &AlgorandMock{ErrorFunctions: err, Account: models.Account{Amount: MockAccountAmount}}
 }
 
+// MockAccountAmount is the balance of the account of a new AlgorandMock in microAlgos.
+// It covers the minimum balance of several apps with the DefaultSchema.
+const MockAccountAmount = 100000000
+
 // SetError controls whether or not the specified functions return an error or not.
 // If val is set to true, all provided methods belonging to this struct will return
 // errors when called.
@@ -63,6 +127,31 @@ func (a *AlgorandMock) SetError(val bool, f ...interface{}) {
 	}
 }
 
+// QueueResponse scripts the next response of the method f. Each call of f returns the
+// next queued response, in order, and falls back to the corresponding public field
+// once the queue is empty. A response has the type f returns, except for
+// PendingTransactionInformation, which takes a models.PendingTransactionInfoResponse.
+// An error response makes the call fail with that error. Errors set by SetError or
+// AlwaysReturnError take precedence, and don't consume queued responses.
+func (a *AlgorandMock) QueueResponse(f interface{}, response interface{}) {
+	a.QueueResponses(f, response)
+}
+
+// QueueResponses scripts the next responses of the method f, in order. See
+// QueueResponse.
+func (a *AlgorandMock) QueueResponses(f interface{}, responses ...interface{}) {
+	if a.responses == nil {
+		a.responses = make(map[string][]interface{})
+	}
+	funcName := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	a.responses[funcName] = append(a.responses[funcName], responses...)
+}
+
+// ClearResponses drops all responses queued by QueueResponse.
+func (a *AlgorandMock) ClearResponses() {
+	a.responses = nil
+}
+
 // AddDummyApps adds applications with given IDs to the account with the default
 // AEMA schema.
 func (a *AlgorandMock) AddDummyApps(ids ...uint64) {
@@ -101,63 +190,267 @@ func (a *AlgorandMock) ClearFunctionErrors() {
 	a.ErrorFunctions = make(map[string]bool)
 }
 
-func (a *AlgorandMock) AccountInformation(string, context.Context) (models.Account, error) {
+func (a *AlgorandMock) AccountInformation(addr string, ctx context.Context) (models.Account, error) {
+	a.record((*AlgorandMock).AccountInformation, addr)
+	if err := a.wait(ctx); err != nil {
+		return models.Account{}, err
+	}
 	ret, err := a.wrapExecutionCondition(a.Account, models.Account{}, (*AlgorandMock).AccountInformation)
 	return ret.(models.Account), err
 }
 
-func (a *AlgorandMock) GetApplicationByID(uint64, context.Context) (models.Application, error) {
+func (a *AlgorandMock) AccountInformationExcluding(s string, exclude []string, ctx context.Context) (models.Account, error) {
+	a.record((*AlgorandMock).AccountInformationExcluding, s, exclude)
+	a.AccountExclude = exclude
+	acc, err := a.AccountInformation(s, ctx)
+	if err != nil {
+		return acc, err
+	}
+	return excludeFields(acc, exclude)
+}
+
+func (a *AlgorandMock) GetApplicationByID(appId uint64, ctx context.Context) (models.Application, error) {
+	a.record((*AlgorandMock).GetApplicationByID, appId)
+	if err := a.wait(ctx); err != nil {
+		return models.Application{}, err
+	}
 	ret, err := a.wrapExecutionCondition(a.App, models.Application{}, (*AlgorandMock).GetApplicationByID)
 	return ret.(models.Application), err
 }
 
-func (a *AlgorandMock) SuggestedParams(context.Context) (types.SuggestedParams, error) {
+func (a *AlgorandMock) SuggestedParams(ctx context.Context) (types.SuggestedParams, error) {
+	a.record((*AlgorandMock).SuggestedParams)
+	if err := a.wait(ctx); err != nil {
+		return types.SuggestedParams{}, err
+	}
 	ret, err := a.wrapExecutionCondition(a.Params, types.SuggestedParams{}, (*AlgorandMock).SuggestedParams)
 	return ret.(types.SuggestedParams), err
 }
 
-func (a *AlgorandMock) HealthCheck(context.Context) error {
+func (a *AlgorandMock) HealthCheck(ctx context.Context) error {
+	a.record((*AlgorandMock).HealthCheck)
+	if err := a.wait(ctx); err != nil {
+		return err
+	}
 	_, err := a.wrapExecutionCondition(nil, nil, (*AlgorandMock).HealthCheck)
 	return err
 }
 
-func (a *AlgorandMock) Status(context.Context) (models.NodeStatus, error) {
+func (a *AlgorandMock) Status(ctx context.Context) (models.NodeStatus, error) {
+	a.record((*AlgorandMock).Status)
+	if err := a.wait(ctx); err != nil {
+		return models.NodeStatus{}, err
+	}
 	ret, err := a.wrapExecutionCondition(a.NodeStatus, models.NodeStatus{}, (*AlgorandMock).Status)
 	return ret.(models.NodeStatus), err
 }
 
-func (a *AlgorandMock) StatusAfterBlock(uint64, context.Context) (models.NodeStatus, error) {
+// StatusAfterBlock returns NodeStatus. With SimulateRounds, it first waits until the
+// simulated round clock passed round.
+func (a *AlgorandMock) StatusAfterBlock(round uint64, ctx context.Context) (models.NodeStatus, error) {
+	a.record((*AlgorandMock).StatusAfterBlock, round)
+	if err := a.wait(ctx); err != nil {
+		return models.NodeStatus{}, err
+	}
+	for a.SimulateRounds && a.NodeStatus.LastRound <= round {
+		if a.BlockTime > 0 {
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if err := sleepContext(ctx, a.BlockTime); err != nil {
+				return models.NodeStatus{}, err
+			}
+		}
+		a.NodeStatus.LastRound++
+	}
 	ret, err := a.wrapExecutionCondition(a.NodeStatus, models.NodeStatus{}, (*AlgorandMock).StatusAfterBlock)
 	return ret.(models.NodeStatus), err
 }
 
-func (a *AlgorandMock) SendRawTransaction([]byte, context.Context) (string, error) {
+func (a *AlgorandMock) SendRawTransaction(rawTxn []byte, ctx context.Context) (string, error) {
+	a.record((*AlgorandMock).SendRawTransaction, rawTxn)
+	if err := a.wait(ctx); err != nil {
+		return "", err
+	}
 	ret, err := a.wrapExecutionCondition(a.RawTXNResponse, "", (*AlgorandMock).SendRawTransaction)
 	return ret.(string), err
 }
 
-func (a *AlgorandMock) PendingTransactionInformation(string, context.Context) (models.PendingTransactionInfoResponse, types.SignedTxn, error) {
+func (a *AlgorandMock) PendingTransactionInformation(txid string, ctx context.Context) (models.PendingTransactionInfoResponse, types.SignedTxn, error) {
+	a.record((*AlgorandMock).PendingTransactionInformation, txid)
+	if err := a.wait(ctx); err != nil {
+		return models.PendingTransactionInfoResponse{}, types.SignedTxn{}, err
+	}
 	type txResponse struct {
 		Info models.PendingTransactionInfoResponse
 		TXN  types.SignedTxn
 	}
 	txr := txResponse{Info: a.PendingTXNInfo, TXN: a.SignedTXN}
 	ret, err := a.wrapExecutionCondition(txr, txResponse{}, (*AlgorandMock).PendingTransactionInformation)
+	if info, ok := ret.(models.PendingTransactionInfoResponse); ok {
+		// queued response
+		return info, a.SignedTXN, err
+	}
 	txr = ret.(txResponse)
 	return txr.Info, txr.TXN, err
 }
 
-func (a *AlgorandMock) TealCompile([]byte, context.Context) (models.CompileResponse, error) {
+// PendingTransactionsByAddress returns the transactions of PendingTXNs sent by addr.
+func (a *AlgorandMock) PendingTransactionsByAddress(addr string, max uint64, ctx context.Context) ([]types.SignedTxn, error) {
+	a.record((*AlgorandMock).PendingTransactionsByAddress, addr, max)
+	if err := a.wait(ctx); err != nil {
+		return nil, err
+	}
+	_, err := a.wrapExecutionCondition(nil, nil, (*AlgorandMock).PendingTransactionsByAddress)
+	if err != nil {
+		return nil, err
+	}
+	txns := make([]types.SignedTxn, 0)
+	for _, stxn := range a.PendingTXNs {
+		if max > 0 && uint64(len(txns)) == max {
+			break
+		}
+		if stxn.Txn.Sender.String() == addr {
+			txns = append(txns, stxn)
+		}
+	}
+	return txns, nil
+}
+
+func (a *AlgorandMock) TealCompile(program []byte, ctx context.Context) (models.CompileResponse, error) {
+	a.record((*AlgorandMock).TealCompile, program)
+	if err := a.wait(ctx); err != nil {
+		return models.CompileResponse{}, err
+	}
 	ret, err := a.wrapExecutionCondition(a.CompileResponse, models.CompileResponse{}, (*AlgorandMock).TealCompile)
 	return ret.(models.CompileResponse), err
 }
 
-func (a *AlgorandMock) ExecuteTransaction(crypto.Account, types.Transaction, context.Context) (models.PendingTransactionInfoResponse, error) {
-	panic("AlgorandStub doesn't stub this method")
-	return models.PendingTransactionInfoResponse{}, nil
+// DisassembleProgram returns the source that Disassembly holds for the given bytecode.
+func (a *AlgorandMock) DisassembleProgram(bytecode []byte, ctx context.Context) (string, error) {
+	a.record((*AlgorandMock).DisassembleProgram, bytecode)
+	if err := a.wait(ctx); err != nil {
+		return "", err
+	}
+	_, err := a.wrapExecutionCondition(nil, nil, (*AlgorandMock).DisassembleProgram)
+	if err != nil {
+		return "", err
+	}
+	source, ok := a.Disassembly[base64.StdEncoding.EncodeToString(bytecode)]
+	if !ok {
+		return "", errors.New("invalid program")
+	}
+	return source, nil
+}
+
+// ExecuteTransaction applies application calls, as built by the application management
+// methods of the other AlgorandClient implementations, to the mock: app creation and
+// deletion, and puts and deletes of global state. Other transactions aren't supported.
+func (a *AlgorandMock) ExecuteTransaction(acc crypto.Account, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	a.record((*AlgorandMock).ExecuteTransaction, acc, txn)
+	if err := a.wait(ctx); err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	if txn.Type == types.PaymentTx && !txn.RekeyTo.IsZero() {
+		// balances aren't tracked, so payments only apply rekeys
+		_, err := a.wrapExecutionCondition(nil, nil, (*AlgorandMock).ExecuteTransaction)
+		if err == nil {
+			a.Account.AuthAddr = txn.RekeyTo.String()
+			if txn.RekeyTo == txn.Sender {
+				a.Account.AuthAddr = ""
+			}
+		}
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	if txn.Type != types.ApplicationCallTx {
+		return models.PendingTransactionInfoResponse{}, errors.New("AlgorandMock only executes application calls")
+	}
+	appId := uint64(txn.ApplicationID)
+	if appId == 0 {
+		id, err := a.CreateApplication(acc, "", "")
+		if err == nil {
+			// apply the schema of the transaction, e.g. of CreateApplicationWithSchema
+			g, l := txn.GlobalStateSchema, txn.LocalStateSchema
+			a.App.Params.GlobalStateSchema = models.ApplicationStateSchema{NumUint: g.NumUint, NumByteSlice: g.NumByteSlice}
+			a.App.Params.LocalStateSchema = models.ApplicationStateSchema{NumUint: l.NumUint, NumByteSlice: l.NumByteSlice}
+			a.Account.CreatedApps[0] = a.App
+		}
+		return models.PendingTransactionInfoResponse{ApplicationIndex: id}, err
+	}
+	if txn.OnCompletion == types.DeleteApplicationOC {
+		return models.PendingTransactionInfoResponse{}, a.DeleteApplication(acc, appId)
+	}
+	if txn.OnCompletion == types.CloseOutOC || txn.OnCompletion == types.ClearStateOC {
+		return models.PendingTransactionInfoResponse{}, a.leaveApplication(appId, txn.OnCompletion)
+	}
+	args := txn.ApplicationArgs
+	switch string(txn.Note) {
+	case "put":
+		tkv := make([]models.TealKeyValue, 0, len(args)/2)
+		for i := 0; i+1 < len(args); i += 2 {
+			tkv = append(tkv, models.TealKeyValue{Key: string(args[i]), Value: models.TealValue{Bytes: string(args[i+1])}})
+		}
+		return a.StoreGlobalsResult(acc, appId, tkv)
+	case "delete":
+		keys := make([]string, len(args))
+		for i, k := range args {
+			keys[i] = string(k)
+		}
+		return a.DeleteGlobalsResult(acc, appId, keys...)
+	}
+	if len(args) == 0 {
+		// approval.teal accepts calls without arguments, regardless of the note
+		_, err := a.wrapExecutionCondition(nil, nil, (*AlgorandMock).ExecuteTransaction)
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	return models.PendingTransactionInfoResponse{}, errors.New("AlgorandMock doesn't support this application call")
+}
+
+// ExecuteGroup assigns the group ID and records the group in Groups. The transactions
+// are then executed one after another like in ExecuteTransaction, so unlike on a node,
+// the group isn't atomic.
+func (a *AlgorandMock) ExecuteGroup(acc crypto.Account, txns []types.Transaction, ctx context.Context) ([]models.PendingTransactionInfoResponse, error) {
+	a.record((*AlgorandMock).ExecuteGroup, acc, txns)
+	_, err := a.wrapExecutionCondition(nil, nil, (*AlgorandMock).ExecuteGroup)
+	if err != nil {
+		return nil, err
+	}
+	txns, err = assignGroup(txns)
+	if err != nil {
+		return nil, err
+	}
+	a.Groups = append(a.Groups, txns)
+	infos := make([]models.PendingTransactionInfoResponse, len(txns))
+	for i, txn := range txns {
+		infos[i], err = a.ExecuteTransaction(acc, txn, ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+// leaveApplication removes the app from the local states of Account. The mock
+// doesn't track accounts by address, so every account shares the local states.
+func (a *AlgorandMock) leaveApplication(appId uint64, oc types.OnCompletion) error {
+	_, err := a.wrapExecutionCondition(nil, nil, (*AlgorandMock).ExecuteTransaction)
+	if err != nil {
+		return err
+	}
+	if oc == types.CloseOutOC && a.RejectCloseOut {
+		return errors.New("transaction rejected by ApprovalProgram")
+	}
+	for i, local := range a.Account.AppsLocalState {
+		if local.Id == appId {
+			a.Account.AppsLocalState = append(a.Account.AppsLocalState[:i:i], a.Account.AppsLocalState[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("account is not opted in to the application")
 }
 
 func (a *AlgorandMock) DeleteApplication(acc crypto.Account, appId uint64) error {
+	a.record((*AlgorandMock).DeleteApplication, acc, appId)
 	_, err := a.wrapExecutionCondition(nil, nil, (*AlgorandMock).DeleteApplication)
 	if err != nil {
 		return err
@@ -178,6 +471,7 @@ func (a *AlgorandMock) DeleteApplication(acc crypto.Account, appId uint64) error
 }
 
 func (a *AlgorandMock) CreateApplication(account crypto.Account, approve string, clear string) (uint64, error) {
+	a.record((*AlgorandMock).CreateApplication, account, approve, clear)
 	l, g := GenerateSchemasModel()
 	params := models.ApplicationParams{GlobalStateSchema: g, LocalStateSchema: l}
 	app := models.Application{Id: 4512, Params: params}
@@ -190,9 +484,22 @@ func (a *AlgorandMock) CreateApplication(account crypto.Account, approve string,
 	return a.App.Id, nil
 }
 
+// DeleteGlobals deletes the given keys from the global state of App.
 func (a *AlgorandMock) DeleteGlobals(acc crypto.Account, appId uint64, keys ...string) error {
+	_, err := a.DeleteGlobalsResult(acc, appId, keys...)
+	return err
+}
+
+// DeleteGlobalsResult is like DeleteGlobals. The returned info response is
+// PendingTXNInfo. Calls are recorded as calls of DeleteGlobals.
+func (a *AlgorandMock) DeleteGlobalsResult(acc crypto.Account, appId uint64, keys ...string) (models.PendingTransactionInfoResponse, error) {
+	a.record((*AlgorandMock).DeleteGlobals, acc, appId, append([]string(nil), keys...))
+	ret, err := a.wrapExecutionCondition(a.PendingTXNInfo, models.PendingTransactionInfoResponse{}, (*AlgorandMock).DeleteGlobals)
+	if err != nil {
+		return ret.(models.PendingTransactionInfoResponse), err
+	}
 	if a.App.Id != appId {
-		return errors.New("incorrect appId provided")
+		return models.PendingTransactionInfoResponse{}, errors.New("incorrect appId provided")
 	}
 	state := a.App.Params.GlobalState
 	for i, _ := range keys {
@@ -210,14 +517,29 @@ func (a *AlgorandMock) DeleteGlobals(acc crypto.Account, appId uint64, keys ...s
 	}
 	a.App.Params.GlobalState = state
 	a.Account.CreatedApps[0] = a.App
-	return nil
+	return ret.(models.PendingTransactionInfoResponse), nil
 }
 
+// StoreGlobals stores the given key-value pairs in the global state of App.
 func (a *AlgorandMock) StoreGlobals(acc crypto.Account, appId uint64, kv []models.TealKeyValue) error {
+	_, err := a.StoreGlobalsResult(acc, appId, kv)
+	return err
+}
+
+// StoreGlobalsResult is like StoreGlobals. The returned info response is
+// PendingTXNInfo. Calls are recorded as calls of StoreGlobals.
+func (a *AlgorandMock) StoreGlobalsResult(acc crypto.Account, appId uint64, kv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	a.record((*AlgorandMock).StoreGlobals, acc, appId, kv)
+	ret, err := a.wrapExecutionCondition(a.PendingTXNInfo, models.PendingTransactionInfoResponse{}, (*AlgorandMock).StoreGlobals)
+	if err != nil {
+		return ret.(models.PendingTransactionInfoResponse), err
+	}
 	if a.App.Id != appId {
-		return errors.New("incorrect appId provided")
+		return models.PendingTransactionInfoResponse{}, errors.New("incorrect appId provided")
 	}
-	// Encode with base64 like reference implementation of Algorand sdk
+	// Encode with base64 like reference implementation of Algorand sdk. The
+	// arguments are copied, so that the caller's slice isn't modified.
+	kv = append([]models.TealKeyValue(nil), kv...)
 	for i, _ := range kv {
 		kv[i].Key = base64.StdEncoding.EncodeToString([]byte(kv[i].Key))
 		kv[i].Value.Bytes = base64.StdEncoding.EncodeToString([]byte(kv[i].Value.Bytes))
@@ -240,5 +562,5 @@ func (a *AlgorandMock) StoreGlobals(acc crypto.Account, appId uint64, kv []model
 	}
 	a.App.Params.GlobalState = state
 	a.Account.CreatedApps[0] = a.App
-	return nil
+	return ret.(models.PendingTransactionInfoResponse), nil
 }
@@ -5,10 +5,12 @@ package client
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/crypto"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -65,6 +67,89 @@ func TestAlgorandMock_ErrorFunctions(t *testing.T) {
 	}
 }
 
+func TestAlgorandMock_QueueResponses(t *testing.T) {
+	client := CreateAlgorandClientMock("", "")
+	client.NodeStatus = models.NodeStatus{LastRound: 1}
+	fail := errors.New("scripted")
+	client.QueueResponses((*AlgorandMock).Status, models.NodeStatus{LastRound: 5}, fail, models.NodeStatus{LastRound: 6})
+
+	status, err := client.Status(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 5, status.LastRound)
+	_, err = client.Status(context.Background())
+	assert.ErrorIs(t, err, fail)
+
+	// injected errors don't consume queued responses
+	client.SetError(true, (*AlgorandMock).Status)
+	_, err = client.Status(context.Background())
+	assert.NotNil(t, err)
+	client.ClearFunctionErrors()
+
+	status, _ = client.Status(context.Background())
+	assert.EqualValues(t, 6, status.LastRound)
+	// falls back to the field once the queue is empty
+	status, _ = client.Status(context.Background())
+	assert.EqualValues(t, 1, status.LastRound)
+
+	// queues are per method
+	client.QueueResponse((*AlgorandMock).StatusAfterBlock, models.NodeStatus{LastRound: 9})
+	status, _ = client.Status(context.Background())
+	assert.EqualValues(t, 1, status.LastRound)
+	client.ClearResponses()
+	status, _ = client.StatusAfterBlock(2, context.Background())
+	assert.EqualValues(t, 1, status.LastRound)
+}
+
+func TestAlgorandMock_QueuePendingTransactionInformation(t *testing.T) {
+	client := CreateAlgorandClientMock("", "")
+	client.PendingTXNInfo = models.PendingTransactionInfoResponse{ConfirmedRound: 3}
+	client.QueueResponse((*AlgorandMock).PendingTransactionInformation, models.PendingTransactionInfoResponse{PoolError: "overspend"})
+
+	info, _, err := client.PendingTransactionInformation("txid", context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "overspend", info.PoolError)
+	info, _, _ = client.PendingTransactionInformation("txid", context.Background())
+	assert.EqualValues(t, 3, info.ConfirmedRound)
+}
+
+func TestAlgorandMock_Latency(t *testing.T) {
+	client := CreateAlgorandClientMock("", "")
+	client.Latency = 20 * time.Millisecond
+
+	start := time.Now()
+	assert.Nil(t, client.HealthCheck(context.Background()))
+	assert.True(t, time.Since(start) >= client.Latency)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := client.Status(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAlgorandMock_SimulateRounds(t *testing.T) {
+	client := CreateAlgorandClientMock("", "")
+	client.NodeStatus = models.NodeStatus{LastRound: 10}
+
+	// rounds don't advance by default
+	status, _ := client.StatusAfterBlock(12, context.Background())
+	assert.EqualValues(t, 10, status.LastRound)
+
+	client.SimulateRounds = true
+	status, _ = client.StatusAfterBlock(12, context.Background())
+	assert.EqualValues(t, 13, status.LastRound)
+	status, _ = client.Status(context.Background())
+	assert.EqualValues(t, 13, status.LastRound)
+	// past rounds return right away
+	status, _ = client.StatusAfterBlock(5, context.Background())
+	assert.EqualValues(t, 13, status.LastRound)
+
+	client.BlockTime = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := client.StatusAfterBlock(13, ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
 // Make sure the store function updates, and creates new only when not exceeding
 // the limit defined by the application schema
 func TestAlgorandMock_StoreGlobalSemantics(t *testing.T) {
@@ -110,3 +195,25 @@ func TestAlgorandMock_StoreGlobalSemantics(t *testing.T) {
 		assertEqualBase64(t, x.Value.Bytes, "dummy2")
 	}
 }
+
+func TestAlgorandMock_AccountInformationExcluding(t *testing.T) {
+	client := CreateAlgorandClientMock("", "")
+	client.CreateDummyApps(2, 5)
+	client.Account.Assets = []models.AssetHolding{{AssetId: 1}}
+
+	acc, err := client.AccountInformationExcluding("", []string{ExcludeNone}, context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, acc.Assets, 1)
+	assert.Len(t, acc.CreatedApps, 2)
+
+	// like algod, all fields or none are excluded
+	acc, err = client.AccountInformationExcluding("", []string{ExcludeAll}, context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, acc.Assets, 0)
+	assert.Len(t, acc.CreatedApps, 0)
+	assert.Len(t, client.Account.Assets, 1)
+	assert.Equal(t, []string{ExcludeAll}, client.AccountExclude)
+
+	_, err = client.AccountInformationExcluding("", []string{"assets"}, context.Background())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// ErrSimulationRejected is returned by DryrunError, if a simulated transaction would
+// be rejected.
+var ErrSimulationRejected = errors.New("transaction rejected in simulation")
+
+// dryrunReject is the app call message of a dryrun, if the program rejected the
+// transaction.
+const dryrunReject = "REJECT"
+
+// DryrunError returns ErrSimulationRejected with the messages of the programs, if the
+// dryrun failed or a program of a transaction rejected it. Returns nil if all
+// transactions are approved.
+func DryrunError(resp models.DryrunResponse) error {
+	if resp.Error != "" {
+		return fmt.Errorf("%w: %s", ErrSimulationRejected, resp.Error)
+	}
+	for i, txn := range resp.Txns {
+		messages := make([]string, 0, len(txn.LogicSigMessages)+len(txn.AppCallMessages))
+		messages = append(append(messages, txn.LogicSigMessages...), txn.AppCallMessages...)
+		for _, msg := range messages {
+			if msg == dryrunReject {
+				return fmt.Errorf("%w: transaction %d: %s", ErrSimulationRejected, i, strings.Join(messages, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+func (a *AlgorandClientWrapper) SimulateTransaction(_ crypto.Account, txn types.Transaction, ctx context.Context) (response models.DryrunResponse, err error) {
+	err = a.request(ctx, func(ctx context.Context) error {
+		// the dryrun doesn't verify signatures
+		request, err := future.CreateDryrun(a.Client, []types.SignedTxn{{Txn: txn}}, nil, ctx)
+		if err != nil {
+			return err
+		}
+		response, err = a.Client.TealDryrun(request).Do(ctx)
+		return err
+	})
+	return response, err
+}
+
+// SimulateTransaction evaluates the transaction like SendRawTransaction, but reverts
+// its effects. Rejections are reported in the response, like by the dryrun endpoint of
+// a node.
+func (l *FakeLedger) SimulateTransaction(_ crypto.Account, txn types.Transaction, _ context.Context) (models.DryrunResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	accounts, apps := l.snapshot()
+	nextAppId := l.nextAppId
+	info, err := l.apply(txn)
+	l.accounts, l.apps, l.nextAppId = accounts, apps, nextAppId
+
+	result := models.DryrunTxnResult{AppCallMessages: []string{"PASS"}, GlobalDelta: info.GlobalStateDelta}
+	if err != nil {
+		result = models.DryrunTxnResult{AppCallMessages: []string{dryrunReject, err.Error()}}
+	}
+	return models.DryrunResponse{ProtocolVersion: FakeLedgerVersion, Txns: []models.DryrunTxnResult{result}}, nil
+}
+
+// SimulateTransaction returns the DryrunResponse field. If it has no transactions,
+// every transaction is approved.
+func (a *AlgorandMock) SimulateTransaction(acc crypto.Account, txn types.Transaction, _ context.Context) (models.DryrunResponse, error) {
+	a.record((*AlgorandMock).SimulateTransaction, acc, txn)
+	resp := a.DryrunResponse
+	if len(resp.Txns) == 0 && resp.Error == "" {
+		resp.Txns = []models.DryrunTxnResult{{AppCallMessages: []string{"PASS"}}}
+	}
+	ret, err := a.wrapExecutionCondition(resp, models.DryrunResponse{}, (*AlgorandMock).SimulateTransaction)
+	return ret.(models.DryrunResponse), err
+}
@@ -0,0 +1,95 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryrunError(t *testing.T) {
+	pass := models.DryrunTxnResult{AppCallMessages: []string{"PASS"}}
+	reject := models.DryrunTxnResult{AppCallMessages: []string{"REJECT", "assert failed"}}
+	assert.Nil(t, DryrunError(models.DryrunResponse{Txns: []models.DryrunTxnResult{pass}}))
+
+	err := DryrunError(models.DryrunResponse{Txns: []models.DryrunTxnResult{pass, reject}})
+	assert.ErrorIs(t, err, ErrSimulationRejected)
+	assert.Contains(t, err.Error(), "transaction 1: REJECT, assert failed")
+
+	err = DryrunError(models.DryrunResponse{Error: "no such app"})
+	assert.ErrorIs(t, err, ErrSimulationRejected)
+
+	lsig := models.DryrunTxnResult{LogicSigMessages: []string{"REJECT"}}
+	assert.ErrorIs(t, DryrunError(models.DryrunResponse{Txns: []models.DryrunTxnResult{lsig}}), ErrSimulationRejected)
+}
+
+// Simulated transactions don't change the ledger.
+func TestFakeLedger_SimulateTransaction(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	appId, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+	balance := l.Balance(acc.Address)
+
+	tkv := []models.TealKeyValue{{Key: "a", Value: models.TealValue{Bytes: "1"}}}
+	txn, err := StoreGlobalsTx(l, acc, appId, tkv)
+	assert.Nil(t, err)
+	resp, err := l.SimulateTransaction(acc, txn, context.Background())
+	assert.Nil(t, err)
+	assert.Nil(t, DryrunError(resp))
+	assert.Len(t, resp.Txns[0].GlobalDelta, 1)
+
+	assert.Equal(t, balance, l.Balance(acc.Address))
+	state, err := ReadGlobalState(l, appId, context.Background())
+	assert.Nil(t, err)
+	assert.Empty(t, state)
+
+	// only the creator may write
+	txn, err = StoreGlobalsTx(l, crypto.GenerateAccount(), appId, tkv)
+	assert.Nil(t, err)
+	resp, err = l.SimulateTransaction(acc, txn, context.Background())
+	assert.Nil(t, err)
+	assert.ErrorIs(t, DryrunError(resp), ErrSimulationRejected)
+}
+
+func TestAlgorandClientWrapper_SimulateTransaction(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	var request models.DryrunRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v2/teal/dryrun":
+			assert.Nil(t, msgpack.NewDecoder(r.Body).Decode(&request))
+			_ = json.NewEncoder(w).Encode(models.DryrunResponse{Txns: []models.DryrunTxnResult{{AppCallMessages: []string{"REJECT"}}}})
+		case r.URL.Path == "/v2/applications/6":
+			_ = json.NewEncoder(w).Encode(models.Application{Id: 6, Params: models.ApplicationParams{Creator: acc.Address.String()}})
+		case strings.HasPrefix(r.URL.Path, "/v2/accounts/"):
+			_ = json.NewEncoder(w).Encode(models.Account{Address: strings.TrimPrefix(r.URL.Path, "/v2/accounts/")})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	c, err := CreateAlgorandClientWrapper(server.URL, "")
+	assert.Nil(t, err)
+
+	txn, err := GenerateApplicationCallTx(6, acc, types.SuggestedParams{Fee: MinTxnFee, FlatFee: true, FirstRoundValid: 1, LastRoundValid: 10}, types.NoOpOC)
+	assert.Nil(t, err)
+	resp, err := c.SimulateTransaction(acc, txn, context.Background())
+	assert.Nil(t, err)
+	assert.ErrorIs(t, DryrunError(resp), ErrSimulationRejected)
+	assert.Len(t, request.Txns, 1)
+	assert.Equal(t, txn.Sender, request.Txns[0].Txn.Sender)
+	assert.Len(t, request.Apps, 1)
+}
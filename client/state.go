@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+)
+
+// ReadGlobalState returns the global state of the application with raw keys and
+// byte values. Use it to read the state of an app without creating an AlgorandBuffer.
+func ReadGlobalState(c AlgorandClient, appId uint64, ctx context.Context) (map[string][]byte, error) {
+	app, err := c.GetApplicationByID(appId, ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string][]byte)
+	for _, kv := range app.Params.GlobalState {
+		decodedKey, _ := base64.StdEncoding.DecodeString(kv.Key)
+		decodedVal, _ := base64.StdEncoding.DecodeString(kv.Value.Bytes)
+		m[string(decodedKey)] = decodedVal
+	}
+	return m, nil
+}
+
+// DeleteGlobalsByPrefix deletes all keys of the global state of the application that
+// start with prefix, e.g. "price/" for "price/BTC" and "price/ETH". The keys are
+// deleted in batches of up to MaxArgs keys, one transaction per batch. Returns the
+// number of deleted keys, which is zero if no key matches. If a batch fails, the
+// batches before it remain deleted, and their keys are counted.
+func DeleteGlobalsByPrefix(a AlgorandClient, acc crypto.Account, appId uint64, prefix string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
+	state, err := ReadGlobalState(a, appId, ctx)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0)
+	for k := range state {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	deleted := 0
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > MaxArgs {
+			n = MaxArgs
+		}
+		batch := append([]string(nil), keys[:n]...)
+		if err := a.DeleteGlobals(acc, appId, batch...); err != nil {
+			return deleted, err
+		}
+		deleted += n
+		keys = keys[n:]
+	}
+	return deleted, nil
+}
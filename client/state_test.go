@@ -0,0 +1,71 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// Keys under the prefix are deleted in batches of MaxArgs, other keys are kept
+func TestDeleteGlobalsByPrefix(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	id, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		tkv := make([]models.TealKeyValue, 0, MaxKVArgs)
+		for j := 0; j < MaxKVArgs; j++ {
+			key := fmt.Sprintf("price/%d", i*MaxKVArgs+j)
+			tkv = append(tkv, models.TealKeyValue{Key: key, Value: models.TealValue{Bytes: "1"}})
+		}
+		err = l.StoreGlobals(acc, id, tkv)
+		assert.Nil(t, err)
+	}
+	err = l.StoreGlobals(acc, id, []models.TealKeyValue{{Key: "meta/updated", Value: models.TealValue{Bytes: "1"}}})
+	assert.Nil(t, err)
+	before, _ := l.AccountInformation(acc.Address.String(), context.Background())
+
+	n, err := DeleteGlobalsByPrefix(l, acc, id, "price/")
+	assert.Nil(t, err)
+	assert.Equal(t, 3*MaxKVArgs, n)
+	state, err := ReadGlobalState(l, id, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]byte{"meta/updated": []byte("1")}, state)
+	after, _ := l.AccountInformation(acc.Address.String(), context.Background())
+	assert.EqualValues(t, 2*MinTxnFee, before.Amount-after.Amount)
+
+	// no matching keys is no error, and submits nothing
+	n, err = DeleteGlobalsByPrefix(l, acc, id, "price/")
+	assert.Nil(t, err)
+	assert.Zero(t, n)
+	before, _ = l.AccountInformation(acc.Address.String(), context.Background())
+	assert.Equal(t, after.Amount, before.Amount)
+}
+
+func TestDeleteGlobalsByPrefix_Error(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.CreateDummyApps(6)
+	m.App = m.Account.CreatedApps[0]
+	acc := crypto.GenerateAccount()
+	err := m.StoreGlobals(acc, 6, []models.TealKeyValue{{Key: "price/BTC"}, {Key: "meta/updated"}})
+	assert.Nil(t, err)
+
+	m.SetError(true, (*AlgorandMock).DeleteGlobals)
+	n, err := DeleteGlobalsByPrefix(m, acc, 6, "price/")
+	assert.NotNil(t, err)
+	assert.Zero(t, n)
+
+	m.ClearFunctionErrors()
+	n, err = DeleteGlobalsByPrefix(m, acc, 6, "price/")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, m.App.Params.GlobalState, 1)
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// SubmitHook is called with every transaction before it's signed and submitted. If it
+// returns an error, the transaction isn't submitted and the error is returned instead.
+type SubmitHook func(txn types.Transaction) error
+
+// hookedClient calls a SubmitHook before executing transactions of the wrapped client.
+type hookedClient struct {
+	AlgorandClient
+	hook SubmitHook
+}
+
+// WithSubmitHook returns an AlgorandClient that calls hook with every transaction
+// before it's executed by c. The application management methods (e.g. StoreGlobals)
+// build their transactions like the other implementations, and execute them through
+// c.ExecuteTransaction, so the hook sees the exact transactions that are submitted.
+// Use it for logging or a policy engine that can veto transactions.
+func WithSubmitHook(c AlgorandClient, hook SubmitHook) AlgorandClient {
+	return &hookedClient{AlgorandClient: c, hook: hook}
+}
+
+func (h *hookedClient) ExecuteTransaction(acc crypto.Account, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	if err := h.hook(txn); err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	return h.AlgorandClient.ExecuteTransaction(acc, txn, ctx)
+}
+
+func (h *hookedClient) ExecuteGroup(acc crypto.Account, txns []types.Transaction, ctx context.Context) ([]models.PendingTransactionInfoResponse, error) {
+	for _, txn := range txns {
+		if err := h.hook(txn); err != nil {
+			return nil, err
+		}
+	}
+	return h.AlgorandClient.ExecuteGroup(acc, txns, ctx)
+}
+
+func (h *hookedClient) AccountInformationExcluding(addr string, exclude []string, ctx context.Context) (models.Account, error) {
+	return AccountInformationExcluding(h.AlgorandClient, addr, exclude, ctx)
+}
+
+func (h *hookedClient) DeleteApplication(acc crypto.Account, appId uint64) error {
+	return deleteApplication(h, acc, appId)
+}
+
+func (h *hookedClient) CreateApplication(acc crypto.Account, approve string, clear string) (uint64, error) {
+	return createApplication(h, acc, approve, clear)
+}
+
+func (h *hookedClient) DeleteGlobals(acc crypto.Account, appId uint64, keys ...string) error {
+	_, err := deleteGlobals(h, acc, appId, keys...)
+	return err
+}
+
+func (h *hookedClient) DeleteGlobalsResult(acc crypto.Account, appId uint64, keys ...string) (models.PendingTransactionInfoResponse, error) {
+	return deleteGlobals(h, acc, appId, keys...)
+}
+
+func (h *hookedClient) StoreGlobals(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) error {
+	_, err := storeGlobals(h, acc, appId, tkv)
+	return err
+}
+
+func (h *hookedClient) StoreGlobalsResult(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	return storeGlobals(h, acc, appId, tkv)
+}
@@ -0,0 +1,53 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// The hook sees every transaction, and vetoed transactions never reach the client
+func TestWithSubmitHook(t *testing.T) {
+	m := CreateAlgorandClientMock("", "")
+	m.CreateDummyApps(6)
+	m.App = m.Account.CreatedApps[0]
+	acc := crypto.GenerateAccount()
+
+	var seen []types.Transaction
+	veto := false
+	c := WithSubmitHook(m, func(txn types.Transaction) error {
+		seen = append(seen, txn)
+		if veto {
+			return errors.New("vetoed")
+		}
+		return nil
+	})
+
+	kv := []models.TealKeyValue{{Key: "team", Value: models.TealValue{Bytes: "Astralis"}}}
+	err := c.StoreGlobals(acc, 6, kv)
+	assert.Nil(t, err)
+	assert.Len(t, seen, 1)
+	assert.Equal(t, "put", string(seen[0].Note))
+	assert.Equal(t, [][]byte{[]byte("team"), []byte("Astralis")}, seen[0].ApplicationArgs)
+	state, err := ReadGlobalState(m, 6, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "Astralis", string(state["team"]))
+
+	veto = true
+	err = c.DeleteGlobals(acc, 6, "team")
+	assert.EqualError(t, err, "vetoed")
+	assert.Len(t, seen, 2)
+	assert.EqualValues(t, 6, seen[1].ApplicationID)
+	state, _ = ReadGlobalState(m, 6, context.Background())
+	assert.Equal(t, "Astralis", string(state["team"]))
+
+	assert.EqualError(t, c.DeleteApplication(acc, 6), "vetoed")
+	assert.Len(t, m.Account.CreatedApps, 1)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+)
+
+// Timeouts are the timeouts of the application management methods of AlgorandClient
+// (e.g. CreateApplication, StoreGlobals). Each one covers the submission of the
+// transaction and the wait for its confirmation. Zero fields keep the default.
+type Timeouts struct {
+	// Create is the timeout of app creations. Defaults to twice the
+	// AlgorandDefaultTimeout.
+	Create time.Duration
+
+	// Delete is the timeout of app deletions. Defaults to twice the
+	// AlgorandDefaultTimeout.
+	Delete time.Duration
+
+	// Store is the timeout of writes to the global state (e.g. StoreGlobals,
+	// DeleteGlobals and StoreGlobalsGroup). Defaults to AlgorandDefaultTimeout.
+	Store time.Duration
+}
+
+func (t Timeouts) create() time.Duration {
+	return orDefault(t.Create, AlgorandDefaultTimeout*2)
+}
+
+func (t Timeouts) delete() time.Duration {
+	return orDefault(t.Delete, AlgorandDefaultTimeout*2)
+}
+
+func (t Timeouts) store() time.Duration {
+	return orDefault(t.Store, AlgorandDefaultTimeout)
+}
+
+func orDefault(d time.Duration, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// timedClient applies custom Timeouts to the application management methods of the
+// wrapped client.
+type timedClient struct {
+	AlgorandClient
+	timeouts Timeouts
+}
+
+// WithTimeouts returns an AlgorandClient that applies t to the application management
+// methods of c. Like WithSubmitHook, they build their transactions like the other
+// implementations, and execute them through c.ExecuteTransaction. The helpers of this
+// package (e.g. CreateApplicationWithSchema) use t as well, if they're passed the
+// returned client. Wrap clients with WithSubmitHook before WithTimeouts, not after.
+func WithTimeouts(c AlgorandClient, t Timeouts) AlgorandClient {
+	return &timedClient{AlgorandClient: c, timeouts: t}
+}
+
+// timeoutsOf returns the Timeouts of a client returned by WithTimeouts, and the
+// default Timeouts for all other clients.
+func timeoutsOf(a AlgorandClient) Timeouts {
+	if t, ok := a.(*timedClient); ok {
+		return t.timeouts
+	}
+	return Timeouts{}
+}
+
+func (t *timedClient) AccountInformationExcluding(addr string, exclude []string, ctx context.Context) (models.Account, error) {
+	return AccountInformationExcluding(t.AlgorandClient, addr, exclude, ctx)
+}
+
+func (t *timedClient) DeleteApplication(acc crypto.Account, appId uint64) error {
+	return deleteApplication(t, acc, appId)
+}
+
+func (t *timedClient) CreateApplication(acc crypto.Account, approve string, clear string) (uint64, error) {
+	return createApplication(t, acc, approve, clear)
+}
+
+func (t *timedClient) DeleteGlobals(acc crypto.Account, appId uint64, keys ...string) error {
+	_, err := deleteGlobals(t, acc, appId, keys...)
+	return err
+}
+
+func (t *timedClient) DeleteGlobalsResult(acc crypto.Account, appId uint64, keys ...string) (models.PendingTransactionInfoResponse, error) {
+	return deleteGlobals(t, acc, appId, keys...)
+}
+
+func (t *timedClient) StoreGlobals(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) error {
+	_, err := storeGlobals(t, acc, appId, tkv)
+	return err
+}
+
+func (t *timedClient) StoreGlobalsResult(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	return storeGlobals(t, acc, appId, tkv)
+}
@@ -0,0 +1,71 @@
+//go:build unit
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// latentMock takes latency to execute a transaction, unless the context is done
+// before.
+type latentMock struct {
+	*AlgorandMock
+	latency time.Duration
+}
+
+func (m *latentMock) ExecuteTransaction(acc crypto.Account, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	if err := sleepContext(ctx, m.latency); err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	return m.AlgorandMock.ExecuteTransaction(acc, txn, ctx)
+}
+
+func (m *latentMock) ExecuteGroup(acc crypto.Account, txns []types.Transaction, ctx context.Context) ([]models.PendingTransactionInfoResponse, error) {
+	if err := sleepContext(ctx, m.latency); err != nil {
+		return nil, err
+	}
+	return m.AlgorandMock.ExecuteGroup(acc, txns, ctx)
+}
+
+func TestWithTimeouts(t *testing.T) {
+	m := &latentMock{AlgorandMock: CreateAlgorandClientMock("", ""), latency: 50 * time.Millisecond}
+	m.CreateDummyApps(6)
+	c := WithTimeouts(m, Timeouts{Create: time.Second, Store: time.Millisecond, Delete: time.Millisecond})
+	acc := crypto.GenerateAccount()
+
+	appId, err := c.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+
+	tkv := []models.TealKeyValue{{Key: "a", Value: models.TealValue{Bytes: "1"}}}
+	err = c.StoreGlobals(acc, appId, tkv)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	err = c.DeleteGlobals(acc, appId, "a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = StoreGlobalsWithLease(c, acc, appId, tkv, [32]byte{1})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = StoreGlobalsGroup(c, acc, appId, [][]models.TealKeyValue{tkv})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, c.DeleteApplication(acc, appId), context.DeadlineExceeded)
+}
+
+// Clients that aren't wrapped keep the default timeouts.
+func TestWithTimeouts_Defaults(t *testing.T) {
+	m := &latentMock{AlgorandMock: CreateAlgorandClientMock("", ""), latency: time.Millisecond}
+	m.CreateDummyApps(6)
+	m.App = m.Account.CreatedApps[0]
+	acc := crypto.GenerateAccount()
+	_, err := StoreGlobalsWithLease(m, acc, 6, []models.TealKeyValue{{Key: "a", Value: models.TealValue{Bytes: "1"}}}, [32]byte{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, Timeouts{}, timeoutsOf(m))
+	assert.Equal(t, 2*AlgorandDefaultTimeout, Timeouts{}.create())
+	assert.Equal(t, AlgorandDefaultTimeout, Timeouts{}.store())
+	assert.Equal(t, time.Second, Timeouts{Store: time.Second}.store())
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// The functions in this file implement the application management methods of
+// AlgorandClient on top of its basic node methods. Implementations of the interface
+// share them, so that they build and submit the same transactions.
+
+func deleteApplication(a AlgorandClient, acc crypto.Account, appId uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
+	params, err := a.SuggestedParams(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	txn, _ := future.MakeApplicationDeleteTx(appId, nil, nil, nil, nil,
+		params, acc.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
+
+	ctx, cancel = context.WithTimeout(context.Background(), timeoutsOf(a).delete())
+	_, err = a.ExecuteTransaction(acc, txn, ctx)
+	cancel()
+	return err
+}
+
+func createApplication(a AlgorandClient, acc crypto.Account, approve string, clear string) (uint64, error) {
+	return CreateApplicationWithSchema(a, acc, approve, clear, DefaultSchema)
+}
+
+// CreateApplicationWithSchema creates a new application with given teal code, like
+// AlgorandClient.CreateApplication, but with the state schema of spec instead of the
+// DefaultSchema.
+func CreateApplicationWithSchema(a AlgorandClient, acc crypto.Account, approve string, clear string, spec SchemaSpec) (uint64, error) {
+	appr, err := CompileProgramE(a, []byte(approve))
+	if err != nil {
+		return 0, fmt.Errorf("approval program: %w", err)
+	}
+	clr, err := CompileProgramE(a, []byte(clear))
+	if err != nil {
+		return 0, fmt.Errorf("clear program: %w", err)
+	}
+	localSchema, globalSchema := spec.Schemas()
+	return CreateApplicationCompiled(a, acc, appr, clr, globalSchema, localSchema)
+}
+
+// CreateApplicationCompiled creates a new application from compiled programs, like
+// CreateApplicationWithSchema, but without compiling them with the node. Use it for
+// programs compiled ahead of time, to pin the exact program instead of depending on
+// the compiler version of the node.
+func CreateApplicationCompiled(a AlgorandClient, acc crypto.Account, approval []byte, clear []byte, global types.StateSchema, local types.StateSchema) (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
+	params, err := a.SuggestedParams(ctx)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	txn, _ := future.MakeApplicationCreateTx(false, approval, clear, global, local,
+		nil, nil, nil, nil, params, acc.Address, nil,
+		types.Digest{}, [32]byte{}, types.Address{})
+
+	ctx, cancel = context.WithTimeout(context.Background(), timeoutsOf(a).create())
+	result, err := a.ExecuteTransaction(acc, txn, ctx)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+	return result.ApplicationIndex, nil
+}
+
+func deleteGlobals(a AlgorandClient, acc crypto.Account, appId uint64, args ...string) (models.PendingTransactionInfoResponse, error) {
+	// convert args from []string to [][]byte
+	convArg := make([][]byte, len(args))
+	for i, x := range args {
+		convArg[i] = []byte(x)
+	}
+	return postArgumentsToApp(a, acc, appId, "delete", convArg, [32]byte{})
+}
+
+func storeGlobals(a AlgorandClient, acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	return StoreGlobalsWithLease(a, acc, appId, tkv, [32]byte{})
+}
+
+// StoreGlobalsWithLease stores the given key-value pairs like AlgorandClient.StoreGlobals,
+// but sets the lease field of the transaction. The network rejects a transaction, if
+// another transaction of the same sender with the same lease is still valid. Use it to
+// make sure that only one of several competing writes is confirmed.
+func StoreGlobalsWithLease(a AlgorandClient, acc crypto.Account, appId uint64, tkv []models.TealKeyValue, lease [32]byte) (models.PendingTransactionInfoResponse, error) {
+	return postArgumentsToApp(a, acc, appId, "put", kvArguments(tkv), lease)
+}
+
+// StoreGlobalsTx returns the transaction that StoreGlobals would submit to store the
+// given key-value pairs, without submitting it. Use it with SimulateTransaction.
+func StoreGlobalsTx(a AlgorandClient, acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (types.Transaction, error) {
+	return appArgumentsTx(a, acc, appId, "put", kvArguments(tkv), [32]byte{})
+}
+
+// kvArguments converts TEAL kv pairs to the [][]byte arguments of a put call.
+func kvArguments(tkv []models.TealKeyValue) [][]byte {
+	args := make([][]byte, len(tkv)*2)
+	for i, kv := range tkv {
+		args[i*2] = []byte(kv.Key)
+		args[i*2+1] = []byte(kv.Value.Bytes)
+	}
+	return args
+}
+
+// postArgumentsToApp creates and publishes a No-Op transaction with given arguments
+// to the application. A note is also added to the transaction. The note determines
+// how the Arguments of the No-Op call get interpreted. You can distill note options
+// from the approval.teal contract. If lease isn't zero, it's set as the lease of the
+// transaction.
+func postArgumentsToApp(a AlgorandClient, acc crypto.Account, appId uint64, note string, args [][]byte, lease [32]byte) (models.PendingTransactionInfoResponse, error) {
+	txn, err := appArgumentsTx(a, acc, appId, note, args, lease)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutsOf(a).store())
+	result, err := a.ExecuteTransaction(acc, txn, ctx)
+	cancel()
+	return result, err
+}
+
+// appArgumentsTx creates the No-Op transaction of postArgumentsToApp.
+func appArgumentsTx(a AlgorandClient, acc crypto.Account, appId uint64, note string, args [][]byte, lease [32]byte) (types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
+	params, err := a.SuggestedParams(ctx)
+	cancel()
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("error getting suggested tx params: %w", err)
+	}
+	txn, _ := future.MakeApplicationNoOpTx(appId, args,
+		nil, nil, nil, params, acc.Address, []byte(note), types.Digest{}, lease, types.Address{})
+	return txn, nil
+}
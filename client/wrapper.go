@@ -7,29 +7,79 @@ import (
 	"github.com/algorand/go-algorand-sdk/client/v2/common"
 	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/crypto"
-	"github.com/algorand/go-algorand-sdk/future"
 	"github.com/algorand/go-algorand-sdk/types"
+	"time"
 )
 
 // AlgorandClientWrapper implements the AlgorandClient interface by wrapping the original
 // algod.Client
 type AlgorandClientWrapper struct {
 	Client *algod.Client
+
+	// Signer signs all transactions of this client, if set. By default, transactions
+	// are signed with the private key of the crypto.Account passed to the call. Use
+	// it for keys that aren't kept in memory, like a LedgerSigner.
+	Signer AccountSigner
+
+	// Rebroadcast replaces transactions that are stuck in the transaction pool, if
+	// set. By default, ExecuteTransaction gives up after waiting 5 rounds.
+	Rebroadcast *RebroadcastPolicy
+
+	// RateLimit retries requests that the node rejects with HTTP 429, if set. By
+	// default, they fail right away with a RateLimitError.
+	RateLimit *RateLimitPolicy
+
+	// OnConfirmationTimeout decides if a transaction that hasn't been confirmed within
+	// 5 rounds is resubmitted. Defaults to ResubmitUnseenOnce.
+	OnConfirmationTimeout ConfirmationTimeoutHook
+
+	// ConfirmationBackoff determines the delays between polls for the confirmation of
+	// a transaction, if set. By default, the node is polled once per round.
+	ConfirmationBackoff *BackoffPolicy
+
+	// Logger receives the log messages of the client, e.g. failed compilations. By
+	// default, they're discarded.
+	Logger Logger
+
+	// sleep waits before retrying rate-limited requests. Replaced in tests.
+	sleep func(context.Context, time.Duration) error
+
+	// endpoint is used for requests that the algod client doesn't support
+	endpoint *nodeEndpoint
 }
 
+// DefaultAuthHeader is the header in which algod expects the API token.
+const DefaultAuthHeader = "X-Algo-API-Token"
+
 func CreateAlgorandClientWrapper(URL string, token string) (*AlgorandClientWrapper, error) {
 	c, err := algod.MakeClient(URL, token)
-	return &AlgorandClientWrapper{Client: c}, err
+	return &AlgorandClientWrapper{Client: c, endpoint: &nodeEndpoint{url: URL, token: token}}, err
+}
+
+// NewClientWithAuthHeader creates an algod client that sends the token in the header with
+// the given name, instead of DefaultAuthHeader. Use it for hosted node providers that
+// expect an API key, like "X-API-Key" for PureStake and Nodely. All requests of the
+// client use the header, including the health and status checks of a buffer. If header
+// is empty, DefaultAuthHeader is used.
+func NewClientWithAuthHeader(URL string, header string, token string) (*AlgorandClientWrapper, error) {
+	if header == "" {
+		header = DefaultAuthHeader
+	}
+	c, err := common.MakeClient(URL, header, token)
+	return &AlgorandClientWrapper{Client: (*algod.Client)(c), endpoint: &nodeEndpoint{url: URL, authHeader: header, token: token}}, err
 }
 
 // NewClientWithHeaders creates an algod client with a given set of headers. Use it if you're
 // connecting to Node providers that use custom header keys like PureStake
 func NewClientWithHeaders(URL string, token string, headers []*common.Header) (*AlgorandClientWrapper, error) {
 	c, err := algod.MakeClientWithHeaders(URL, token, headers)
-	return &AlgorandClientWrapper{Client: c}, err
+	return &AlgorandClientWrapper{Client: c, endpoint: &nodeEndpoint{url: URL, token: token, headers: headers}}, err
 }
-func (a *AlgorandClientWrapper) SuggestedParams(ctx context.Context) (types.SuggestedParams, error) {
-	params, err := a.Client.SuggestedParams().Do(ctx)
+func (a *AlgorandClientWrapper) SuggestedParams(ctx context.Context) (params types.SuggestedParams, err error) {
+	err = a.request(ctx, func(ctx context.Context) error {
+		params, err = a.Client.SuggestedParams().Do(ctx)
+		return err
+	})
 	if err == nil {
 		params.FlatFee = true
 		params.Fee = 1000
@@ -38,132 +88,168 @@ func (a *AlgorandClientWrapper) SuggestedParams(ctx context.Context) (types.Sugg
 }
 
 func (a *AlgorandClientWrapper) HealthCheck(ctx context.Context) error {
-	return a.Client.HealthCheck().Do(ctx)
+	return a.request(ctx, func(ctx context.Context) error {
+		return a.Client.HealthCheck().Do(ctx)
+	})
 }
 
-func (a *AlgorandClientWrapper) Status(ctx context.Context) (models.NodeStatus, error) {
-	return a.Client.Status().Do(ctx)
+func (a *AlgorandClientWrapper) Status(ctx context.Context) (response models.NodeStatus, err error) {
+	err = a.request(ctx, func(ctx context.Context) error {
+		response, err = a.Client.Status().Do(ctx)
+		return err
+	})
+	return response, err
 }
 
 func (a *AlgorandClientWrapper) StatusAfterBlock(round uint64, ctx context.Context) (response models.NodeStatus, err error) {
-	return a.Client.StatusAfterBlock(round).Do(ctx)
+	err = a.request(ctx, func(ctx context.Context) error {
+		response, err = a.Client.StatusAfterBlock(round).Do(ctx)
+		return err
+	})
+	return response, err
+}
+
+func (a *AlgorandClientWrapper) AccountInformation(s string, ctx context.Context) (response models.Account, err error) {
+	err = a.request(ctx, func(ctx context.Context) error {
+		response, err = a.Client.AccountInformation(s).Do(ctx)
+		return err
+	})
+	return response, err
+}
+
+// accountInformationParams are the query parameters of an account information request
+type accountInformationParams struct {
+	Exclude string `url:"exclude,omitempty"`
+}
+
+func (a *AlgorandClientWrapper) AccountInformationExcluding(s string, exclude []string, ctx context.Context) (response models.Account, err error) {
+	param, err := excludeParam(exclude)
+	if err != nil {
+		return response, err
+	}
+	params := accountInformationParams{Exclude: param}
+	err = a.request(ctx, func(ctx context.Context) error {
+		return (*common.Client)(a.Client).Get(ctx, &response, fmt.Sprintf("/v2/accounts/%s", s), params, nil)
+	})
+	return response, err
 }
 
-func (a *AlgorandClientWrapper) AccountInformation(s string, ctx context.Context) (models.Account, error) {
-	return a.Client.AccountInformation(s).Do(ctx)
+func (a *AlgorandClientWrapper) GetApplicationByID(id uint64, ctx context.Context) (response models.Application, err error) {
+	err = a.request(ctx, func(ctx context.Context) error {
+		response, err = a.Client.GetApplicationByID(id).Do(ctx)
+		return err
+	})
+	return response, err
 }
 
-func (a *AlgorandClientWrapper) GetApplicationByID(id uint64, ctx context.Context) (models.Application, error) {
-	return a.Client.GetApplicationByID(id).Do(ctx)
+func (a *AlgorandClientWrapper) SendRawTransaction(txn []byte, ctx context.Context) (txID string, err error) {
+	err = a.request(ctx, func(ctx context.Context) error {
+		txID, err = a.Client.SendRawTransaction(txn).Do(ctx)
+		return err
+	})
+	return txID, poolFullError(err)
 }
 
-func (a *AlgorandClientWrapper) SendRawTransaction(txn []byte, ctx context.Context) (string, error) {
-	return a.Client.SendRawTransaction(txn).Do(ctx)
+func (a *AlgorandClientWrapper) PendingTransactionInformation(txid string, ctx context.Context) (response models.PendingTransactionInfoResponse, stxn types.SignedTxn, err error) {
+	err = a.request(ctx, func(ctx context.Context) error {
+		response, stxn, err = a.Client.PendingTransactionInformation(txid).Do(ctx)
+		return err
+	})
+	return response, stxn, err
 }
 
-func (a *AlgorandClientWrapper) PendingTransactionInformation(txid string, ctx context.Context) (models.PendingTransactionInfoResponse, types.SignedTxn, error) {
-	return a.Client.PendingTransactionInformation(txid).Do(ctx)
+func (a *AlgorandClientWrapper) PendingTransactionsByAddress(addr string, max uint64, ctx context.Context) (txns []types.SignedTxn, err error) {
+	err = a.request(ctx, func(ctx context.Context) error {
+		_, txns, err = a.Client.PendingTransactionsByAddress(addr).Max(max).Do(ctx)
+		return err
+	})
+	return txns, err
 }
 
 func (a *AlgorandClientWrapper) TealCompile(b []byte, ctx context.Context) (response models.CompileResponse, err error) {
-	return a.Client.TealCompile(b).Do(ctx)
+	err = a.request(ctx, func(ctx context.Context) error {
+		response, err = a.Client.TealCompile(b).Do(ctx)
+		return err
+	})
+	return response, err
 }
 
-func (a *AlgorandClientWrapper) ExecuteTransaction(acc crypto.Account, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
-	_, signedTxn, err := crypto.SignTransaction(acc.PrivateKey, txn)
-	if err != nil {
-		return models.PendingTransactionInfoResponse{}, err
+// request performs a single request to the node with f, which must send it with the
+// given context. Responses with HTTP 429 are returned as RateLimitError, and retried
+// according to the RateLimit policy and their Retry-After header.
+func (a *AlgorandClientWrapper) request(ctx context.Context, f func(context.Context) error) error {
+	call := func() error {
+		reqCtx, captured := withCapturedHeaders(ctx)
+		err := f(reqCtx)
+		return rateLimitError(err, captured.get())
 	}
-
-	txID, err := a.SendRawTransaction(signedTxn, ctx)
-	if err != nil {
-		return models.PendingTransactionInfoResponse{}, err
+	if a.RateLimit == nil {
+		return call()
 	}
+	sleep := a.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+	return retryRateLimited(ctx, *a.RateLimit, sleep, call)
+}
 
-	_, err = future.WaitForConfirmation(a.Client, txID, 5, ctx)
+func (a *AlgorandClientWrapper) ExecuteTransaction(acc crypto.Account, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	signer := a.Signer
+	if signer == nil {
+		signer = NewKeySigner(acc)
+	}
+	if a.Rebroadcast != nil {
+		return executeWithRebroadcast(a, signer, txn, *a.Rebroadcast, a.OnConfirmationTimeout, a.ConfirmationBackoff, ctx)
+	}
+	signedTxn, err := signer.SignTransaction(txn)
 	if err != nil {
 		return models.PendingTransactionInfoResponse{}, err
 	}
-
-	response, _, err := a.PendingTransactionInformation(txID, ctx)
-	return response, err
+	return a.submitAndConfirm(signedTxn, ctx)
 }
 
-func (a *AlgorandClientWrapper) DeleteApplication(acc crypto.Account, appId uint64) error {
-	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
-	params, err := a.SuggestedParams(ctx)
-	cancel()
-	if err != nil {
-		return err
+// submitAndConfirm submits the signed transaction and waits for its confirmation, with
+// the OnConfirmationTimeout and ConfirmationBackoff of the client.
+func (a *AlgorandClientWrapper) submitAndConfirm(signedTxn []byte, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	hook := a.OnConfirmationTimeout
+	if hook == nil {
+		hook = ResubmitUnseenOnce
 	}
-	txn, _ := future.MakeApplicationDeleteTx(appId, nil, nil, nil, nil,
-		params, acc.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
-
-	ctx, cancel = context.WithTimeout(context.Background(), AlgorandDefaultTimeout*2)
-	_, err = a.ExecuteTransaction(acc, txn, ctx)
-	cancel()
-	return err
+	return submitAndConfirm(a, signedTxn, hook, a.ConfirmationBackoff, ctx)
 }
 
-func (a *AlgorandClientWrapper) CreateApplication(acc crypto.Account, approve string, clear string) (uint64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
-	params, err := a.SuggestedParams(ctx)
-	cancel()
-	if err != nil {
-		return 0, err
+// ExecuteGroup signs the transactions like ExecuteTransaction. Groups aren't
+// rebroadcast or resubmitted if they aren't confirmed in time.
+func (a *AlgorandClientWrapper) ExecuteGroup(acc crypto.Account, txns []types.Transaction, ctx context.Context) ([]models.PendingTransactionInfoResponse, error) {
+	signer := a.Signer
+	if signer == nil {
+		signer = NewKeySigner(acc)
 	}
-	localSchema, globalSchema := GenerateSchemas()
-	appr := CompileProgram(a, []byte(approve))
-	clr := CompileProgram(a, []byte(clear))
+	return executeGroup(a, signer, txns, ctx)
+}
 
-	txn, _ := future.MakeApplicationCreateTx(false, appr, clr, globalSchema, localSchema,
-		nil, nil, nil, nil, params, acc.Address, nil,
-		types.Digest{}, [32]byte{}, types.Address{})
+func (a *AlgorandClientWrapper) DeleteApplication(acc crypto.Account, appId uint64) error {
+	return deleteApplication(a, acc, appId)
+}
 
-	ctx, cancel = context.WithTimeout(context.Background(), AlgorandDefaultTimeout*2)
-	result, err := a.ExecuteTransaction(acc, txn, ctx)
-	cancel()
-	if err != nil {
-		return 0, err
-	}
-	return result.ApplicationIndex, nil
+func (a *AlgorandClientWrapper) CreateApplication(acc crypto.Account, approve string, clear string) (uint64, error) {
+	return createApplication(a, acc, approve, clear)
 }
 
 func (a *AlgorandClientWrapper) DeleteGlobals(acc crypto.Account, appId uint64, args ...string) error {
-	// convert args from []string to [][]byte
-	convArg := make([][]byte, len(args))
-	for i, x := range args {
-		convArg[i] = []byte(x)
-	}
-	return a.postArgumentsToApp(acc, appId, "delete", convArg)
+	_, err := deleteGlobals(a, acc, appId, args...)
+	return err
 }
 
-func (a *AlgorandClientWrapper) StoreGlobals(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) error {
-	// convert TEAL kv pair to [][]byte arguments
-	args := make([][]byte, len(tkv)*2)
-	for i, kv := range tkv {
-		args[i*2] = []byte(kv.Key)
-		args[i*2+1] = []byte(kv.Value.Bytes)
-	}
-	return a.postArgumentsToApp(acc, appId, "put", args)
+func (a *AlgorandClientWrapper) DeleteGlobalsResult(acc crypto.Account, appId uint64, args ...string) (models.PendingTransactionInfoResponse, error) {
+	return deleteGlobals(a, acc, appId, args...)
 }
 
-// postArgumentsToApp creates and publishes a No-Op transaction with given arguments
-// to the application. A note is also added to the transaction. The note determines
-// how the Arguments of the No-Op call get interpreted. You can distill note options
-// from the approval.teal contract.
-func (a *AlgorandClientWrapper) postArgumentsToApp(acc crypto.Account, appId uint64, note string, args [][]byte) error {
-	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
-	params, err := a.SuggestedParams(ctx)
-	cancel()
-	if err != nil {
-		return fmt.Errorf("error getting suggested tx params: %s", err)
-	}
-	txn, _ := future.MakeApplicationNoOpTx(appId, args,
-		nil, nil, nil, params, acc.Address, []byte(note), types.Digest{}, [32]byte{}, types.Address{})
-
-	ctx, cancel = context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
-	_, err = a.ExecuteTransaction(acc, txn, ctx)
-	cancel()
+func (a *AlgorandClientWrapper) StoreGlobals(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) error {
+	_, err := storeGlobals(a, acc, appId, tkv)
 	return err
 }
+
+func (a *AlgorandClientWrapper) StoreGlobalsResult(acc crypto.Account, appId uint64, tkv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	return storeGlobals(a, acc, appId, tkv)
+}
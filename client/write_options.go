@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// MaxNoteSize is the maximum length of the note of a transaction in bytes.
+const MaxNoteSize = 1024
+
+// WriteOptions are optional fields of the transactions of a write (see
+// StoreGlobalsWithOptions). The zero value adds nothing.
+type WriteOptions struct {
+	// Note tags the write, e.g. with a source identifier or batch ID. The note of the
+	// write itself selects the operation of approval.teal ("put"), so the tag is carried
+	// by an application call without arguments, which the contract accepts without
+	// changes. Both calls are submitted in an atomic group, which costs one more
+	// transaction fee. At most MaxNoteSize bytes.
+	Note []byte
+
+	// Lease is set as the lease of the write, if it isn't zero. The network rejects the
+	// write while another transaction of the account with the same lease is valid, so
+	// a write that is retried with the same lease (e.g. after a crash) is confirmed at
+	// most once.
+	Lease [32]byte
+}
+
+// StoreGlobalsWithOptions stores the given key-value pairs like
+// AlgorandClient.StoreGlobals, with the note and lease of opts. Returns the info
+// response of the confirmed write.
+func StoreGlobalsWithOptions(a AlgorandClient, acc crypto.Account, appId uint64, tkv []models.TealKeyValue, opts WriteOptions) (models.PendingTransactionInfoResponse, error) {
+	if len(opts.Note) == 0 {
+		return StoreGlobalsWithLease(a, acc, appId, tkv, opts.Lease)
+	}
+	if len(opts.Note) > MaxNoteSize {
+		return models.PendingTransactionInfoResponse{}, fmt.Errorf("note can't exceed %d bytes, got %d", MaxNoteSize, len(opts.Note))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), AlgorandDefaultTimeout)
+	params, err := a.SuggestedParams(ctx)
+	cancel()
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, fmt.Errorf("error getting suggested tx params: %w", err)
+	}
+	put, err := future.MakeApplicationNoOpTx(appId, kvArguments(tkv),
+		nil, nil, nil, params, acc.Address, []byte("put"), types.Digest{}, opts.Lease, types.Address{})
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	tag, err := future.MakeApplicationNoOpTx(appId, nil,
+		nil, nil, nil, params, acc.Address, opts.Note, types.Digest{}, [32]byte{}, types.Address{})
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), timeoutsOf(a).store())
+	defer cancel()
+	infos, err := a.ExecuteGroup(acc, []types.Transaction{put, tag}, ctx)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	return infos[0], nil
+}
+
+// Txns returns the number of transactions of a write with the options.
+func (o WriteOptions) Txns() int {
+	if len(o.Note) > 0 {
+		return 2
+	}
+	return 1
+}
+
+// ForBatch returns the options for the i-th of several writes that store one batch of
+// pairs each. Every batch needs its own lease, so the lease of batch i > 0 is derived
+// from Lease and i. The first batch uses Lease itself.
+func (o WriteOptions) ForBatch(i int) WriteOptions {
+	if i == 0 || o.Lease == ([32]byte{}) {
+		return o
+	}
+	idx := make([]byte, 8)
+	binary.BigEndian.PutUint64(idx, uint64(i))
+	o.Lease = sha256.Sum256(append(o.Lease[:], idx...))
+	return o
+}
@@ -0,0 +1,66 @@
+//go:build unit
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// The note is carried by a call without arguments, in a group with the write
+func TestStoreGlobalsWithOptions_Note(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	id, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+
+	tkv := []models.TealKeyValue{{Key: "k", Value: models.TealValue{Bytes: "v"}}}
+	opts := WriteOptions{Note: []byte("source=feed-1")}
+	_, err = StoreGlobalsWithOptions(l, acc, id, tkv, opts)
+	assert.Nil(t, err)
+	state, err := ReadGlobalState(l, id, context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "v", string(state["k"]))
+	info, _ := l.AccountInformation(acc.Address.String(), context.Background())
+	assert.EqualValues(t, 10000000-3*MinTxnFee, info.Amount)
+	assert.Equal(t, 2, opts.Txns())
+
+	_, err = StoreGlobalsWithOptions(l, acc, id, tkv, WriteOptions{Note: bytes.Repeat([]byte{1}, MaxNoteSize+1)})
+	assert.NotNil(t, err)
+}
+
+// A write that is retried with the same lease is confirmed only once
+func TestStoreGlobalsWithOptions_Lease(t *testing.T) {
+	l := NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	id, err := l.CreateApplication(acc, ApproveTeal, ClearTeal)
+	assert.Nil(t, err)
+
+	tkv := []models.TealKeyValue{{Key: "k", Value: models.TealValue{Bytes: "v"}}}
+	opts := WriteOptions{Note: []byte("batch-7"), Lease: [32]byte{7}}
+	_, err = StoreGlobalsWithOptions(l, acc, id, tkv, opts)
+	assert.Nil(t, err)
+	_, err = StoreGlobalsWithOptions(l, acc, id, tkv, opts)
+	assert.NotNil(t, err)
+	_, err = StoreGlobalsWithOptions(l, acc, id, tkv, opts.ForBatch(1))
+	assert.Nil(t, err)
+}
+
+func TestWriteOptions_ForBatch(t *testing.T) {
+	opts := WriteOptions{Note: []byte("n"), Lease: [32]byte{1}}
+	assert.Equal(t, opts, opts.ForBatch(0))
+	assert.NotEqual(t, opts.Lease, opts.ForBatch(1).Lease)
+	assert.NotEqual(t, opts.ForBatch(1).Lease, opts.ForBatch(2).Lease)
+	assert.Equal(t, opts.ForBatch(1), opts.ForBatch(1))
+	assert.Equal(t, opts.Note, opts.ForBatch(1).Note)
+
+	// writes without lease stay without lease
+	assert.Equal(t, WriteOptions{}, WriteOptions{}.ForBatch(3))
+}
@@ -0,0 +1,353 @@
+package siam
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+)
+
+// ManageConfig configures how an AlgorandBuffer manages its application and how
+// it submits transactions to the Algorand node. The zero value of ManageConfig
+// results in the same behavior as NewAlgorandBuffer.
+type ManageConfig struct {
+	// FeeBudget caps the total amount of fees the buffer is allowed to spend
+	// within a time window. If nil, fee spending is not limited.
+	FeeBudget *FeeBudget
+
+	// FeeStrategy determines the fees of the transactions of the buffer: the fee
+	// suggested by the node (the default), a flat fee (e.g. zero on a private network),
+	// or a multiple of the suggested fee (e.g. during congestion), capped at
+	// FeeStrategy.Max. The Client of the buffer is wrapped with client.WithFeeStrategy
+	// to achieve this. The FeeBudget is charged with the fees of the strategy.
+	FeeStrategy *client.FeeStrategy
+
+	// ExcludeAccountFields is sent as the exclude values when the buffer requests
+	// information about the target account that doesn't need its created apps: the
+	// auth address check of every cycle of the management loop and the balance check
+	// of Diagnose (see client.AccountInformationExcluding). Set it to client.ExcludeAll
+	// to reduce the payload for accounts that hold many assets. algod only supports
+	// client.ExcludeAll and client.ExcludeNone. Requests that need the created apps
+	// (e.g. the reconciliation) always request the full account.
+	ExcludeAccountFields []string
+
+	// CreateConfirmation determines how long the buffer waits after creating its
+	// application. App creation is rare, so it's reasonable to wait for deeper
+	// finality to be sure that it's durable.
+	CreateConfirmation ConfirmationPolicy
+
+	// StoreConfirmation determines how long writes (see PutElements and DeleteElements)
+	// wait after their transactions are confirmed.
+	StoreConfirmation ConfirmationPolicy
+
+	// PinnedAppId is the app that is kept if the target account owns several valid
+	// apps. If zero (or if the app doesn't exist), the app the buffer currently
+	// publishes to is kept. Initially, this is the app chosen by KeepPolicy.
+	PinnedAppId uint64
+
+	// KeepPolicy determines which app is kept initially, if the target account owns
+	// several valid apps: the one created first (KeepOldest, the default; ties are
+	// broken by the lowest ID), or the one with the lowest ID (KeepLowestID). The state
+	// of the kept app is preserved, the other apps are deleted.
+	KeepPolicy KeepPolicy
+
+	// ConsolidationPolicy determines what happens to the global state of the apps that
+	// are deleted in favour of the kept app (see KeepPolicy): it's deleted with them
+	// (DeleteOnly, the default), or merged into the kept app first. On conflicts, the
+	// kept app wins (MergeKeepSurvivor), or the app created last (MergeKeepNewest).
+	// Apps with an invalid schema are never merged.
+	ConsolidationPolicy ConsolidationPolicy
+
+	// ExtraAppGrace is the duration for which extra valid apps are tolerated before
+	// they're deleted. During a migration there can be two valid apps, and deleting
+	// one of them right away could remove the new one. Apps with an invalid schema are
+	// always deleted immediately.
+	ExtraAppGrace time.Duration
+
+	// KeyEncoding determines how keys passed to PutElements, DeleteElements etc. map to
+	// the keys of the global state. If zero, keys are used as raw bytes.
+	KeyEncoding KeyEncoding
+
+	// ValueEncoding determines how values returned by GetBuffer and passed to PutElements
+	// and PutOrdered map to the byte values of the global state. If zero, values are
+	// used as UTF-8 strings. Raw methods like GetBufferRaw aren't affected.
+	ValueEncoding ValueEncoding
+
+	// Schema is the state schema of the buffer's app. Apps are created with it, and only
+	// apps with its global schema are recognized. The capacity of the buffer is its
+	// number of global byte slices. If zero, client.DefaultSchema is used.
+	Schema client.SchemaSpec
+
+	// AdaptToDeployedSchema accepts apps with any global state schema that holds byte
+	// slices, instead of requiring the schema of client.GenerateSchemas. The capacity of
+	// the buffer is then based on the schema of the deployed app. Use it for forks of the
+	// contract with a different schema. New apps are still created with the default
+	// schema.
+	AdaptToDeployedSchema bool
+
+	// BatchSize is the maximum number of key-value pairs stored per transaction. Each
+	// pair takes two application arguments, so it must be between 1 and
+	// client.MaxKVArgs. Smaller batches lead to smaller transactions, but more of them.
+	// If zero, client.MaxKVArgs is used.
+	BatchSize int
+
+	// GroupSize is the maximum number of transactions in an atomic group (see
+	// PutElementsAtomic). It must be between 1 and client.MaxGroupSize. Larger groups
+	// make larger writes atomic, smaller groups are cheaper to retry. If zero,
+	// client.MaxGroupSize is used.
+	GroupSize int
+
+	// StrictAtomic makes PutElementsAtomic reject writes that don't fit into a single
+	// atomic group (see AtomicKeyLimit) with ErrGroupTooLarge, instead of splitting them
+	// across several groups. Either all pairs of a write are stored, or none.
+	StrictAtomic bool
+
+	// SleepInterval is the time the management loop (see Manage) waits between two
+	// cycles. Use a short interval on private networks with short block times, and a
+	// longer one to back off on MainNet. If zero, client.AlgorandDefaultMinSleep is used.
+	SleepInterval time.Duration
+
+	// HealthTimeout is the timeout of requests to the node, like health checks (see
+	// Health) and status requests. If zero, client.AlgorandDefaultTimeout is used.
+	HealthTimeout time.Duration
+
+	// ReadTimeout is the timeout of reads of the target account and the app, like
+	// GetBuffer. Accounts with many assets or apps with large states may need longer than
+	// a health check. If zero, the HealthTimeout is used.
+	ReadTimeout time.Duration
+
+	// CreateTimeout is the timeout of app creations and deletions, including the wait for
+	// their confirmation. If zero, twice the client.AlgorandDefaultTimeout is used.
+	CreateTimeout time.Duration
+
+	// StoreTimeout is the timeout of writes (e.g. PutElements and DeleteElements),
+	// including the wait for their confirmation. It also applies to other transactions of
+	// the buffer, like rekeys. If zero, client.AlgorandDefaultTimeout is used for writes,
+	// and the HealthTimeout for other transactions.
+	StoreTimeout time.Duration
+
+	// MaxRetries is the number of consecutive failed cycles after which the management
+	// loop (see Manage) gives up and returns, e.g. to let a supervisor restart the
+	// service. The buffer isn't stopped. If zero, failed cycles are retried forever.
+	MaxRetries int
+
+	// PoolFullBackoff is the time the management loop (see Manage) waits after a cycle
+	// failed with client.ErrPoolFull or client.ErrRateLimited (see PoolFull and
+	// RateLimited), instead of the usual SleepInterval. A full transaction pool means the
+	// network is congested, so retrying right away only adds to it. If zero, four times
+	// the SleepInterval is used.
+	PoolFullBackoff time.Duration
+
+	// ClassifyError overrides how the management loop (see Manage) classifies the errors
+	// of failed cycles, which decides how they're retried (see ErrorClass). Use it for
+	// node providers that report conditions with their own messages. Errors for which it
+	// returns Unclassified, and all errors if it's nil, are classified by
+	// DefaultClassifyError.
+	ClassifyError func(err error) ErrorClass
+
+	// ShutdownGrace is the time the management loop (see Manage) keeps flushing the
+	// pairs queued by QueueElements when it shuts down (see Stop), so that buffered
+	// updates aren't lost on deploys. Pairs that haven't been stored by then are
+	// dropped. If zero, queued pairs are dropped on shutdown.
+	ShutdownGrace time.Duration
+
+	// TrackModified tracks the round in which each key was last written (see
+	// LastModified). The round is stored under a companion key (see ModifiedPrefix)
+	// in the same transaction as the key. Every key then takes two of the 64 slots of
+	// the global state, which halves the capacity of the buffer (to 32 keys, minus
+	// reserved keys), and the number of pairs per transaction.
+	TrackModified bool
+
+	// WarnValueRatio is the fraction of the maximum length of a key-value pair (128
+	// bytes) from which stored pairs are reported with ErrNearLimit (e.g. 0.9), without
+	// failing the write. This gives operators time to react before ErrValueTooLong. If
+	// zero, long values aren't reported.
+	WarnValueRatio float64
+
+	// WarnCapacityRatio is the fraction of the capacity of the buffer from which writes
+	// are reported with ErrNearLimit (e.g. 0.9), without failing. It's reported once
+	// when a write crosses it, and again after the buffer dropped below it. Checking it
+	// reads the global state after every write. If zero, the capacity isn't reported.
+	WarnCapacityRatio float64
+
+	// OnConverged is called by the management loop (see Manage) when the target account
+	// reaches a steady valid state: the buffer owns exactly one app with the right
+	// schema, and no writes are pending. It's called once on the first convergence, and
+	// again whenever the account converges after it diverged (e.g. after failing cycles,
+	// or while an extra app exists). Use it to flip a readiness flag.
+	OnConverged func()
+
+	// ContractSpec declares the constraints of the approval program. If set, stored
+	// key-value pairs are validated against it before submission.
+	ContractSpec *ContractSpec
+
+	// ParamsCacheTTL is the duration for which the suggested params of the node are
+	// cached (see AlgorandBuffer.SuggestedParams). If zero, params aren't cached.
+	ParamsCacheTTL time.Duration
+
+	// MinWriteInterval is the minimum time between two writes of the same key. Writes
+	// of a key that has been written more recently are suppressed (see LastSuppressed).
+	// This protects against buggy producers hammering the same keys, which wastes fees.
+	// If zero, all writes are submitted.
+	MinWriteInterval time.Duration
+
+	// AppFilter identifies the buffer's application among the apps of the target
+	// account, if the account also hosts unrelated apps. Apps are only adopted if they
+	// fulfil the schema of the buffer and the filter returns true. Apps that don't match
+	// are left untouched. If nil, all apps with the right schema belong to the buffer,
+	// and all other apps are deleted.
+	AppFilter func(models.Application) bool
+
+	// HeartbeatInterval is the interval in which the management loop (see Manage)
+	// stores the current time and round under HeartbeatKey, to prove liveness. The
+	// heartbeat key is reserved and excluded from GetBuffer, so it leaves one key less
+	// for the user. If zero, no heartbeat is stored.
+	HeartbeatInterval time.Duration
+
+	// TruncateOversized truncates values (at a UTF-8 boundary) that would exceed the
+	// maximum length of a key-value pair, instead of returning ErrValueTooLong. The keys
+	// of truncated values are recorded (see LastTruncated). Use it for lossy-tolerant
+	// data like log messages.
+	TruncateOversized bool
+
+	// LeaseDuration enables a lease that protects against several managers of the same
+	// account fighting each other. The management loop (see Manage) stores its owner ID
+	// and the expiry under LeaseKey, and renews it in time. A manager that finds an
+	// unexpired lease of another owner goes on standby (see AlgorandBuffer.Standby) and
+	// becomes read-only, until the lease expires. The lease key is reserved and excluded
+	// from GetBuffer. If zero, no lease is used.
+	LeaseDuration time.Duration
+
+	// LeaseOwner identifies the manager in the lease. If empty, a random ID is used.
+	LeaseOwner string
+
+	// ExpectedSigner is the auth address the target account is expected to have, if it
+	// has been rekeyed before the buffer was created. The management loop (see Manage)
+	// reports other auth addresses with ErrUnexpectedRekey. If zero, the account is
+	// expected to sign for itself.
+	ExpectedSigner types.Address
+
+	// Indexer is queried for the history that algod doesn't keep, like deleted apps
+	// (see AppHistory and CreationInfo). If nil, only the current state of algod is
+	// available.
+	Indexer client.IndexerClient
+
+	// Boxes declares the boxes the buffer's app uses. If AutoFundBoxes is set, the
+	// account of the app is funded to cover their minimum balance (see FundBoxes).
+	Boxes []BoxSpec
+
+	// AutoFundBoxes makes the management loop (see Manage) fund the account of the app,
+	// whenever its balance doesn't cover the minimum balance of the Boxes. Box writes
+	// fail otherwise.
+	AutoFundBoxes bool
+
+	// BoxTreasury pays for the funding of the app account (see FundBoxes). If nil, the
+	// target account pays.
+	BoxTreasury *crypto.Account
+
+	// OptedInAccounts are accounts that may be opted into apps of the buffer, e.g. with
+	// forks of the contract that use local state. Before an app is deleted, their local
+	// state is removed according to LocalStatePolicy, so that their minimum balance is
+	// reclaimed. The accounts sign for themselves.
+	OptedInAccounts []crypto.Account
+
+	// LocalStatePolicy determines how the local state of OptedInAccounts is removed
+	// (ClearLocalState, the default, or CloseOutOrReport).
+	LocalStatePolicy LocalStatePolicy
+
+	// EmptyValuePolicy determines whether writes of empty values store an empty value
+	// (StoreEmpty, the default) or delete the key (DeleteKey).
+	EmptyValuePolicy EmptyValuePolicy
+
+	// EvictionPolicy determines what happens if a write would exceed the capacity of the
+	// buffer: it's rejected with ErrBufferFull (EvictNone, the default), or the oldest
+	// keys are deleted to make room (EvictFIFO, EvictLRU). Evicted keys are deleted
+	// right before the pairs are stored, e.g. in the same cycle of the management loop.
+	// The order of keys is kept in memory, so after a restart, keys that haven't been
+	// written again are evicted first.
+	EvictionPolicy EvictionPolicy
+
+	// EvictTouchReads makes reads of single keys (e.g. GetBytes, GetInt) count as use
+	// for EvictLRU.
+	EvictTouchReads bool
+
+	// Metrics receives the metrics of the buffer as they change, like the cycles of the
+	// management loop and the transactions sent (see Metrics). Use it to adapt them to
+	// a monitoring library. The same metrics can be exposed without it (see
+	// WritePrometheus). If nil, metrics are only kept by the buffer.
+	Metrics Metrics
+
+	// Logger receives the log messages of the buffer, like the problems it reports on
+	// AppChannel. Messages of the client are logged to the logger of the client (see
+	// client.AlgorandClientWrapper.Logger). If nil, messages are discarded.
+	Logger client.Logger
+
+	// OnBeforeSubmit is called with every transaction of the buffer (e.g. app creation,
+	// stores and deletes) before it's signed and submitted. If it returns an error, the
+	// transaction is aborted and the operation returns the error. Use it to log the
+	// decoded transactions, or to let a human or a policy engine veto them. The Client
+	// of the buffer is wrapped with client.WithSubmitHook to achieve this.
+	OnBeforeSubmit func(tx types.Transaction) error
+}
+
+// validate returns an error if a field of the config is out of range.
+func (cfg ManageConfig) validate() error {
+	if cfg.BatchSize < 0 || cfg.BatchSize > client.MaxKVArgs {
+		return fmt.Errorf("batch size must be between 1 and %d, got %d", client.MaxKVArgs, cfg.BatchSize)
+	}
+	if cfg.MaxRetries < 0 {
+		return fmt.Errorf("max retries must not be negative, got %d", cfg.MaxRetries)
+	}
+	if cfg.Schema != (client.SchemaSpec{}) {
+		if err := cfg.Schema.Validate(); err != nil {
+			return err
+		}
+	}
+	if cfg.TrackModified && cfg.BatchSize == 1 {
+		return errors.New("batch size must be at least 2 to track last-modified rounds")
+	}
+	for _, ratio := range []float64{cfg.WarnValueRatio, cfg.WarnCapacityRatio} {
+		if ratio < 0 || ratio > 1 {
+			return fmt.Errorf("warning ratios must be between 0 and 1, got %g", ratio)
+		}
+	}
+	if cfg.GroupSize < 0 || cfg.GroupSize > client.MaxGroupSize {
+		return fmt.Errorf("group size must be between 1 and %d, got %d", client.MaxGroupSize, cfg.GroupSize)
+	}
+	if cfg.FeeStrategy != nil {
+		if err := cfg.FeeStrategy.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, field := range cfg.ExcludeAccountFields {
+		if field != client.ExcludeAll && field != client.ExcludeNone {
+			return fmt.Errorf("excluded account fields must be %q or %q, got %q", client.ExcludeAll, client.ExcludeNone, field)
+		}
+	}
+	return nil
+}
+
+// batchSize returns the number of key-value pairs stored per transaction.
+func (ab *AlgorandBuffer) batchSize() int {
+	size := ab.config.BatchSize
+	if size == 0 {
+		size = client.MaxKVArgs
+	}
+	if ab.config.TrackModified {
+		// every pair is stored together with its companion key
+		return size / 2
+	}
+	return size
+}
+
+// groupSize returns the maximum number of transactions in an atomic group.
+func (ab *AlgorandBuffer) groupSize() int {
+	if ab.config.GroupSize == 0 {
+		return client.MaxGroupSize
+	}
+	return ab.config.GroupSize
+}
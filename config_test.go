@@ -0,0 +1,76 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// A batch size of 4 splits a write of 10 keys into 3 transactions.
+func TestAlgorandBuffer_BatchSize(t *testing.T) {
+	c := &storeRecordingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{BatchSize: 4})
+	assert.Nil(t, err)
+
+	data := make(map[string]string)
+	pairs := make([]KV, 0)
+	for i := 0; i < 10; i++ {
+		data[strconv.Itoa(i)] = "v"
+		pairs = append(pairs, KV{Key: strconv.Itoa(i), Value: "w"})
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+	assert.Len(t, c.stored, 3)
+	sizes := []int{len(c.stored[0]), len(c.stored[1]), len(c.stored[2])}
+	assert.ElementsMatch(t, []int{4, 4, 2}, sizes)
+
+	c.stored = nil
+	assert.Nil(t, buffer.PutOrdered(context.Background(), pairs))
+	assert.Len(t, c.stored, 3)
+	assert.Len(t, c.stored[0], 4)
+	assert.Len(t, c.stored[2], 2)
+}
+
+func TestManageConfig_BatchSizeOutOfRange(t *testing.T) {
+	for _, size := range []int{-1, client.MaxKVArgs + 1} {
+		c := client.CreateAlgorandClientMock("", "")
+		_, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{BatchSize: size})
+		assert.NotNil(t, err)
+	}
+}
+
+func TestManageConfig_GroupSizeOutOfRange(t *testing.T) {
+	for _, size := range []int{-1, client.MaxGroupSize + 1} {
+		c := client.CreateAlgorandClientMock("", "")
+		_, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{GroupSize: size})
+		assert.NotNil(t, err)
+	}
+}
+
+func TestManageConfig_WarnRatioOutOfRange(t *testing.T) {
+	for _, cfg := range []ManageConfig{{WarnValueRatio: -0.1}, {WarnCapacityRatio: 1.5}} {
+		c := client.CreateAlgorandClientMock("", "")
+		_, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+		assert.NotNil(t, err)
+	}
+}
+
+// Values that algod doesn't support are rejected.
+func TestManageConfig_ExcludeAccountFields(t *testing.T) {
+	for _, field := range []string{"created-apps", "assets"} {
+		c := client.CreateAlgorandClientMock("", "")
+		_, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ExcludeAccountFields: []string{field}})
+		assert.NotNil(t, err, field)
+	}
+	for _, field := range []string{client.ExcludeAll, client.ExcludeNone} {
+		c := client.CreateAlgorandClientMock("", "")
+		_, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ExcludeAccountFields: []string{field}})
+		assert.Nil(t, err, field)
+	}
+}
@@ -0,0 +1,58 @@
+package siam
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// ConfirmationPolicy determines when a confirmed transaction is considered durable.
+// The zero value completes an operation as soon as its transaction is confirmed.
+type ConfirmationPolicy struct {
+	// FinalityRounds is the number of rounds that have to pass after the round in
+	// which a transaction was confirmed, before the operation completes.
+	FinalityRounds uint64
+}
+
+// awaitFinality blocks until the given policy is satisfied for a transaction that
+// was confirmed in round confirmedRound. If confirmedRound is unknown (zero), the
+// last round of the node is used instead.
+func (ab *AlgorandBuffer) awaitFinality(ctx context.Context, policy ConfirmationPolicy, confirmedRound uint64) error {
+	if policy.FinalityRounds == 0 {
+		return nil
+	}
+	tctx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
+	status, err := ab.Client.Status(tctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	if confirmedRound == 0 {
+		confirmedRound = status.LastRound
+	}
+
+	round := status.LastRound
+	for round < confirmedRound+policy.FinalityRounds {
+		tctx, cancel = context.WithTimeout(ctx, ab.timeoutLength)
+		status, err = ab.Client.StatusAfterBlock(round, tctx)
+		cancel()
+		if err != nil {
+			return err
+		}
+		// StatusAfterBlock only returns after the given round has been passed
+		round++
+		if status.LastRound > round {
+			round = status.LastRound
+		}
+	}
+	return nil
+}
+
+// awaitStoreFinality waits until the last transaction of a write satisfies the
+// StoreConfirmation policy.
+func (ab *AlgorandBuffer) awaitStoreFinality(ctx context.Context, results []models.PendingTransactionInfoResponse) error {
+	if len(results) == 0 {
+		return nil
+	}
+	return ab.awaitFinality(ctx, ab.config.StoreConfirmation, results[len(results)-1].ConfirmedRound)
+}
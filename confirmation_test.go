@@ -0,0 +1,56 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// statusCountingMock counts the calls to StatusAfterBlock
+type statusCountingMock struct {
+	*client.AlgorandMock
+	statusAfterBlock int
+}
+
+func (c *statusCountingMock) StatusAfterBlock(round uint64, ctx context.Context) (models.NodeStatus, error) {
+	c.statusAfterBlock++
+	return c.AlgorandMock.StatusAfterBlock(round, ctx)
+}
+
+// Creation waits for finality, while stores return on first confirmation
+func TestAlgorandBuffer_CreateConfirmation(t *testing.T) {
+	c := &statusCountingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.NodeStatus.LastRound = 100
+	cfg := ManageConfig{CreateConfirmation: ConfirmationPolicy{FinalityRounds: 4}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, c.statusAfterBlock)
+
+	c.statusAfterBlock = 0
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"x": "y"}))
+	assert.Nil(t, buffer.DeleteElements(context.Background(), "x"))
+	assert.Equal(t, 0, c.statusAfterBlock)
+}
+
+func TestAlgorandBuffer_StoreConfirmation(t *testing.T) {
+	c := &statusCountingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.NodeStatus.LastRound = 100
+	c.PendingTXNInfo.ConfirmedRound = 99
+	cfg := ManageConfig{StoreConfirmation: ConfirmationPolicy{FinalityRounds: 3}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, c.statusAfterBlock)
+
+	// confirmed in round 99, node is at round 100: two more rounds to go
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"x": "y"}))
+	assert.Equal(t, 2, c.statusAfterBlock)
+
+	// errors while waiting are returned
+	c.SetError(true, (*client.AlgorandMock).StatusAfterBlock)
+	assert.NotNil(t, buffer.PutElements(context.Background(), map[string]string{"x": "y"}))
+}
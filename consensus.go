@@ -0,0 +1,20 @@
+package siam
+
+import (
+	"context"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// RequireBoxes returns an error of type BoxesUnsupported, if the node runs a consensus
+// version that doesn't support box storage.
+func (ab *AlgorandBuffer) RequireBoxes(ctx context.Context) error {
+	version, err := client.ConsensusVersion(ab.Client, ctx)
+	if err != nil {
+		return err
+	}
+	if !client.SupportsBoxes(version) {
+		return &BoxesUnsupported{Version: version}
+	}
+	return nil
+}
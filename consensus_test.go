@@ -0,0 +1,27 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// Box features must be rejected with a clear error on nodes running older protocols.
+func TestAlgorandBuffer_RequireBoxes(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	c.NodeStatus.LastVersion = "https://github.com/algorandfoundation/specs/tree/bc36005dbd776e6d1eaf0c560619bb183215645c"
+	err = buffer.RequireBoxes(context.Background())
+	var unsupported *BoxesUnsupported
+	assert.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, c.NodeStatus.LastVersion, unsupported.Version)
+
+	c.NodeStatus.LastVersion = client.ConsensusFuture
+	assert.Nil(t, buffer.RequireBoxes(context.Background()))
+}
@@ -0,0 +1,114 @@
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// ConsolidationPolicy determines what happens to the global state of extra valid apps,
+// before they're deleted (see ManageConfig.ConsolidationPolicy).
+type ConsolidationPolicy int
+
+const (
+	// DeleteOnly deletes extra apps together with their state.
+	DeleteOnly ConsolidationPolicy = iota
+
+	// MergeKeepSurvivor copies the keys of extra apps into the kept app, unless the
+	// kept app already stores them.
+	MergeKeepSurvivor
+
+	// MergeKeepNewest copies the keys of extra apps into the kept app. If both store a
+	// key, the value of the app created last wins.
+	MergeKeepNewest
+)
+
+// consolidation tracks the state merged into the kept app during manageDeletion. For
+// every key, it records the app the current value originates from.
+type consolidation struct {
+	survivor models.Application
+	origin   map[string]models.Application
+}
+
+// newConsolidation starts a consolidation into the given app.
+func (ab *AlgorandBuffer) newConsolidation(survivor models.Application) *consolidation {
+	c := &consolidation{survivor: survivor, origin: make(map[string]models.Application)}
+	for key := range ab.appState(survivor) {
+		c.origin[key] = survivor
+	}
+	return c
+}
+
+// consolidate writes the global state of app into the kept app, according to
+// ManageConfig.ConsolidationPolicy. Reserved keys and integer values aren't copied.
+// Nothing is deleted from the kept app, so the merged keys must fit into its schema,
+// otherwise the node rejects them and the extra app isn't deleted.
+func (ab *AlgorandBuffer) consolidate(ctx context.Context, c *consolidation, app models.Application) error {
+	data := make(map[string][]byte)
+	for key, value := range ab.appState(app) {
+		if prev, ok := c.origin[key]; ok {
+			if ab.config.ConsolidationPolicy == MergeKeepSurvivor || !createdAfter(app, prev) {
+				continue
+			}
+		}
+		data[key] = value
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	partitions := partitionMapByte(data, ab.batchSize())
+	batches := make([][]models.TealKeyValue, 0, len(partitions))
+	for _, p := range partitions {
+		kvArray := make([]models.TealKeyValue, 0, len(p))
+		for k, v := range p {
+			kvArray = append(kvArray, models.TealKeyValue{Key: k, Value: models.TealValue{Bytes: string(v)}})
+		}
+		batches = append(batches, kvArray)
+	}
+	if err := ab.spendTxnFees(ctx, writeTxnSizes(batches, nil)...); err != nil {
+		return err
+	}
+	for i, kvArray := range batches {
+		if err := ab.Client.StoreGlobals(ab.AccountCrypt, c.survivor.Id, kvArray); err != nil {
+			return ab.observeSubmitError(err)
+		}
+		for k := range partitions[i] {
+			c.origin[k] = app
+		}
+	}
+	return nil
+}
+
+// appState returns the byte values of the global state of app, with global state keys,
+// excluding reserved keys.
+func (ab *AlgorandBuffer) appState(app models.Application) map[string][]byte {
+	state := make(map[string][]byte)
+	for _, kv := range app.Params.GlobalState {
+		if kv.Value.Type == tealUintType {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil || ab.isReservedKey(string(key)) {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(kv.Value.Bytes)
+		if err != nil {
+			continue
+		}
+		state[string(key)] = value
+	}
+	return state
+}
+
+// tealUintType is the type of integer values in the global state.
+const tealUintType = 2
+
+// createdAfter returns true if app a was created after app b. Apps created in the same
+// round are ordered by ID.
+func createdAfter(a, b models.Application) bool {
+	if a.CreatedAtRound != b.CreatedAtRound {
+		return a.CreatedAtRound > b.CreatedAtRound
+	}
+	return a.Id > b.Id
+}
@@ -0,0 +1,74 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func kvs(data map[string]string) []models.TealKeyValue {
+	tkv := make([]models.TealKeyValue, 0, len(data))
+	for k, v := range data {
+		tkv = append(tkv, models.TealKeyValue{Key: k, Value: models.TealValue{Bytes: v}})
+	}
+	return tkv
+}
+
+// newDuplicateApps returns a buffer whose account owns a second valid app, created
+// after the app of the buffer
+func newDuplicateApps(t *testing.T, policy ConsolidationPolicy) (*AlgorandBuffer, *client.FakeLedger, uint64) {
+	buffer, l := newFakeLedgerBuffer(t)
+	buffer.config.ConsolidationPolicy = policy
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "survivor", "b": "survivor"}))
+	extra, err := l.CreateApplication(buffer.AccountCrypt, client.ApproveTeal, client.ClearTeal)
+	assert.Nil(t, err)
+	err = l.StoreGlobals(buffer.AccountCrypt, extra, kvs(map[string]string{"b": "extra", "c": "extra"}))
+	assert.Nil(t, err)
+	return buffer, l, extra
+}
+
+func TestAlgorandBuffer_ConsolidationPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy ConsolidationPolicy
+		want   map[string]string
+	}{
+		{"delete only", DeleteOnly, map[string]string{"a": "survivor", "b": "survivor"}},
+		{"keep survivor", MergeKeepSurvivor, map[string]string{"a": "survivor", "b": "survivor", "c": "extra"}},
+		{"keep newest", MergeKeepNewest, map[string]string{"a": "survivor", "b": "extra", "c": "extra"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer, l, extra := newDuplicateApps(t, tc.policy)
+			kept := buffer.AppId
+			assert.Nil(t, buffer.ReconcileOnce(context.Background()))
+			assert.Equal(t, kept, buffer.AppId)
+
+			_, err := l.GetApplicationByID(extra, context.Background())
+			assert.NotNil(t, err, "the extra app is deleted")
+			d, err := buffer.GetBuffer(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, d)
+		})
+	}
+}
+
+// If merging fails, the extra app isn't deleted, so its state isn't lost
+func TestAlgorandBuffer_ConsolidationFailure(t *testing.T) {
+	buffer, l, extra := newDuplicateApps(t, MergeKeepSurvivor)
+	buffer.Client = client.WithSubmitHook(l, func(txn types.Transaction) error {
+		if txn.ApplicationID == types.AppIndex(buffer.AppId) {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	assert.NotNil(t, buffer.ReconcileOnce(context.Background()))
+	_, err := l.GetApplicationByID(extra, context.Background())
+	assert.Nil(t, err)
+}
@@ -0,0 +1,54 @@
+package siam
+
+import (
+	"fmt"
+)
+
+// ContractSpec declares the constraints that the approval program enforces on the
+// stored key-value pairs. Pairs are validated against the spec before they're
+// submitted, so violations are caught early with a clear error, instead of a
+// rejected transaction. Constraints apply to the keys of the global state (see
+// ManageConfig.KeyEncoding).
+type ContractSpec struct {
+	// AllowedKeys are the only keys that may be stored. If empty, all keys are allowed.
+	AllowedKeys []string
+
+	// MaxKeyLength is the maximum length of a key in bytes. Zero means no limit.
+	MaxKeyLength int
+
+	// MaxValueLength is the maximum length of a value in bytes. Zero means no limit.
+	MaxValueLength int
+}
+
+// ContractViolation is returned by PutElements (and similar functions), if a
+// key-value pair violates the ContractSpec of the buffer. Nothing is submitted in
+// this case.
+type ContractViolation struct {
+	Key    string
+	Reason string
+}
+
+func (e *ContractViolation) Error() string {
+	return fmt.Sprintf("key violates contract spec {%s}: %s", e.Key, e.Reason)
+}
+
+// validate returns a ContractViolation, if the key-value pair violates the spec.
+func (s *ContractSpec) validate(key, value string) error {
+	if s.MaxKeyLength > 0 && len(key) > s.MaxKeyLength {
+		return &ContractViolation{Key: key,
+			Reason: fmt.Sprintf("key exceeds %d bytes", s.MaxKeyLength)}
+	}
+	if s.MaxValueLength > 0 && len(value) > s.MaxValueLength {
+		return &ContractViolation{Key: key,
+			Reason: fmt.Sprintf("value exceeds %d bytes", s.MaxValueLength)}
+	}
+	if len(s.AllowedKeys) == 0 {
+		return nil
+	}
+	for _, allowed := range s.AllowedKeys {
+		if key == allowed {
+			return nil
+		}
+	}
+	return &ContractViolation{Key: key, Reason: "key is not allowed"}
+}
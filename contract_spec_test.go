@@ -0,0 +1,43 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_ContractSpec(t *testing.T) {
+	c := &storeRecordingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	spec := &ContractSpec{AllowedKeys: []string{"price", "volume"}, MaxValueLength: 8}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ContractSpec: spec})
+	assert.Nil(t, err)
+
+	var violation *ContractViolation
+	err = buffer.PutElements(context.Background(), map[string]string{"price": "1", "other": "2"})
+	assert.ErrorAs(t, err, &violation)
+	assert.Equal(t, "other", violation.Key)
+
+	err = buffer.PutOrdered(context.Background(), []KV{{Key: "volume", Value: strings.Repeat("9", 9)}})
+	assert.ErrorAs(t, err, &violation)
+	assert.Equal(t, "volume", violation.Key)
+
+	// violations are caught before submission
+	assert.Len(t, c.stored, 0)
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"price": "1", "volume": "12345678"}))
+	assert.Len(t, c.stored, 1)
+}
+
+func TestContractSpec_MaxKeyLength(t *testing.T) {
+	spec := ContractSpec{MaxKeyLength: 3}
+	assert.Nil(t, spec.validate("abc", "value"))
+	assert.NotNil(t, spec.validate("abcd", "value"))
+	assert.Nil(t, (&ContractSpec{}).validate(strings.Repeat("k", 64), strings.Repeat("v", 64)))
+}
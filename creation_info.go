@@ -0,0 +1,86 @@
+package siam
+
+import (
+	"context"
+)
+
+// CreationInfo describes when and by whom the buffer's application was created.
+type CreationInfo struct {
+	// AppId is the ID of the application.
+	AppId uint64
+
+	// Creator is the address of the account that created the application.
+	Creator string
+
+	// CreatedAtRound is the round in which the application was created.
+	CreatedAtRound uint64
+
+	// TxID is the ID of the transaction that created the application. It's looked up
+	// with the ManageConfig.Indexer, because algod only keeps the current state. It's
+	// empty without an indexer, or if the indexer doesn't know the transaction.
+	TxID string
+
+	// ApprovalProgram is the compiled approval program of the application.
+	ApprovalProgram []byte
+
+	// ClearStateProgram is the compiled clear state program of the application.
+	ClearStateProgram []byte
+}
+
+// CreationInfo returns details about the creation of the buffer's application. The
+// information is derived from client.AlgorandClient.GetApplicationByID. If algod
+// doesn't know the app (e.g. because it has been deleted) or doesn't report the
+// creation round, the app is looked up with the ManageConfig.Indexer, if configured.
+// Otherwise, the creation round is looked up in the created apps of the target
+// account. The creation transaction is only known to the indexer.
+func (ab *AlgorandBuffer) CreationInfo() (CreationInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ab.readTimeout())
+	app, err := ab.Client.GetApplicationByID(ab.currentAppId(), ctx)
+	cancel()
+	if (err != nil || app.CreatedAtRound == 0) && ab.config.Indexer != nil {
+		ctx, cancel = context.WithTimeout(context.Background(), ab.readTimeout())
+		indexed, indexErr := ab.config.Indexer.LookupApplicationByID(ab.currentAppId(), ctx)
+		cancel()
+		if indexErr == nil {
+			app, err = indexed, nil
+		}
+	}
+	if err != nil {
+		return CreationInfo{}, err
+	}
+
+	info := CreationInfo{
+		AppId:             ab.currentAppId(),
+		Creator:           app.Params.Creator,
+		CreatedAtRound:    app.CreatedAtRound,
+		ApprovalProgram:   app.Params.ApprovalProgram,
+		ClearStateProgram: app.Params.ClearStateProgram,
+	}
+	if ab.config.Indexer != nil {
+		ctx, cancel = context.WithTimeout(context.Background(), ab.readTimeout())
+		txn, indexErr := ab.config.Indexer.LookupApplicationCreation(info.AppId, ctx)
+		cancel()
+		if indexErr == nil {
+			info.TxID = txn.Id
+			if info.CreatedAtRound == 0 {
+				info.CreatedAtRound = txn.ConfirmedRound
+			}
+		}
+	}
+	if info.CreatedAtRound != 0 {
+		return info, nil
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), ab.readTimeout())
+	acc, err := ab.accountInformation(ctx)
+	cancel()
+	if err != nil {
+		return CreationInfo{}, err
+	}
+	for _, a := range acc.CreatedApps {
+		if a.Id == ab.currentAppId() {
+			info.CreatedAtRound = a.CreatedAtRound
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,49 @@
+//go:build unit
+
+package siam
+
+import (
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_CreationInfo(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.App.CreatedAtRound = 1337
+	c.App.Params.Creator = "QVMZNWTLJDQCPB4O2SC2ZPGSSELQ6GZNAUTSW2ORNPTCMLG32AEKAQGXGM"
+	c.App.Params.ApprovalProgram = []byte{5, 32}
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	info, err := buffer.CreationInfo()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 6, info.AppId)
+	assert.EqualValues(t, 1337, info.CreatedAtRound)
+	assert.Equal(t, c.App.Params.Creator, info.Creator)
+	assert.Equal(t, []byte{5, 32}, info.ApprovalProgram)
+	assert.Empty(t, info.TxID)
+}
+
+// If the application response has no creation round, use the round of the
+// account's created app
+func TestAlgorandBuffer_CreationInfoFromAccount(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.Account.CreatedApps[0].CreatedAtRound = 42
+	c.App = c.Account.CreatedApps[0]
+	c.App.CreatedAtRound = 0
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	info, err := buffer.CreationInfo()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 42, info.CreatedAtRound)
+
+	c.SetError(true, (*client.AlgorandMock).GetApplicationByID)
+	_, err = buffer.CreationInfo()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,54 @@
+package siam
+
+import (
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// fulfillsSchema returns true if the app has a schema the buffer can use. By default,
+// this is the schema of ManageConfig.Schema. If ManageConfig.AdaptToDeployedSchema is
+// set, any app with byte slices in its global state is accepted.
+func (ab *AlgorandBuffer) fulfillsSchema(app models.Application) bool {
+	if !ab.config.AdaptToDeployedSchema {
+		return ab.schemaSpec().Fulfills(app)
+	}
+	return app.Id != 0 && app.Params.GlobalStateSchema.NumByteSlice > 0
+}
+
+// observeSchema records the global and local state schema of the app the buffer
+// publishes to.
+func (ab *AlgorandBuffer) observeSchema(app models.Application) {
+	ab.mu.Lock()
+	ab.schema = app.Params.GlobalStateSchema
+	ab.localSchema = app.Params.LocalStateSchema
+	ab.mu.Unlock()
+}
+
+// globalBytes returns the number of byte slices in the global state of the buffer's
+// app. Unless ManageConfig.AdaptToDeployedSchema is set, it's the number of byte slices
+// of ManageConfig.Schema.
+func (ab *AlgorandBuffer) globalBytes() int {
+	if !ab.config.AdaptToDeployedSchema {
+		return int(ab.schemaSpec().GlobalBytes)
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	return int(ab.schema.NumByteSlice)
+}
+
+// schemaSpec returns the schema the buffer creates apps with.
+func (ab *AlgorandBuffer) schemaSpec() client.SchemaSpec {
+	if ab.config.Schema == (client.SchemaSpec{}) {
+		return client.DefaultSchema
+	}
+	return ab.config.Schema
+}
+
+// createApp creates an app with the schema of the buffer. Apps with the DefaultSchema
+// are created with client.AlgorandClient.CreateApplication.
+func (ab *AlgorandBuffer) createApp(approve string, clear string) (uint64, error) {
+	if spec := ab.schemaSpec(); spec != client.DefaultSchema {
+		return client.CreateApplicationWithSchema(ab.Client, ab.AccountCrypt, approve, clear, spec)
+	}
+	return ab.Client.CreateApplication(ab.AccountCrypt, approve, clear)
+}
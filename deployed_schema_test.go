@@ -0,0 +1,57 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// With AdaptToDeployedSchema, an app with a smaller schema is adopted, and the capacity
+// reflects its schema.
+func TestAlgorandBuffer_AdaptToDeployedSchema(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyAppsWithSchema(models.ApplicationStateSchema{NumByteSlice: 16}, 6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{AdaptToDeployedSchema: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 6, buffer.AppId)
+
+	remaining, err := buffer.RemainingCapacity(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 16, remaining)
+
+	tooMany := make(map[string]string)
+	for i := 0; i < 17; i++ {
+		tooMany[strconv.Itoa(i)] = "v"
+	}
+	contains, err := buffer.Contains(context.Background(), tooMany)
+	assert.Nil(t, err)
+	assert.False(t, contains)
+	assert.ErrorIs(t, buffer.Preflight(context.Background(), tooMany), ErrPreflightFailed)
+}
+
+// Without the option, apps with a different schema are replaced.
+func TestAlgorandBuffer_DeployedSchemaIgnored(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyAppsWithSchema(models.ApplicationStateSchema{NumByteSlice: 16}, 6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 4512, buffer.AppId)
+	assert.Equal(t, client.GlobalBytes, buffer.capacity())
+}
+
+// Apps without byte slices can't hold the buffer, even with the option.
+func TestAlgorandBuffer_DeployedSchemaWithoutBytes(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyAppsWithSchema(models.ApplicationStateSchema{NumUint: 16}, 6)
+	c.App = c.Account.CreatedApps[0]
+	_, err := NewReadOnlyBuffer(c, 6, ManageConfig{AdaptToDeployedSchema: true})
+	assert.NotNil(t, err)
+}
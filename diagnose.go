@@ -0,0 +1,123 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// Names of the checks of Diagnose, in the order they run.
+const (
+	CheckNodeReachable     = "node reachable"
+	CheckTokenValid        = "token valid"
+	CheckNodeSynced        = "node synced"
+	CheckAccountFunded     = "account funded"
+	CheckAppPresent        = "app present"
+	CheckCompileTeal       = "can compile TEAL"
+	CheckSufficientBalance = "sufficient balance"
+)
+
+// DiagnosticCheck is the result of a single check of Diagnose.
+type DiagnosticCheck struct {
+	// Name identifies the check (e.g. CheckNodeReachable).
+	Name string
+
+	// Passed is true if the check succeeded.
+	Passed bool
+
+	// Detail describes why the check failed, or is empty if it passed.
+	Detail string
+
+	// Hint suggests how to fix a failed check, or is empty if it passed.
+	Hint string
+}
+
+// DiagnosticReport holds the results of all checks of Diagnose.
+type DiagnosticReport struct {
+	Checks []DiagnosticCheck
+}
+
+// Passed returns true if all checks of the report passed.
+func (r DiagnosticReport) Passed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns the check with the given name, and false if the report doesn't
+// contain it.
+func (r DiagnosticReport) Check(name string) (DiagnosticCheck, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return DiagnosticCheck{}, false
+}
+
+func (r *DiagnosticReport) add(name string, failure error, hint string) {
+	check := DiagnosticCheck{Name: name, Passed: failure == nil}
+	if failure != nil {
+		check.Detail = failure.Error()
+		check.Hint = hint
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// Diagnose checks the setup of the buffer: whether the node is reachable and synced,
+// the token is valid, the target account is funded, the app exists with the schema of
+// the buffer, the node compiles TEAL, and the account can pay for filling the buffer.
+// All checks run, even if earlier ones fail, and the report contains a hint on how to
+// fix each failed check. Read-only buffers (see NewReadOnlyBuffer) skip the checks of
+// the target account.
+func (ab *AlgorandBuffer) Diagnose(ctx context.Context) DiagnosticReport {
+	var report DiagnosticReport
+	report.add(CheckNodeReachable, ab.Health(), "check the URL of the node and that it's running")
+
+	reqCtx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
+	status, err := ab.Client.Status(reqCtx)
+	cancel()
+	report.add(CheckTokenValid, err, "check the API token, and that the URL has no trailing slash")
+
+	if err == nil && status.CatchupTime > 0 {
+		err = fmt.Errorf("node is catching up, last round %d", status.LastRound)
+	}
+	report.add(CheckNodeSynced, err, "wait for the node to catch up, or use fast catchup")
+
+	if !ab.readOnly {
+		reqCtx, cancel = context.WithTimeout(ctx, ab.readTimeout())
+		info, err := ab.accountSummary(reqCtx)
+		cancel()
+		if err == nil && info.Amount < client.MinBalance {
+			err = fmt.Errorf("balance of %d microAlgos is below the minimum of %d", info.Amount, client.MinBalance)
+		}
+		report.add(CheckAccountFunded, err, fmt.Sprintf("send Algos to %s", ab.AccountCrypt.Address))
+	}
+
+	reqCtx, cancel = context.WithTimeout(ctx, ab.readTimeout())
+	app, err := ab.Client.GetApplicationByID(ab.currentAppId(), reqCtx)
+	cancel()
+	if err == nil && (app.Id != ab.currentAppId() || !ab.fulfillsSchema(app)) {
+		err = fmt.Errorf("app %d doesn't exist or doesn't fulfil the schema of the buffer", ab.currentAppId())
+	}
+	report.add(CheckAppPresent, err, "run the management loop (see Manage) to create the app, or check the app ID")
+
+	reqCtx, cancel = context.WithTimeout(ctx, ab.timeoutLength)
+	_, err = ab.Client.TealCompile([]byte(client.ApproveTeal), reqCtx)
+	cancel()
+	report.add(CheckCompileTeal, err, "enable the developer API of the node (EnableDeveloperAPI in config.json)")
+
+	if !ab.readOnly {
+		var failure string
+		failure, err = ab.checkBalance(ctx, batchCount(ab.capacity(), ab.batchSize()))
+		if err == nil && failure != "" {
+			err = fmt.Errorf("filling the buffer: %s", failure)
+		}
+		report.add(CheckSufficientBalance, err, fmt.Sprintf("send more Algos to %s", ab.AccountCrypt.Address))
+	}
+	return report
+}
@@ -0,0 +1,96 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDiagnoseBuffer(t *testing.T) (*AlgorandBuffer, *client.AlgorandMock) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.Account.Amount = 10000000
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	return buffer, c
+}
+
+// assertFailed asserts that exactly the given checks of the report failed.
+func assertFailed(t *testing.T, report DiagnosticReport, failed ...string) {
+	for _, check := range report.Checks {
+		expectFail := false
+		for _, name := range failed {
+			expectFail = expectFail || name == check.Name
+		}
+		assert.Equal(t, !expectFail, check.Passed, check.Name)
+		if expectFail {
+			assert.NotEmpty(t, check.Detail, check.Name)
+			assert.NotEmpty(t, check.Hint, check.Name)
+		}
+	}
+}
+
+func TestAlgorandBuffer_Diagnose(t *testing.T) {
+	buffer, _ := newDiagnoseBuffer(t)
+	report := buffer.Diagnose(context.Background())
+	assert.True(t, report.Passed())
+	assert.Len(t, report.Checks, 7)
+	check, ok := report.Check(CheckAppPresent)
+	assert.True(t, ok)
+	assert.True(t, check.Passed)
+	assert.Empty(t, check.Hint)
+}
+
+func TestAlgorandBuffer_DiagnoseFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		inject func(c *client.AlgorandMock)
+		failed []string
+	}{
+		{"unreachable", func(c *client.AlgorandMock) { c.SetError(true, (*client.AlgorandMock).HealthCheck) },
+			[]string{CheckNodeReachable}},
+		{"bad token", func(c *client.AlgorandMock) { c.SetError(true, (*client.AlgorandMock).Status) },
+			[]string{CheckTokenValid, CheckNodeSynced}},
+		{"catching up", func(c *client.AlgorandMock) { c.NodeStatus.CatchupTime = 1000 },
+			[]string{CheckNodeSynced}},
+		{"unfunded", func(c *client.AlgorandMock) { c.Account.Amount = 1000 },
+			[]string{CheckAccountFunded, CheckSufficientBalance}},
+		{"low balance", func(c *client.AlgorandMock) {
+			c.Account.Amount = client.MinAccountBalance(c.Account.CreatedApps)
+			c.Params.MinFee = 1000
+		},
+			[]string{CheckSufficientBalance}},
+		{"no app", func(c *client.AlgorandMock) { c.App.Id = 7 },
+			[]string{CheckAppPresent}},
+		{"wrong schema", func(c *client.AlgorandMock) { c.App.Params.GlobalStateSchema.NumByteSlice = 1 },
+			[]string{CheckAppPresent}},
+		{"no compile", func(c *client.AlgorandMock) { c.SetError(true, (*client.AlgorandMock).TealCompile) },
+			[]string{CheckCompileTeal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer, c := newDiagnoseBuffer(t)
+			tt.inject(c)
+			report := buffer.Diagnose(context.Background())
+			assert.False(t, report.Passed())
+			assertFailed(t, report, tt.failed...)
+		})
+	}
+}
+
+func TestReadOnlyBuffer_Diagnose(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewReadOnlyBuffer(c, 6, ManageConfig{})
+	assert.Nil(t, err)
+	report := buffer.Diagnose(context.Background())
+	assert.True(t, report.Passed())
+	_, ok := report.Check(CheckAccountFunded)
+	assert.False(t, ok)
+}
@@ -0,0 +1,18 @@
+package siam
+
+import "context"
+
+// ApprovalSource reads the approval program of the deployed app and disassembles it
+// back to TEAL (see client.AlgorandClient.DisassembleProgram). Use it to verify that
+// the deployed program matches the intended one. Comments and labels of the original
+// source are lost during compilation, so compare it to the disassembly of the
+// compiled intended program rather than to its source.
+func (ab *AlgorandBuffer) ApprovalSource() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ab.readTimeout())
+	defer cancel()
+	app, err := ab.Client.GetApplicationByID(ab.currentAppId(), ctx)
+	if err != nil {
+		return "", err
+	}
+	return ab.Client.DisassembleProgram(app.Params.ApprovalProgram, ctx)
+}
@@ -0,0 +1,38 @@
+//go:build unit
+
+package siam
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_ApprovalSource(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	program := []byte{0x04, 0x81, 0x01}
+	c.App.Params.ApprovalProgram = program
+	c.Disassembly = map[string]string{base64.StdEncoding.EncodeToString(program): "#pragma version 4\npushint 1\n"}
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	source, err := buffer.ApprovalSource()
+	assert.Nil(t, err)
+	assert.Equal(t, "#pragma version 4\npushint 1\n", source)
+
+	c.App.Params.ApprovalProgram = []byte{0x05}
+	_, err = buffer.ApprovalSource()
+	assert.NotNil(t, err)
+}
+
+// The FakeLedger "compiles" programs to their source, so the source is returned.
+func TestAlgorandBuffer_ApprovalSourceFakeLedger(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	source, err := buffer.ApprovalSource()
+	assert.Nil(t, err)
+	assert.Equal(t, client.ApproveTeal, source)
+}
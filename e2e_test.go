@@ -0,0 +1,76 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeLedgerBuffer creates an AlgorandBuffer on top of a client.FakeLedger with
+// a funded target account
+func newFakeLedgerBuffer(t *testing.T) (*AlgorandBuffer, *client.FakeLedger) {
+	l := client.NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	buffer, err := NewAlgorandBuffer(l, base64.StdEncoding.EncodeToString(acc.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buffer, l
+}
+
+// Create, store, read and delete through a ledger that applies transactions
+func TestEndToEnd_FakeLedger(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	info, err := l.AccountInformation(buffer.AccountCrypt.Address.String(), context.Background())
+	assert.Nil(t, err)
+	assert.True(t, client.ValidAccount(info))
+	assert.Equal(t, info.CreatedApps[0].Id, buffer.AppId)
+
+	data := map[string]string{
+		"1000": "Astralis",
+		"1001": "Vitality",
+		"1002": "Gambit",
+		"1003": "OG",
+		"1004": "Na'Vi",
+		"1005": "Furia",
+		"1006": "G2",
+		"1007": "Liquid",
+		"1008": "FaZe",
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, d)
+
+	assert.Nil(t, buffer.DeleteElements(context.Background(), "1001", "1003"))
+	d, _ = buffer.GetBuffer(context.Background())
+	assert.Len(t, d, 7)
+	_, ok := d["1001"]
+	assert.False(t, ok)
+
+	assert.Nil(t, buffer.AchieveDesiredState(context.Background(), map[string]string{"1000": "G2"}))
+	d, _ = buffer.GetBuffer(context.Background())
+	assert.Equal(t, map[string]string{"1000": "G2"}, d)
+
+	// fees are paid for every transaction
+	assert.Less(t, l.Balance(buffer.AccountCrypt.Address), uint64(10000000-client.MinTxnFee*4))
+}
+
+// A second buffer for the same account adopts the existing app
+func TestEndToEnd_FakeLedgerAdoptApp(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"x": "y"}))
+
+	second, err := NewAlgorandBuffer(l, base64.StdEncoding.EncodeToString(buffer.AccountCrypt.PrivateKey))
+	assert.Nil(t, err)
+	assert.Equal(t, buffer.AppId, second.AppId)
+	d, _ := second.GetBuffer(context.Background())
+	assert.Equal(t, "y", d["x"])
+}
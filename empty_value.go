@@ -0,0 +1,63 @@
+package siam
+
+import "sort"
+
+// EmptyValuePolicy determines how writes of empty values are handled (see
+// ManageConfig.EmptyValuePolicy).
+type EmptyValuePolicy int
+
+const (
+	// StoreEmpty stores empty values like any other value. The key remains in the
+	// global state with an empty byte slice.
+	StoreEmpty EmptyValuePolicy = iota
+
+	// DeleteKey deletes keys that are written with an empty value, as if they were
+	// passed to DeleteElements.
+	DeleteKey
+)
+
+// splitEmpty returns the pairs of data with non-empty values, and the sorted keys with
+// empty values, if EmptyValuePolicy is DeleteKey. Otherwise, data is returned as is.
+func (ab *AlgorandBuffer) splitEmpty(data map[string][]byte) (map[string][]byte, []string) {
+	if ab.config.EmptyValuePolicy != DeleteKey {
+		return data, nil
+	}
+	stores := make(map[string][]byte, len(data))
+	deletes := make([]string, 0)
+	for k, v := range data {
+		if len(v) == 0 {
+			deletes = append(deletes, k)
+		} else {
+			stores[k] = v
+		}
+	}
+	sort.Strings(deletes)
+	return stores, deletes
+}
+
+// splitEmptyPairs is like splitEmpty, but keeps the order of the pairs. Since the
+// deletes are applied after the stores, only the last pair of each key is kept, at its
+// position: keys whose last value is empty are deleted, the others are stored with
+// their last value.
+func (ab *AlgorandBuffer) splitEmptyPairs(pairs []KV) ([]KV, []string) {
+	if ab.config.EmptyValuePolicy != DeleteKey {
+		return pairs, nil
+	}
+	last := make(map[string]int, len(pairs))
+	for i, kv := range pairs {
+		last[kv.Key] = i
+	}
+	stores := make([]KV, 0, len(pairs))
+	deletes := make([]string, 0)
+	for i, kv := range pairs {
+		if last[kv.Key] != i {
+			continue
+		}
+		if kv.Value != "" {
+			stores = append(stores, kv)
+		} else {
+			deletes = append(deletes, kv.Key)
+		}
+	}
+	return stores, deletes
+}
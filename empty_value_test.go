@@ -0,0 +1,54 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Empty values are stored by default.
+func TestAlgorandBuffer_EmptyValueStored(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"1000": "Astralis"}))
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"1000": ""}))
+	data, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"1000": ""}, data)
+}
+
+// With DeleteKey, writing an empty value deletes the key.
+func TestAlgorandBuffer_EmptyValueDeletes(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	buffer.config.EmptyValuePolicy = DeleteKey
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"1000": "Astralis", "1001": "Vitality", "1002": "OG"}))
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"1000": "", "1001": "Heroic"}))
+	data, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"1001": "Heroic", "1002": "OG"}, data)
+
+	assert.Nil(t, buffer.PutOrdered(context.Background(), []KV{{Key: "1002", Value: ""}}))
+	data, err = buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"1001": "Heroic"}, data)
+}
+
+// With DeleteKey, the last value of a key in PutOrdered wins, even if an earlier
+// value is empty.
+func TestAlgorandBuffer_EmptyValueOrdered(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	buffer.config.EmptyValuePolicy = DeleteKey
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"b": "OG"}))
+
+	pairs := []KV{{Key: "a", Value: ""}, {Key: "a", Value: "x"}, {Key: "b", Value: "y"}, {Key: "b", Value: ""}}
+	stores, deletes := buffer.splitEmptyPairs(pairs)
+	assert.Equal(t, []KV{{Key: "a", Value: "x"}}, stores)
+	assert.Equal(t, []string{"b"}, deletes)
+	assert.Nil(t, buffer.PutOrdered(context.Background(), pairs))
+	data, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "x"}, data)
+}
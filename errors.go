@@ -1,6 +1,7 @@
 package siam
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
@@ -8,6 +9,77 @@ import (
 	"github.com/algorand/go-algorand-sdk/crypto"
 )
 
+// ErrFeeBudgetExceeded is returned by write operations if the configured FeeBudget
+// has been used up for the current window.
+var ErrFeeBudgetExceeded = errors.New("fee budget exceeded for current window")
+
+// ErrValueTooLong is returned by write operations, if a key-value pair exceeds the
+// maximum length of 128 bytes.
+var ErrValueTooLong = errors.New("value too long")
+
+// ErrKeyTooLong is returned by write operations, if a key exceeds the maximum length
+// of 64 bytes of the global state.
+var ErrKeyTooLong = errors.New("key too long")
+
+// ErrReadOnly is returned by write operations of buffers created with NewReadOnlyBuffer.
+var ErrReadOnly = errors.New("buffer is read-only")
+
+// ErrKeyNotFound is returned by reads of single keys, if the key isn't stored.
+var ErrKeyNotFound = errors.New("key not found")
+
+// ErrTypeMismatch is returned by typed reads like GetInt and GetJSON, if the stored
+// bytes of a key don't decode to the requested type.
+var ErrTypeMismatch = errors.New("stored value doesn't match the requested type")
+
+// ErrSchemaMismatch is returned by ImportJSON, if the export was taken from an app
+// with a different global state schema than the one of the buffer.
+var ErrSchemaMismatch = errors.New("schema of the export doesn't match the buffer")
+
+// ErrPreflightFailed is returned by Preflight, if a write would fail. The error
+// details every failed check.
+var ErrPreflightFailed = errors.New("preflight failed")
+
+// ErrBufferFull is returned by write operations, if the new keys of a write exceed the
+// remaining capacity of the buffer (see AlgorandBuffer.Capacity). Nothing is written.
+var ErrBufferFull = errors.New("buffer is full")
+
+// ErrInsufficientFunds is reported by the management loop (see Manage), if the target
+// account can't pay for the minimum balance of its apps and the fees of the pending
+// writes (see CheckFunding). The cycle is skipped until the account is funded.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrGroupTooLarge is returned by PutElementsAtomic with ManageConfig.StrictAtomic and
+// by PutBlob, if the pairs don't fit into a single atomic group (see AtomicKeyLimit).
+var ErrGroupTooLarge = errors.New("write doesn't fit into a single atomic group")
+
+// ErrInvalidPassphrase is returned by LoadEncryptedKey, if the passphrase is wrong or
+// the key file has been tampered with.
+var ErrInvalidPassphrase = errors.New("invalid passphrase or corrupted key file")
+
+// ErrStopped is returned by reads and writes of a buffer after Stop has been called.
+var ErrStopped = errors.New("buffer is stopped")
+
+// ErrNearLimit is reported on AlgorandBuffer.AppChannel, if a write stored a value
+// close to the maximum length, or filled the buffer close to its capacity (see
+// ManageConfig.WarnValueRatio and ManageConfig.WarnCapacityRatio).
+var ErrNearLimit = errors.New("write is close to a limit")
+
+// ErrRoundRollback is reported on AlgorandBuffer.AppChannel, if the last round of the
+// node decreased between two cycles of the management loop (e.g. after a reorg). The
+// state read before may be stale, so the cycle re-validates the account and skips its
+// writes.
+var ErrRoundRollback = errors.New("round of the node rolled back")
+
+// ErrUnexpectedRekey is reported on AlgorandBuffer.AppChannel, if the auth address of
+// the target account changed without the buffer rekeying it (see AlgorandBuffer.Rekey).
+// This is a sign of compromise, or of a rekey that the operator should know about.
+var ErrUnexpectedRekey = errors.New("account rekeyed unexpectedly")
+
+// ErrLocalStateNotCleared is reported on AlgorandBuffer.AppChannel, if the local state
+// of opted-in accounts couldn't be removed before their app was deleted (see
+// ManageConfig.LocalStatePolicy).
+var ErrLocalStateNotCleared = errors.New("local state not cleared")
+
 // NoApplication is returned upon creation of an Algorand buffer for an account
 // that owns no application.
 type NoApplication struct {
@@ -28,3 +100,13 @@ type TooManyApplications struct {
 func (e *TooManyApplications) Error() string {
 	return fmt.Sprintf("given account owns more than one application {%s}", e.Account.Address)
 }
+
+// BoxesUnsupported is returned by features that require box storage, if the node
+// runs a consensus version without support for boxes.
+type BoxesUnsupported struct {
+	Version string
+}
+
+func (e *BoxesUnsupported) Error() string {
+	return fmt.Sprintf("consensus version does not support boxes {%s}", e.Version)
+}
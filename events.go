@@ -0,0 +1,64 @@
+package siam
+
+import (
+	"context"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// appChannelSize is the capacity of AlgorandBuffer.AppChannel.
+const appChannelSize = 16
+
+// ManageEvent is sent on AlgorandBuffer.AppChannel when the buffer adopts a different
+// application, e.g. because it created a new one, or when the management loop detects
+// a problem with the target account.
+type ManageEvent struct {
+	// AppId is the ID of the application the buffer now publishes to.
+	AppId uint64
+
+	// Err is the detected problem (e.g. ErrUnexpectedRekey), or nil if the event reports
+	// a different application.
+	Err error
+
+	// Context is the context of the management loop (see AlgorandBuffer.Context). It's
+	// cancelled when the buffer shuts down, so use it for calls made in reaction to
+	// the event.
+	Context context.Context
+}
+
+// emitEvent logs the event and sends it on AppChannel, without blocking if nobody
+// reads it. Events of a stopped buffer are dropped, since AppChannel is closed.
+func (ab *AlgorandBuffer) emitEvent(e ManageEvent) {
+	if e.Err != nil {
+		ab.logger().Warnf("app %d: %s", e.AppId, e.Err)
+	} else {
+		ab.logger().Infof("publishing to app %d", e.AppId)
+	}
+	e.Context = ab.Context()
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if ab.stopped {
+		return
+	}
+	select {
+	case ab.AppChannel <- e:
+	default:
+	}
+}
+
+// setAppId sets the app the buffer publishes to, and emits a ManageEvent if it changed.
+func (ab *AlgorandBuffer) setAppId(id uint64) {
+	if id == ab.currentAppId() {
+		return
+	}
+	ab.storeAppId(id)
+	ab.emitEvent(ManageEvent{AppId: id})
+}
+
+// logger returns the Logger of the config, or a client.NopLogger.
+func (ab *AlgorandBuffer) logger() client.Logger {
+	if ab.config.Logger == nil {
+		return client.NopLogger{}
+	}
+	return ab.config.Logger
+}
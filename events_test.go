@@ -0,0 +1,94 @@
+//go:build unit
+
+package siam
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// The context of emitted events is cancelled when the buffer shuts down.
+func TestAlgorandBuffer_EventContext(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	var event ManageEvent
+	select {
+	case event = <-buffer.AppChannel:
+	default:
+		t.Fatal("no event for the created app")
+	}
+	assert.Equal(t, uint64(4512), event.AppId)
+	assert.Nil(t, event.Context.Err())
+
+	done := make(chan struct{})
+	go func() {
+		buffer.Manage()
+		close(done)
+	}()
+	buffer.shutdown()
+
+	select {
+	case <-event.Context.Done():
+	case <-time.After(time.Second):
+		t.Fatal("event context wasn't cancelled")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Manage didn't return after shutdown")
+	}
+	assert.Equal(t, event.Context, buffer.Context())
+}
+
+// Adopting the same app again doesn't emit another event.
+func TestAlgorandBuffer_EventOnlyOnChange(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	assert.Len(t, buffer.AppChannel, 1)
+
+	assert.Nil(t, buffer.ReconcileOnce(buffer.Context()))
+	assert.Len(t, buffer.AppChannel, 1)
+}
+
+// logRecorder records the messages logged at each level.
+type logRecorder struct {
+	mu       sync.Mutex
+	messages map[string][]string
+}
+
+func (l *logRecorder) log(level string, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.messages == nil {
+		l.messages = make(map[string][]string)
+	}
+	l.messages[level] = append(l.messages[level], fmt.Sprintf(format, args...))
+}
+
+func (l *logRecorder) Debugf(format string, args ...interface{}) { l.log("debug", format, args...) }
+func (l *logRecorder) Infof(format string, args ...interface{})  { l.log("info", format, args...) }
+func (l *logRecorder) Warnf(format string, args ...interface{})  { l.log("warn", format, args...) }
+func (l *logRecorder) Errorf(format string, args ...interface{}) { l.log("error", format, args...) }
+
+// Events are logged to the logger of the buffer.
+func TestAlgorandBuffer_EventsLogged(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	logger := &logRecorder{}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{Logger: logger})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"publishing to app 4512"}, logger.messages["info"])
+
+	buffer.emitEvent(ManageEvent{AppId: buffer.AppId, Err: ErrUnexpectedRekey})
+	assert.Len(t, logger.messages["warn"], 1)
+	assert.Contains(t, logger.messages["warn"][0], ErrUnexpectedRekey.Error())
+}
@@ -0,0 +1,115 @@
+package siam
+
+import (
+	"context"
+	"sort"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// EvictionPolicy determines what happens if a write would exceed the capacity of the
+// buffer (see ManageConfig.EvictionPolicy).
+type EvictionPolicy int
+
+const (
+	// EvictNone rejects writes that exceed the capacity with ErrBufferFull.
+	EvictNone EvictionPolicy = iota
+
+	// EvictFIFO deletes the keys that were stored first. Overwriting a key doesn't
+	// change its position.
+	EvictFIFO
+
+	// EvictLRU deletes the keys that were written least recently. With
+	// ManageConfig.EvictTouchReads, reads of single keys (e.g. GetInt) count as well.
+	EvictLRU
+)
+
+// evictFor deletes the oldest stored keys according to ManageConfig.EvictionPolicy,
+// so that the batches fit into the capacity of the buffer. Keys of the batches are
+// never evicted. Keys that the buffer hasn't written since it was created (e.g. after
+// a restart) are the oldest, in order of their keys. Returns ErrBufferFull, if not
+// enough keys can be evicted.
+func (ab *AlgorandBuffer) evictFor(ctx context.Context, state map[string][]byte, batches [][]models.TealKeyValue, excess int) error {
+	writing := make(map[string]bool)
+	for _, kvArray := range batches {
+		for _, kv := range kvArray {
+			writing[kv.Key] = true
+		}
+	}
+	candidates := make([]string, 0, len(state))
+	for k := range state {
+		if !writing[k] {
+			candidates = append(candidates, k)
+		}
+	}
+	if len(candidates) < excess {
+		return ErrBufferFull
+	}
+	ab.mu.Lock()
+	order := ab.keyOrder
+	sort.Slice(candidates, func(i, j int) bool {
+		if order[candidates[i]] != order[candidates[j]] {
+			return order[candidates[i]] < order[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	ab.mu.Unlock()
+
+	evicted := make([]string, excess)
+	for i, k := range candidates[:excess] {
+		evicted[i] = ab.config.KeyEncoding.fromState([]byte(k))
+	}
+	return ab.DeleteElements(ctx, evicted...)
+}
+
+// trackKeys records the write of the stored keys for ManageConfig.EvictionPolicy.
+func (ab *AlgorandBuffer) trackKeys(kvArray []models.TealKeyValue) {
+	if ab.config.EvictionPolicy == EvictNone {
+		return
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	for _, kv := range kvArray {
+		ab.touchLocked(kv.Key, ab.config.EvictionPolicy == EvictLRU)
+	}
+}
+
+// touchRead records the read of a single key, if ManageConfig.EvictTouchReads is set.
+func (ab *AlgorandBuffer) touchRead(key string) {
+	if ab.config.EvictionPolicy != EvictLRU || !ab.config.EvictTouchReads {
+		return
+	}
+	stateKey, err := ab.config.KeyEncoding.toState(key)
+	if err != nil {
+		return
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	ab.touchLocked(stateKey, true)
+}
+
+// touchLocked moves the key to the end of the eviction order. Keys that are already
+// tracked are only moved if update is set. Call with mu held.
+func (ab *AlgorandBuffer) touchLocked(key string, update bool) {
+	if ab.keyOrder == nil {
+		ab.keyOrder = make(map[string]uint64)
+	}
+	if _, ok := ab.keyOrder[key]; ok && !update {
+		return
+	}
+	ab.keySeq++
+	ab.keyOrder[key] = ab.keySeq
+}
+
+// untrackKeys forgets the deleted state keys, so that they're new if they're stored
+// again.
+func (ab *AlgorandBuffer) untrackKeys(keys []string) {
+	if ab.config.EvictionPolicy == EvictNone {
+		return
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	for _, k := range keys {
+		delete(ab.keyOrder, k)
+	}
+}
@@ -0,0 +1,86 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// newEvictingBuffer returns a buffer with a capacity of three keys, which stored the
+// keys a, b and c in that order, and then overwrote a.
+func newEvictingBuffer(t *testing.T, cfg ManageConfig) (*AlgorandBuffer, *client.AlgorandMock) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyAppsWithSchema(models.ApplicationStateSchema{NumByteSlice: 3}, 6)
+	c.App = c.Account.CreatedApps[0]
+	cfg.AdaptToDeployedSchema = true
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	for _, k := range []string{"a", "b", "c", "a"} {
+		assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{k: "1"}))
+	}
+	return buffer, c
+}
+
+func assertKeys(t *testing.T, buffer *AlgorandBuffer, keys ...string) {
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, stored, len(keys))
+	for _, k := range keys {
+		assert.Contains(t, stored, k)
+	}
+}
+
+func TestAlgorandBuffer_EvictFIFO(t *testing.T) {
+	buffer, c := newEvictingBuffer(t, ManageConfig{EvictionPolicy: EvictFIFO})
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"d": "1"}))
+	assertKeys(t, buffer, "b", "c", "d")
+
+	// the evicted key is new when it's stored again
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "1", "e": "1"}))
+	assertKeys(t, buffer, "d", "a", "e")
+
+	// keys of the write aren't evicted
+	err := buffer.PutElements(context.Background(), map[string]string{"1": "", "2": "", "3": "", "4": ""})
+	assert.ErrorIs(t, err, ErrBufferFull)
+	assertKeys(t, buffer, "d", "a", "e")
+
+	// keys that the buffer didn't write are evicted first
+	buffer, err = NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{EvictionPolicy: EvictFIFO, AdaptToDeployedSchema: true})
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "2"}))
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"f": "1"}))
+	assertKeys(t, buffer, "a", "e", "f")
+}
+
+func TestAlgorandBuffer_EvictLRU(t *testing.T) {
+	buffer, _ := newEvictingBuffer(t, ManageConfig{EvictionPolicy: EvictLRU})
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"d": "1"}))
+	assertKeys(t, buffer, "a", "c", "d")
+
+	// reads don't count by default
+	_, err := buffer.GetBytes(context.Background(), "c")
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"e": "1"}))
+	assertKeys(t, buffer, "a", "d", "e")
+}
+
+func TestAlgorandBuffer_EvictTouchReads(t *testing.T) {
+	buffer, _ := newEvictingBuffer(t, ManageConfig{EvictionPolicy: EvictLRU, EvictTouchReads: true})
+	_, err := buffer.GetBytes(context.Background(), "b")
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"d": "1"}))
+	assertKeys(t, buffer, "a", "b", "d")
+}
+
+// Without eviction, a full buffer rejects new keys.
+func TestAlgorandBuffer_EvictNone(t *testing.T) {
+	buffer, _ := newEvictingBuffer(t, ManageConfig{})
+	err := buffer.PutElements(context.Background(), map[string]string{"d": "1"})
+	assert.ErrorIs(t, err, ErrBufferFull)
+	assertKeys(t, buffer, "a", "b", "c")
+}
@@ -0,0 +1,46 @@
+package siam
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+)
+
+// ExportCSV writes the stored key-value pairs of the buffer as CSV to w, sorted by key
+// and preceded by a "key,value" header. Values containing commas, quotes or newlines
+// are quoted. Reserved keys (e.g. HeartbeatKey) are excluded, like in GetBuffer.
+func (ab *AlgorandBuffer) ExportCSV(ctx context.Context, w io.Writer) error {
+	return ab.export(ctx, w, ',')
+}
+
+// ExportTSV writes the stored key-value pairs of the buffer like ExportCSV, but
+// separated by tabs.
+func (ab *AlgorandBuffer) ExportTSV(ctx context.Context, w io.Writer) error {
+	return ab.export(ctx, w, '\t')
+}
+
+func (ab *AlgorandBuffer) export(ctx context.Context, w io.Writer, comma rune) error {
+	data, err := ab.GetBuffer(ctx)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write([]string{"key", "value"}); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := cw.Write([]string{k, data[k]}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,129 @@
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// jsonExportVersion is the version of the format written by ExportJSON.
+const jsonExportVersion = 1
+
+// jsonExport is the format of ExportJSON. Values are base64-encoded, so that values
+// that aren't valid UTF-8 survive the round trip.
+type jsonExport struct {
+	Version int               `json:"version"`
+	AppId   uint64            `json:"app_id"`
+	Round   uint64            `json:"round"`
+	Schema  jsonSchema        `json:"schema"`
+	State   map[string]string `json:"state"`
+}
+
+// jsonSchema is the state schema of the app an export was taken from.
+type jsonSchema struct {
+	GlobalInts  uint64 `json:"global_ints"`
+	GlobalBytes uint64 `json:"global_bytes"`
+	LocalInts   uint64 `json:"local_ints"`
+	LocalBytes  uint64 `json:"local_bytes"`
+}
+
+// application returns an app with the given ID and the schema, so that the schema can
+// be checked like the schema of deployed apps (see fulfillsSchema).
+func (s jsonSchema) application(id uint64) models.Application {
+	return models.Application{Id: id, Params: models.ApplicationParams{
+		GlobalStateSchema: models.ApplicationStateSchema{NumUint: s.GlobalInts, NumByteSlice: s.GlobalBytes},
+		LocalStateSchema:  models.ApplicationStateSchema{NumUint: s.LocalInts, NumByteSlice: s.LocalBytes},
+	}}
+}
+
+// ExportJSON writes the state of the buffer as indented JSON to w, e.g. for backups or
+// to commit snapshots to version control. Besides the stored pairs with base64-encoded
+// raw values, the output holds the app ID, the round the state reflects (see Snapshot)
+// and the state schema of the buffer. Keys are sorted, so exports of equal states are
+// equal, and diffs between exports only show the changed pairs. Like ExportCSV, it
+// takes a ctx that bounds the requests to the node.
+func (ab *AlgorandBuffer) ExportJSON(ctx context.Context, w io.Writer) error {
+	s, err := ab.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	export := jsonExport{
+		Version: jsonExportVersion,
+		AppId:   s.AppId,
+		Round:   s.Round,
+		Schema:  ab.stateSchema(),
+		State:   make(map[string]string, len(s.State)),
+	}
+	for k, v := range s.State {
+		export.State[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	// maps are encoded with sorted keys
+	b, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// ImportJSON replaces the state of the buffer with an export of ExportJSON, like
+// Restore. The export may come from another app (e.g. after a redeployment), but the
+// buffer must accept an app with its schema (see NewAlgorandBuffer), and the global and
+// local schema must match the schema of the buffer. Otherwise ErrSchemaMismatch is
+// returned. Malformed exports return ErrInvalidSnapshot. Before writing, all pairs are
+// checked against the maximum length and the capacity of the buffer. If a check
+// fails, ErrPreflightFailed is returned and nothing is written. Writes are bounded by
+// ctx, like those of Restore.
+func (ab *AlgorandBuffer) ImportJSON(ctx context.Context, r io.Reader) error {
+	var export jsonExport
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&export); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if export.Version != jsonExportVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, export.Version)
+	}
+	if export.AppId == 0 {
+		return fmt.Errorf("%w: missing app ID", ErrInvalidSnapshot)
+	}
+	schema := ab.stateSchema()
+	if !ab.fulfillsSchema(export.Schema.application(export.AppId)) || export.Schema != schema {
+		return fmt.Errorf("%w: export has schema %+v, buffer has %+v", ErrSchemaMismatch, export.Schema, schema)
+	}
+	state := make(map[string][]byte, len(export.State))
+	for k, v := range export.State {
+		value, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return fmt.Errorf("%w: value of key %q: %v", ErrInvalidSnapshot, k, err)
+		}
+		state[k] = value
+	}
+
+	failures := ab.checkValues(rawStrings(state))
+	if len(state) > ab.capacity() {
+		failures = append(failures, fmt.Sprintf("%d keys exceed the capacity of %d keys", len(state), ab.capacity()))
+	}
+	if err := preflightError(failures); err != nil {
+		return err
+	}
+	return ab.Restore(ctx, state)
+}
+
+// stateSchema returns the global and local state schema of the buffer's app. Unless
+// ManageConfig.AdaptToDeployedSchema is set, it's ManageConfig.Schema.
+func (ab *AlgorandBuffer) stateSchema() jsonSchema {
+	if !ab.config.AdaptToDeployedSchema {
+		spec := ab.schemaSpec()
+		return jsonSchema{GlobalInts: spec.GlobalInts, GlobalBytes: spec.GlobalBytes,
+			LocalInts: spec.LocalInts, LocalBytes: spec.LocalBytes}
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	return jsonSchema{GlobalInts: ab.schema.NumUint, GlobalBytes: ab.schema.NumByteSlice,
+		LocalInts: ab.localSchema.NumUint, LocalBytes: ab.localSchema.NumByteSlice}
+}
@@ -0,0 +1,147 @@
+//go:build unit
+
+package siam
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_ExportCSV(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{HeartbeatInterval: time.Minute})
+	assert.Nil(t, err)
+	data := map[string]string{
+		"plain":   "Astralis",
+		"comma":   "Na'Vi, G2",
+		"quote":   `say "hi"`,
+		"newline": "line1\nline2",
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+	assert.Nil(t, buffer.heartbeat(context.Background()))
+
+	var b bytes.Buffer
+	assert.Nil(t, buffer.ExportCSV(context.Background(), &b))
+	expected := "key,value\n" +
+		"comma,\"Na'Vi, G2\"\n" +
+		"newline,\"line1\nline2\"\n" +
+		"plain,Astralis\n" +
+		"quote,\"say \"\"hi\"\"\"\n"
+	assert.Equal(t, expected, b.String())
+
+	// the output round-trips, and excludes the heartbeat
+	records, err := csv.NewReader(&b).ReadAll()
+	assert.Nil(t, err)
+	assert.Len(t, records, len(data)+1)
+	for _, r := range records[1:] {
+		assert.Equal(t, data[r[0]], r[1])
+	}
+}
+
+func TestAlgorandBuffer_ExportTSV(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "x,y", "b": "tab\there"}))
+
+	var b bytes.Buffer
+	assert.Nil(t, buffer.ExportTSV(context.Background(), &b))
+	assert.Equal(t, "key\tvalue\na\tx,y\nb\t\"tab\there\"\n", b.String())
+
+	c.SetError(true, (*client.AlgorandMock).GetApplicationByID)
+	assert.NotNil(t, buffer.ExportTSV(context.Background(), &b))
+}
+
+// An export restored into a new app results in the same state, including values that
+// aren't valid UTF-8.
+func TestAlgorandBuffer_ExportJSON(t *testing.T) {
+	source, _ := newFakeLedgerBuffer(t)
+	data := map[string][]byte{"price/BTC": []byte("1"), "raw": {0xff, 0x00}}
+	assert.Nil(t, source.PutElementsRaw(context.Background(), data))
+
+	var b bytes.Buffer
+	assert.Nil(t, source.ExportJSON(context.Background(), &b))
+	s, err := source.Snapshot(context.Background())
+	assert.Nil(t, err)
+	expected := "{\n" +
+		"  \"version\": 1,\n" +
+		"  \"app_id\": " + strconv.FormatUint(source.AppId, 10) + ",\n" +
+		"  \"round\": " + strconv.FormatUint(s.Round, 10) + ",\n" +
+		"  \"schema\": {\n" +
+		"    \"global_ints\": 0,\n" +
+		"    \"global_bytes\": 64,\n" +
+		"    \"local_ints\": 0,\n" +
+		"    \"local_bytes\": 0\n" +
+		"  },\n" +
+		"  \"state\": {\n" +
+		"    \"price/BTC\": \"MQ==\",\n" +
+		"    \"raw\": \"/wA=\"\n" +
+		"  }\n" +
+		"}\n"
+	assert.Equal(t, expected, b.String())
+
+	target, _ := newFakeLedgerBuffer(t)
+	assert.Nil(t, target.PutElements(context.Background(), map[string]string{"stale": "x"}))
+	// the local schema has to match as well
+	local := strings.Replace(b.String(), `"local_ints": 0`, `"local_ints": 1`, 1)
+	assert.ErrorIs(t, target.ImportJSON(context.Background(), strings.NewReader(local)), ErrSchemaMismatch)
+	assert.Nil(t, target.ImportJSON(context.Background(), &b))
+	stored, err := target.GetBufferRaw(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, stored)
+}
+
+// Exports of apps with another schema, malformed exports and pairs exceeding the
+// limits are rejected without writing.
+func TestAlgorandBuffer_ImportJSONRejected(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyAppsWithSchema(models.ApplicationStateSchema{NumByteSlice: 16}, 6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{AdaptToDeployedSchema: true})
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "1"}))
+
+	var b bytes.Buffer
+	assert.Nil(t, buffer.ExportJSON(context.Background(), &b))
+	assert.Contains(t, b.String(), `"global_bytes": 16`)
+	export := b.String()
+
+	other := strings.Replace(export, `"global_bytes": 16`, `"global_bytes": 64`, 1)
+	assert.ErrorIs(t, buffer.ImportJSON(context.Background(), strings.NewReader(other)), ErrSchemaMismatch)
+	other = strings.Replace(export, `"local_bytes": 16`, `"local_bytes": 0`, 1)
+	assert.ErrorIs(t, buffer.ImportJSON(context.Background(), strings.NewReader(other)), ErrSchemaMismatch)
+	// the buffer doesn't accept apps without global byte slices
+	other = strings.Replace(export, `"global_bytes": 16`, `"global_bytes": 0`, 1)
+	assert.ErrorIs(t, buffer.ImportJSON(context.Background(), strings.NewReader(other)), ErrSchemaMismatch)
+
+	for _, malformed := range []string{
+		"{",
+		strings.Replace(export, `"version": 1`, `"version": 2`, 1),
+		strings.Replace(export, `"MQ=="`, `"not base64"`, 1),
+		strings.Replace(export, `"state"`, `"unknown": 1, "state"`, 1),
+		strings.Replace(export, `"app_id": 6`, `"app_id": 0`, 1),
+	} {
+		assert.ErrorIs(t, buffer.ImportJSON(context.Background(), strings.NewReader(malformed)), ErrInvalidSnapshot)
+	}
+
+	tooLong := strings.Replace(export, `"MQ=="`, `"`+base64.StdEncoding.EncodeToString(make([]byte, MaxValueBytes))+`"`, 1)
+	assert.ErrorIs(t, buffer.ImportJSON(context.Background(), strings.NewReader(tooLong)), ErrPreflightFailed)
+
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "1"}, stored)
+}
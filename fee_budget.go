@@ -0,0 +1,157 @@
+package siam
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+)
+
+// FeeBudget limits the total amount of fees (in microAlgos) an AlgorandBuffer can
+// spend within a fixed time window. It acts as a safety circuit for unattended
+// oracles: if a bug causes runaway transaction submission, the buffer stops
+// writing once MaxFeesPerWindow is reached and resumes when the window resets.
+type FeeBudget struct {
+	// MaxFeesPerWindow is the maximum sum of transaction fees in microAlgos.
+	MaxFeesPerWindow uint64
+
+	// Window is the length of a budget window. When a window has passed, the
+	// spent fees are reset to zero.
+	Window time.Duration
+}
+
+// feeTracker keeps track of the fees spent within the current window of a FeeBudget.
+type feeTracker struct {
+	budget      FeeBudget
+	windowStart time.Time
+	spent       uint64
+	mu          sync.Mutex
+}
+
+func newFeeTracker(budget FeeBudget) *feeTracker {
+	return &feeTracker{budget: budget}
+}
+
+// reserve adds fee to the spent fees of the current window. If this exceeds the
+// budget, ErrFeeBudgetExceeded is returned and nothing is added.
+func (f *feeTracker) reserve(now time.Time, fee uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.windowStart.IsZero() || now.Sub(f.windowStart) >= f.budget.Window {
+		f.windowStart = now
+		f.spent = 0
+	}
+	if f.spent+fee > f.budget.MaxFeesPerWindow {
+		return ErrFeeBudgetExceeded
+	}
+	f.spent += fee
+	return nil
+}
+
+// baseTxnSize is a conservative estimate of the length in bytes of a signed
+// application call without arguments, including its sender, note, lease and group ID.
+const baseTxnSize = 300
+
+// argOverhead is the encoding overhead of a single application call argument.
+const argOverhead = 3
+
+// maxArgBytes and maxProgramBytes are the maximum total lengths of the arguments
+// and the programs of an application call.
+const (
+	maxArgBytes     = 2048
+	maxProgramBytes = 2048
+)
+
+// maxWriteTxnSize is the length of the largest signed write. It estimates the size of
+// writes whose pairs aren't batched yet.
+const maxWriteTxnSize = baseTxnSize + maxArgBytes + 2*client.MaxArgs*argOverhead
+
+// createTxnSize is the length of the largest signed application creation.
+const createTxnSize = baseTxnSize + maxProgramBytes
+
+// writeTxnSizes returns the estimated lengths of the transactions that store the
+// batches. If note isn't empty, every batch is tagged by one more transaction (see
+// client.WriteOptions).
+func writeTxnSizes(batches [][]models.TealKeyValue, note []byte) []uint64 {
+	sizes := make([]uint64, 0, len(batches)*2)
+	for _, kvArray := range batches {
+		size := uint64(baseTxnSize)
+		for _, kv := range kvArray {
+			size += uint64(len(kv.Key) + len(kv.Value.Bytes) + 2*argOverhead)
+		}
+		sizes = append(sizes, size)
+		if len(note) > 0 {
+			sizes = append(sizes, baseTxnSize+uint64(len(note)))
+		}
+	}
+	return sizes
+}
+
+// deleteTxnSizes returns the estimated lengths of the transactions that delete the
+// keys in batches of client.MaxArgs keys.
+func deleteTxnSizes(keys []string) []uint64 {
+	sizes := make([]uint64, 0, batchCount(len(keys), client.MaxArgs))
+	for i, k := range keys {
+		if i%client.MaxArgs == 0 {
+			sizes = append(sizes, baseTxnSize)
+		}
+		sizes[len(sizes)-1] += uint64(len(k) + argOverhead)
+	}
+	return sizes
+}
+
+// spendFees charges the fees of n transactions without arguments against the
+// FeeBudget of the buffer (see spendTxnFees).
+func (ab *AlgorandBuffer) spendFees(ctx context.Context, n int) error {
+	sizes := make([]uint64, n)
+	for i := range sizes {
+		sizes[i] = baseTxnSize
+	}
+	return ab.spendTxnFees(ctx, sizes...)
+}
+
+// spendTxnFees charges the fees of transactions with the given estimated lengths
+// against the FeeBudget of the buffer. The fee of a single transaction is estimated
+// from the node's suggested parameters. If no FeeBudget is configured, this does
+// nothing.
+func (ab *AlgorandBuffer) spendTxnFees(ctx context.Context, sizes ...uint64) error {
+	if ab.fees == nil || len(sizes) == 0 {
+		return nil
+	}
+	params, err := ab.SuggestedParams(ctx)
+	if err != nil {
+		return err
+	}
+	var fees uint64
+	for _, size := range sizes {
+		fees += ab.txnFee(params, size)
+	}
+	return ab.fees.reserve(ab.now(), fees)
+}
+
+// txnFee returns the fee of a single transaction of the buffer with the given length
+// under the given params, with the FeeStrategy of the buffer applied.
+func (ab *AlgorandBuffer) txnFee(params types.SuggestedParams, size uint64) uint64 {
+	fee := txnFee(params, size)
+	if ab.config.FeeStrategy != nil {
+		fee = ab.config.FeeStrategy.Fee(fee)
+	}
+	return fee
+}
+
+// txnFee returns the fee of a single transaction with the given length under the
+// given params. Unless the fee is flat, the node suggests a fee per byte, like the
+// SDK computes it when building the transaction.
+func txnFee(params types.SuggestedParams, size uint64) uint64 {
+	fee := uint64(params.Fee)
+	if !params.FlatFee {
+		fee *= size
+	}
+	if fee < params.MinFee {
+		fee = params.MinFee
+	}
+	return fee
+}
@@ -0,0 +1,140 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// Submit transactions until the fee budget trips. Further writes must be blocked
+// until the budget window rolls over.
+func TestAlgorandBuffer_FeeBudgetExceeded(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.Params = types.SuggestedParams{Fee: 1000, FlatFee: true, MinFee: 1000}
+	cfg := ManageConfig{FeeBudget: &FeeBudget{MaxFeesPerWindow: 3000, Window: time.Minute}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	now := time.Unix(1000, 0)
+	buffer.now = func() time.Time { return now }
+
+	for _, k := range []string{"a", "b", "c"} {
+		assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{k: "v"}))
+	}
+	err = buffer.PutElements(context.Background(), map[string]string{"d": "v"})
+	assert.ErrorIs(t, err, ErrFeeBudgetExceeded)
+	assert.ErrorIs(t, buffer.DeleteElements(context.Background(), "a"), ErrFeeBudgetExceeded)
+
+	// blocked write must not reach the node
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, d, 3)
+	_, exists := d["d"]
+	assert.False(t, exists)
+
+	// still blocked shortly before the window ends
+	now = now.Add(time.Second * 59)
+	assert.ErrorIs(t, buffer.PutElements(context.Background(), map[string]string{"d": "v"}), ErrFeeBudgetExceeded)
+
+	// window rolls over
+	now = now.Add(time.Second)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"d": "v"}))
+	d, _ = buffer.GetBuffer(context.Background())
+	assert.Equal(t, "v", d["d"])
+}
+
+// A single write consisting of several transactions is blocked as a whole, if
+// it would exceed the budget.
+func TestAlgorandBuffer_FeeBudgetWholeWrite(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.Params = types.SuggestedParams{Fee: 1000, FlatFee: true, MinFee: 1000}
+	cfg := ManageConfig{FeeBudget: &FeeBudget{MaxFeesPerWindow: 1000, Window: time.Minute}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	// two transactions are required for more than client.MaxKVArgs elements
+	data := make(map[string]string)
+	for _, k := range []string{"0", "1", "2", "3", "4", "5", "6", "7", "8"} {
+		data[k] = "v"
+	}
+	assert.ErrorIs(t, buffer.PutElements(context.Background(), data), ErrFeeBudgetExceeded)
+	d, _ := buffer.GetBuffer(context.Background())
+	assert.Len(t, d, 0)
+}
+
+// The fee budget is charged with the fees of the FeeStrategy, which are paid
+func TestAlgorandBuffer_FeeStrategy(t *testing.T) {
+	l := client.NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	cfg := ManageConfig{
+		FeeStrategy: &client.FeeStrategy{Mode: client.FeeMultiple, Multiplier: 2},
+		FeeBudget:   &FeeBudget{MaxFeesPerWindow: 4000, Window: time.Minute},
+	}
+	buffer, err := NewAlgorandBufferWithConfig(l, base64.StdEncoding.EncodeToString(acc.PrivateKey), cfg)
+	assert.Nil(t, err)
+
+	before := l.Balance(acc.Address)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "v"}))
+	assert.Equal(t, before-2*client.MinTxnFee, l.Balance(acc.Address))
+	// the creation and the first write used up the budget
+	assert.ErrorIs(t, buffer.PutElements(context.Background(), map[string]string{"b": "v"}), ErrFeeBudgetExceeded)
+
+	cfg.FeeStrategy = &client.FeeStrategy{Mode: client.FeeMultiple}
+	_, err = NewAlgorandBufferWithConfig(l, base64.StdEncoding.EncodeToString(acc.PrivateKey), cfg)
+	assert.NotNil(t, err)
+}
+
+// Unless the fee is flat, the budget is charged with the fee per byte of the
+// estimated transaction length, but with at least the minimum fee.
+func TestAlgorandBuffer_FeeBudgetPerByte(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.Params = types.SuggestedParams{Fee: 10, MinFee: 1000}
+	cfg := ManageConfig{FeeBudget: &FeeBudget{MaxFeesPerWindow: 3000, Window: time.Minute}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	// a congested node charges more than the minimum fee for a single write
+	assert.ErrorIs(t, buffer.PutElements(context.Background(), map[string]string{"a": "v"}), ErrFeeBudgetExceeded)
+	d, _ := buffer.GetBuffer(context.Background())
+	assert.Len(t, d, 0)
+
+	assert.Equal(t, uint64(1000), txnFee(types.SuggestedParams{Fee: 1, MinFee: 1000}, baseTxnSize))
+	assert.Equal(t, uint64(10*baseTxnSize), txnFee(types.SuggestedParams{Fee: 10, MinFee: 1000}, baseTxnSize))
+	assert.Equal(t, uint64(1000), txnFee(types.SuggestedParams{Fee: 10, FlatFee: true, MinFee: 1000}, baseTxnSize))
+}
+
+// The note of the write options is carried by one more transaction per batch, which
+// is charged as well.
+func TestAlgorandBuffer_FeeBudgetNote(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.Params = types.SuggestedParams{Fee: 1000, FlatFee: true, MinFee: 1000}
+	cfg := ManageConfig{FeeBudget: &FeeBudget{MaxFeesPerWindow: 1000, Window: time.Minute}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	opts := client.WriteOptions{Note: []byte("source")}
+	err = buffer.PutElementsWithOptions(context.Background(), map[string]string{"a": "v"}, opts)
+	assert.ErrorIs(t, err, ErrFeeBudgetExceeded)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "v"}))
+
+	sizes := writeTxnSizes([][]models.TealKeyValue{{{Key: "a", Value: models.TealValue{Bytes: "v"}}}}, opts.Note)
+	assert.Equal(t, []uint64{baseTxnSize + 2 + 2*argOverhead, baseTxnSize + 6}, sizes)
+}
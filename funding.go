@@ -0,0 +1,51 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// CheckFunding returns whether the balance of the target account covers its minimum
+// balance and the fees of the pending writes, and by how many microAlgos it falls
+// short otherwise. The minimum balance follows from the schemas of the apps of the
+// account, including the app of the buffer, if it still has to be created. Pending
+// writes are the pairs queued by QueueElements.
+func (ab *AlgorandBuffer) CheckFunding(ctx context.Context) (sufficient bool, shortfall uint64, err error) {
+	infoCtx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	info, err := ab.accountInformation(infoCtx)
+	cancel()
+	if err != nil {
+		return false, 0, err
+	}
+	ab.queueMu.Lock()
+	txns := batchCount(len(ab.unflushed), ab.batchSize())
+	ab.queueMu.Unlock()
+	apps := info.CreatedApps
+	if ab.keptApp(apps) < 0 {
+		_, g := ab.schemaSpec().Schemas()
+		schema := models.ApplicationStateSchema{NumUint: g.NumUint, NumByteSlice: g.NumByteSlice}
+		apps = append(apps[:len(apps):len(apps)], models.Application{Params: models.ApplicationParams{GlobalStateSchema: schema}})
+		txns++
+	}
+	params, err := ab.SuggestedParams(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	required := client.MinAccountBalance(apps) + ab.txnFee(params, maxWriteTxnSize)*uint64(txns)
+	if info.Amount >= required {
+		return true, 0, nil
+	}
+	return false, required - info.Amount, nil
+}
+
+// checkFunding returns ErrInsufficientFunds, if CheckFunding reports a shortfall.
+func (ab *AlgorandBuffer) checkFunding(ctx context.Context) error {
+	sufficient, shortfall, err := ab.CheckFunding(ctx)
+	if err != nil || sufficient {
+		return err
+	}
+	return fmt.Errorf("%w: %s needs %d more microAlgos", ErrInsufficientFunds, ab.AccountCrypt.Address, shortfall)
+}
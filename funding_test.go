@@ -0,0 +1,96 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_CheckFunding(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.Params = types.SuggestedParams{Fee: client.MinTxnFee, FlatFee: true, MinFee: client.MinTxnFee}
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	c.Account.Amount = client.MinAccountBalance(c.Account.CreatedApps)
+	sufficient, shortfall, err := buffer.CheckFunding(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, sufficient)
+	assert.Zero(t, shortfall)
+
+	// queued pairs are written in two transactions
+	data := make(map[string]string)
+	for i := 0; i <= client.MaxKVArgs; i++ {
+		data[string(rune('a'+i))] = "v"
+	}
+	assert.Nil(t, buffer.QueueElements(data))
+	sufficient, shortfall, err = buffer.CheckFunding(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, sufficient)
+	assert.EqualValues(t, 2*client.MinTxnFee, shortfall)
+
+	// an app that still has to be created needs its minimum balance and a fee
+	c.Account.CreatedApps = nil
+	c.Account.Amount = 0
+	_, shortfall, err = buffer.CheckFunding(context.Background())
+	assert.Nil(t, err)
+	_, g := client.GenerateSchemasModel()
+	app := models.Application{Params: models.ApplicationParams{GlobalStateSchema: g}}
+	assert.Equal(t, client.MinAccountBalance([]models.Application{app})+3*client.MinTxnFee, shortfall)
+
+	c.SetError(true, (*client.AlgorandMock).AccountInformation)
+	_, _, err = buffer.CheckFunding(context.Background())
+	assert.NotNil(t, err)
+}
+
+// The management loop reports ErrInsufficientFunds instead of submitting writes the
+// account can't pay for
+func TestAlgorandBuffer_ManageInsufficientFunds(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.Params = types.SuggestedParams{Fee: client.MinTxnFee, FlatFee: true, MinFee: client.MinTxnFee}
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	c.Account.Amount = client.MinAccountBalance(c.Account.CreatedApps)
+
+	assert.Nil(t, buffer.QueueElements(map[string]string{"k": "v"}))
+	err = buffer.manageCycle(context.Background())
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	assert.True(t, isPhase(err, PhaseCheck))
+	assert.ErrorIs(t, <-buffer.ErrChannel, ErrInsufficientFunds)
+	assert.Len(t, c.App.Params.GlobalState, 0)
+
+	// once funded, the pairs are stored
+	c.Account.Amount += client.MinTxnFee
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, c.App.Params.GlobalState, 1)
+}
+
+// If the app can't be created because the account is short, the cycle reports
+// ErrInsufficientFunds instead of the error of the node
+func TestAlgorandBuffer_ManageInsufficientFundsCreate(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	c.Account.CreatedApps = nil
+	c.Account.Amount = client.MinBalance
+	c.SetError(true, (*client.AlgorandMock).CreateApplication)
+
+	err = buffer.manageCycle(context.Background())
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// with enough funds, the error of the node is reported
+	c.Account.Amount = client.MockAccountAmount
+	err = buffer.manageCycle(context.Background())
+	assert.NotErrorIs(t, err, ErrInsufficientFunds)
+	assert.True(t, isPhase(err, PhaseCreate))
+}
@@ -0,0 +1,90 @@
+package siam
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// HeartbeatKey is the reserved key of the global state that holds the heartbeat of
+// the buffer (see ManageConfig.HeartbeatInterval). Its value consists of the unix
+// timestamp in seconds (8 bytes) followed by the round (8 bytes), both big-endian.
+const HeartbeatKey = "__heartbeat"
+
+// heartbeatLength is the length of a heartbeat value in bytes
+const heartbeatLength = 16
+
+// ReadHeartbeat returns the time and round of the last heartbeat stored in a global
+// state with raw keys (e.g. from client.ReadGlobalState). Returns false, if the state
+// holds no valid heartbeat. Consumers can detect a dead oracle by a stale heartbeat.
+func ReadHeartbeat(state map[string][]byte) (time.Time, uint64, bool) {
+	v, ok := state[HeartbeatKey]
+	if !ok || len(v) != heartbeatLength {
+		return time.Time{}, 0, false
+	}
+	ts := int64(binary.BigEndian.Uint64(v))
+	return time.Unix(ts, 0), binary.BigEndian.Uint64(v[8:]), true
+}
+
+// heartbeat stores the current time and round under HeartbeatKey, if the heartbeat
+// interval has passed since the last heartbeat. Heartbeats aren't user writes, so they
+// don't affect LastLogs, LastSuppressed or the store metrics.
+func (ab *AlgorandBuffer) heartbeat(ctx context.Context) error {
+	interval := ab.config.HeartbeatInterval
+	now := ab.now()
+	if interval <= 0 || (!ab.lastHeartbeat.IsZero() && now.Sub(ab.lastHeartbeat) < interval) {
+		return nil
+	}
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+
+	statusCtx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
+	status, err := ab.Client.Status(statusCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	value := make([]byte, heartbeatLength)
+	binary.BigEndian.PutUint64(value, uint64(now.Unix()))
+	binary.BigEndian.PutUint64(value[8:], status.LastRound)
+
+	err = ab.spendFees(ctx, 1)
+	if err != nil {
+		return err
+	}
+	tkv := models.TealKeyValue{Key: HeartbeatKey, Value: models.TealValue{Bytes: string(value)}}
+	result, err := client.StoreGlobalsResult(ab.Client, ab.AccountCrypt, ab.currentAppId(), []models.TealKeyValue{tkv})
+	if err != nil {
+		return ab.observeSubmitError(err)
+	}
+	ab.lastHeartbeat = now
+	return ab.awaitStoreFinality(ctx, []models.PendingTransactionInfoResponse{result})
+}
+
+// reservedKeys returns the keys of the global state that the buffer uses internally.
+// The heartbeat and lease keys are reserved, if they're enabled.
+func (ab *AlgorandBuffer) reservedKeys() []string {
+	keys := make([]string, 0, 2)
+	if ab.config.HeartbeatInterval > 0 {
+		keys = append(keys, HeartbeatKey)
+	}
+	if ab.config.LeaseDuration > 0 {
+		keys = append(keys, LeaseKey)
+	}
+	return keys
+}
+
+// capacity returns the number of keys of the global state that are available to users
+// of the buffer.
+func (ab *AlgorandBuffer) capacity() int {
+	available := ab.globalBytes() - len(ab.reservedKeys())
+	if ab.config.TrackModified {
+		// every key is stored with its companion key
+		return available / 2
+	}
+	return available
+}
@@ -0,0 +1,86 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func readHeartbeat(t *testing.T, c client.AlgorandClient, appId uint64) (time.Time, uint64, bool) {
+	state, err := client.ReadGlobalState(c, appId, context.Background())
+	assert.Nil(t, err)
+	return ReadHeartbeat(state)
+}
+
+// The heartbeat is updated by the management loop in the configured interval.
+func TestAlgorandBuffer_Heartbeat(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	cfg := ManageConfig{HeartbeatInterval: time.Minute}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	now := time.Unix(1000, 0)
+	buffer.now = func() time.Time { return now }
+	c.NodeStatus.LastRound = 42
+
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	ts, round, ok := readHeartbeat(t, c, buffer.AppId)
+	assert.True(t, ok)
+	assert.Equal(t, now, ts)
+	assert.EqualValues(t, 42, round)
+
+	// no update within the interval
+	now = now.Add(time.Second * 59)
+	c.NodeStatus.LastRound = 50
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	ts, round, _ = readHeartbeat(t, c, buffer.AppId)
+	assert.Equal(t, time.Unix(1000, 0), ts)
+	assert.EqualValues(t, 42, round)
+
+	now = now.Add(time.Second)
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	ts, round, _ = readHeartbeat(t, c, buffer.AppId)
+	assert.Equal(t, now, ts)
+	assert.EqualValues(t, 50, round)
+}
+
+// The heartbeat is excluded from GetBuffer and doesn't count against the capacity.
+func TestAlgorandBuffer_HeartbeatExcluded(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	cfg := ManageConfig{HeartbeatInterval: time.Minute}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"k": "v"}))
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"k": "v"}, d)
+	drift, err := buffer.VerifyAgainst(map[string]string{"k": "v"})
+	assert.Nil(t, err)
+	assert.True(t, drift.Empty())
+	assert.Equal(t, client.GlobalBytes-1, buffer.capacity())
+	assert.Len(t, buffer.LastLogs(), 0)
+}
+
+func TestAlgorandBuffer_NoHeartbeat(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	_, _, ok := readHeartbeat(t, c, buffer.AppId)
+	assert.False(t, ok)
+	assert.Equal(t, client.GlobalBytes, buffer.capacity())
+}
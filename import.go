@@ -0,0 +1,80 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// ReplaceBuffer replaces the stored key-value pairs of the buffer with data: keys that
+// aren't in data are deleted, and added or changed pairs are stored. Unchanged pairs
+// aren't written again.
+func (ab *AlgorandBuffer) ReplaceBuffer(ctx context.Context, data map[string]string) error {
+	live, err := ab.GetBuffer(ctx)
+	if err != nil {
+		return err
+	}
+	return ab.applyDiff(ctx, diffStates(live, data))
+}
+
+// applyDiff applies the StateDiff to the buffer. Removed keys are deleted first, so
+// that their space is available to the added keys.
+func (ab *AlgorandBuffer) applyDiff(ctx context.Context, diff StateDiff) error {
+	if len(diff.Removed) > 0 {
+		keys := make([]string, 0, len(diff.Removed))
+		for k := range diff.Removed {
+			keys = append(keys, k)
+		}
+		if err := ab.DeleteElements(ctx, keys...); err != nil {
+			return err
+		}
+	}
+	puts := make(map[string]string, len(diff.Added)+len(diff.Changed))
+	for k, v := range diff.Added {
+		puts[k] = v
+	}
+	for k, c := range diff.Changed {
+		puts[k] = c.New
+	}
+	if len(puts) == 0 {
+		return nil
+	}
+	return ab.PutElements(ctx, puts)
+}
+
+// Import replaces the state of the buffer with kv (e.g. to restore a backup, see
+// ExportCSV), like ReplaceBuffer. The returned StateDiff describes the changes from the
+// current state to kv. Before writing, kv is validated: all pairs must fit the maximum
+// length and the capacity, and the target account must be able to pay the fees. If a
+// check fails, ErrPreflightFailed is returned and nothing is written. If dryRun is
+// true, the diff is only planned and validated, but not written.
+func (ab *AlgorandBuffer) Import(kv map[string]string, dryRun bool) (StateDiff, error) {
+	ctx := context.Background()
+	live, err := ab.GetBuffer(ctx)
+	if err != nil {
+		return StateDiff{}, err
+	}
+	diff := diffStates(live, kv)
+
+	failures := ab.checkValues(kv)
+	if len(kv) > ab.capacity() {
+		failures = append(failures, fmt.Sprintf("%d keys exceed the capacity of %d keys", len(kv), ab.capacity()))
+	}
+	txns := batchCount(len(diff.Added)+len(diff.Changed), ab.batchSize()) + batchCount(len(diff.Removed), client.MaxArgs)
+	failure, err := ab.checkBalance(ctx, txns)
+	if err != nil {
+		return diff, err
+	}
+	if failure != "" {
+		failures = append(failures, failure)
+	}
+	if err := preflightError(failures); err != nil {
+		return diff, err
+	}
+
+	if dryRun {
+		return diff, nil
+	}
+	return diff, ab.applyDiff(ctx, diff)
+}
@@ -0,0 +1,81 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func lastRound(t *testing.T, l *client.FakeLedger) uint64 {
+	status, err := l.Status(context.Background())
+	assert.Nil(t, err)
+	return status.LastRound
+}
+
+// A dry run plans the diff without submitting transactions, the real run applies it.
+func TestAlgorandBuffer_Import(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"1000": "Astralis", "1001": "Vitality", "1002": "Gambit"}))
+	snapshot := map[string]string{"1000": "Astralis", "1001": "Heroic", "1003": "OG"}
+
+	round := lastRound(t, l)
+	diff, err := buffer.Import(snapshot, true)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"1003": "OG"}, diff.Added)
+	assert.Equal(t, map[string]string{"1002": "Gambit"}, diff.Removed)
+	assert.Equal(t, map[string]ValueChange{"1001": {Old: "Vitality", New: "Heroic"}}, diff.Changed)
+	assert.Equal(t, round, lastRound(t, l))
+
+	diff, err = buffer.Import(snapshot, false)
+	assert.Nil(t, err)
+	assert.False(t, diff.Empty())
+	data, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, snapshot, data)
+
+	// importing the same state again changes nothing
+	round = lastRound(t, l)
+	diff, err = buffer.Import(snapshot, false)
+	assert.Nil(t, err)
+	assert.True(t, diff.Empty())
+	assert.Equal(t, round, lastRound(t, l))
+}
+
+// Invalid snapshots are rejected before anything is written, also in dry runs.
+func TestAlgorandBuffer_ImportInvalid(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	tooMany := make(map[string]string)
+	for i := 0; i <= client.GlobalBytes; i++ {
+		tooMany[strconv.Itoa(i)] = "v"
+	}
+	round := lastRound(t, l)
+	_, err := buffer.Import(tooMany, true)
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Contains(t, err.Error(), "capacity")
+
+	_, err = buffer.Import(map[string]string{"key": strings.Repeat("x", 128)}, false)
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Equal(t, round, lastRound(t, l))
+}
+
+// ReplaceBuffer converges to the given state, even if it's full.
+func TestAlgorandBuffer_ReplaceBuffer(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	full := make(map[string]string)
+	replacement := make(map[string]string)
+	for i := 0; i < client.GlobalBytes; i++ {
+		full[strconv.Itoa(i)] = "v"
+		replacement["r"+strconv.Itoa(i)] = "w"
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), full))
+	assert.Nil(t, buffer.ReplaceBuffer(context.Background(), replacement))
+	data, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, replacement, data)
+}
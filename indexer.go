@@ -0,0 +1,24 @@
+package siam
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// AppHistory returns all applications the target account ever created, including
+// deleted ones, if the ManageConfig.Indexer is configured. Without an indexer, only
+// the apps the account currently owns are returned, because algod doesn't keep
+// deleted apps.
+func (ab *AlgorandBuffer) AppHistory(ctx context.Context) ([]models.Application, error) {
+	ctx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	defer cancel()
+	if ab.config.Indexer != nil {
+		return ab.config.Indexer.SearchForApplications(ab.AccountCrypt.Address.String(), ctx)
+	}
+	info, err := ab.accountInformation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.CreatedApps, nil
+}
@@ -0,0 +1,69 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_AppHistory(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	// algod only knows the current app
+	apps, err := buffer.AppHistory(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, apps, 1)
+
+	creator := buffer.AccountCrypt.Address.String()
+	buffer.config.Indexer = &client.IndexerMock{Apps: []models.Application{
+		{Id: 3, Deleted: true, Params: models.ApplicationParams{Creator: creator}},
+		{Id: 4, Params: models.ApplicationParams{Creator: "other"}},
+		{Id: 6, Params: models.ApplicationParams{Creator: creator}},
+	}}
+	apps, err = buffer.AppHistory(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, apps, 2)
+	assert.True(t, apps[0].Deleted)
+
+	buffer.config.Indexer = &client.IndexerMock{AlwaysReturnError: true}
+	_, err = buffer.AppHistory(context.Background())
+	assert.NotNil(t, err)
+}
+
+// CreationInfo falls back to the indexer, if algod doesn't know the app.
+func TestAlgorandBuffer_CreationInfoFromIndexer(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	indexer := &client.IndexerMock{Apps: []models.Application{{Id: 6, CreatedAtRound: 99, Deleted: true}}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{Indexer: indexer})
+	assert.Nil(t, err)
+
+	c.SetError(true, (*client.AlgorandMock).GetApplicationByID)
+	info, err := buffer.CreationInfo()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 99, info.CreatedAtRound)
+	assert.Empty(t, info.TxID)
+
+	// the creation transaction is looked up with the indexer
+	indexer.Transactions = []models.Transaction{
+		{Id: "CALL", ApplicationTransaction: models.TransactionApplication{ApplicationId: 6}},
+		{Id: "CREATE", ConfirmedRound: 99, CreatedApplicationIndex: 6},
+	}
+	info, err = buffer.CreationInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, "CREATE", info.TxID)
+
+	indexer.AlwaysReturnError = true
+	_, err = buffer.CreationInfo()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,26 @@
+package siam
+
+import "github.com/algorand/go-algorand-sdk/client/v2/common/models"
+
+// KeepPolicy determines which app the buffer keeps, if the target account owns several
+// valid apps (see ManageConfig.KeepPolicy). The state of the kept app is preserved, the
+// other apps are deleted with their state.
+type KeepPolicy int
+
+const (
+	// KeepOldest keeps the app with the smallest CreatedAtRound. If several apps were
+	// created in the same round, the one with the lowest ID is kept.
+	KeepOldest KeepPolicy = iota
+
+	// KeepLowestID keeps the app with the lowest ID.
+	KeepLowestID
+)
+
+// prefers returns true if the policy keeps app a rather than app b. The order is
+// total, so the choice doesn't depend on the order in which the node lists the apps.
+func (p KeepPolicy) prefers(a, b models.Application) bool {
+	if p == KeepOldest && a.CreatedAtRound != b.CreatedAtRound {
+		return a.CreatedAtRound < b.CreatedAtRound
+	}
+	return a.Id < b.Id
+}
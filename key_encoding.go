@@ -0,0 +1,41 @@
+package siam
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// KeyEncoding determines how the keys passed to and returned from the buffer map to
+// the keys of the global state. Keys of the global state are byte slices.
+type KeyEncoding int
+
+const (
+	// KeyRaw uses the bytes of a key as the global state key.
+	KeyRaw KeyEncoding = iota
+
+	// KeyBase64 expects keys to be base64-encoded (standard encoding with padding).
+	// Keys are decoded before they're written, and global state keys are encoded
+	// before they're returned. Use it to interoperate with tools that store keys
+	// that are already base64-encoded, or if keys aren't valid UTF-8.
+	KeyBase64
+)
+
+// toState returns the global state key of the given buffer key.
+func (e KeyEncoding) toState(key string) (string, error) {
+	if e != KeyBase64 {
+		return key, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", fmt.Errorf("key is not base64-encoded {%s}: %w", key, err)
+	}
+	return string(decoded), nil
+}
+
+// fromState returns the buffer key of the given global state key.
+func (e KeyEncoding) fromState(key []byte) string {
+	if e != KeyBase64 {
+		return string(key)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
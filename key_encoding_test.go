@@ -0,0 +1,67 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newKeyEncodingBuffers(t *testing.T) (raw *AlgorandBuffer, b64 *AlgorandBuffer) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	key := client.GeneratePrivateKey64()
+	raw, err := NewAlgorandBufferWithConfig(c, key, ManageConfig{KeyEncoding: KeyRaw})
+	assert.Nil(t, err)
+	b64, err = NewAlgorandBufferWithConfig(c, key, ManageConfig{KeyEncoding: KeyBase64})
+	assert.Nil(t, err)
+	return raw, b64
+}
+
+// Keys written with base64 encoding are stored as decoded bytes. A buffer with the
+// matching encoding reads them back as written, a raw buffer reads the decoded bytes.
+func TestKeyEncoding_WriteBase64(t *testing.T) {
+	raw, b64 := newKeyEncodingBuffers(t)
+	assert.Nil(t, b64.PutElements(context.Background(), map[string]string{"AAEC": "v"}))
+
+	d, err := b64.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"AAEC": "v"}, d)
+
+	d, err = raw.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"\x00\x01\x02": "v"}, d)
+
+	assert.Nil(t, b64.DeleteElements(context.Background(), "AAEC"))
+	d, _ = raw.GetBuffer(context.Background())
+	assert.Len(t, d, 0)
+}
+
+// Keys written raw are returned base64-encoded by a buffer expecting base64 keys.
+func TestKeyEncoding_WriteRaw(t *testing.T) {
+	raw, b64 := newKeyEncodingBuffers(t)
+	assert.Nil(t, raw.PutOrdered(context.Background(), []KV{{Key: "hello", Value: "world"}}))
+
+	d, err := b64.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"aGVsbG8=": "world"}, d)
+
+	d, err = raw.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"hello": "world"}, d)
+}
+
+// Keys that aren't base64-encoded are rejected before anything is submitted.
+func TestKeyEncoding_InvalidBase64(t *testing.T) {
+	raw, b64 := newKeyEncodingBuffers(t)
+	assert.NotNil(t, b64.PutElements(context.Background(), map[string]string{"hello": "v"}))
+	assert.NotNil(t, b64.PutOrdered(context.Background(), []KV{{Key: "hello", Value: "v"}}))
+	assert.NotNil(t, b64.DeleteElements(context.Background(), "hello"))
+
+	d, _ := raw.GetBuffer(context.Background())
+	assert.Len(t, d, 0)
+}
@@ -0,0 +1,103 @@
+package siam
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"golang.org/x/crypto/scrypt"
+)
+
+// An encrypted key file consists of keyFileMagic, the scrypt salt, the AES-GCM nonce
+// and the sealed private key of the account.
+const (
+	keyFileMagic   = "siamkey1"
+	keyFileSaltLen = 16
+)
+
+// scrypt parameters for deriving the encryption key from a passphrase
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// keyFileCipher derives the AES-GCM cipher of a key file from the passphrase and salt.
+func keyFileCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SaveEncryptedKey encrypts the private key of acc with the passphrase and writes it to
+// the file at path, readable only by the owner. The encryption key is derived with
+// scrypt, and the private key is sealed with AES-GCM. Use LoadEncryptedKey to decrypt it.
+func SaveEncryptedKey(path string, acc crypto.Account, passphrase string) error {
+	salt := make([]byte, keyFileSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	aead, err := keyFileCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	file := bytes.NewBufferString(keyFileMagic)
+	file.Write(salt)
+	file.Write(nonce)
+	file.Write(aead.Seal(nil, nonce, acc.PrivateKey, []byte(keyFileMagic)))
+	return os.WriteFile(path, file.Bytes(), 0600)
+}
+
+// LoadEncryptedKey decrypts the account stored at path by SaveEncryptedKey. Returns
+// ErrInvalidPassphrase, if the passphrase is wrong.
+func LoadEncryptedKey(path, passphrase string) (crypto.Account, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return crypto.Account{}, err
+	}
+	if !bytes.HasPrefix(b, []byte(keyFileMagic)) || len(b) < len(keyFileMagic)+keyFileSaltLen {
+		return crypto.Account{}, errors.New("not an encrypted key file")
+	}
+	b = b[len(keyFileMagic):]
+	aead, err := keyFileCipher(passphrase, b[:keyFileSaltLen])
+	if err != nil {
+		return crypto.Account{}, err
+	}
+	b = b[keyFileSaltLen:]
+	if len(b) < aead.NonceSize() {
+		return crypto.Account{}, errors.New("not an encrypted key file")
+	}
+	pk, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], []byte(keyFileMagic))
+	if err != nil {
+		return crypto.Account{}, ErrInvalidPassphrase
+	}
+	return crypto.AccountFromPrivateKey(pk)
+}
+
+// NewAlgorandBufferFromKeyFile creates an AlgorandBuffer like NewAlgorandBufferWithConfig,
+// but decrypts the private key from a key file written by SaveEncryptedKey. This way,
+// the key can be stored encrypted at rest and unlocked with a passphrase at startup.
+func NewAlgorandBufferFromKeyFile(c client.AlgorandClient, path, passphrase string, cfg ManageConfig) (*AlgorandBuffer, error) {
+	acc, err := LoadEncryptedKey(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewAlgorandBufferWithConfig(c, base64.StdEncoding.EncodeToString(acc.PrivateKey), cfg)
+}
@@ -0,0 +1,55 @@
+//go:build unit
+
+package siam
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oracle.key")
+	acc := crypto.GenerateAccount()
+	assert.Nil(t, SaveEncryptedKey(path, acc, "correct horse"))
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	b, _ := os.ReadFile(path)
+	assert.NotContains(t, string(b), string(acc.PrivateKey))
+
+	loaded, err := LoadEncryptedKey(path, "correct horse")
+	assert.Nil(t, err)
+	assert.Equal(t, acc.Address, loaded.Address)
+	assert.Equal(t, acc.PrivateKey, loaded.PrivateKey)
+
+	_, err = LoadEncryptedKey(path, "battery staple")
+	assert.ErrorIs(t, err, ErrInvalidPassphrase)
+
+	// tampering is detected
+	b[len(b)-1] ^= 1
+	assert.Nil(t, os.WriteFile(path, b, 0600))
+	_, err = LoadEncryptedKey(path, "correct horse")
+	assert.ErrorIs(t, err, ErrInvalidPassphrase)
+}
+
+func TestNewAlgorandBufferFromKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oracle.key")
+	acc := crypto.GenerateAccount()
+	assert.Nil(t, SaveEncryptedKey(path, acc, "secret"))
+
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBufferFromKeyFile(c, path, "secret", ManageConfig{})
+	assert.Nil(t, err)
+	assert.Equal(t, acc.Address, buffer.AccountCrypt.Address)
+
+	_, err = NewAlgorandBufferFromKeyFile(c, path, "wrong", ManageConfig{})
+	assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	_, err = NewAlgorandBufferFromKeyFile(c, filepath.Join(t.TempDir(), "missing"), "secret", ManageConfig{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,98 @@
+package siam
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// ModifiedPrefix is the prefix of the companion keys that hold the last-write round of
+// each key, if ManageConfig.TrackModified is set. The companion key of a key is the
+// prefix followed by the key, and its value is the round as 8-byte big-endian integer.
+const ModifiedPrefix = "__m:"
+
+// modifiedKey returns the companion key of the given global state key.
+func modifiedKey(key string) string {
+	return ModifiedPrefix + key
+}
+
+// isCompanionKey returns true if the global state key is a companion key.
+func (ab *AlgorandBuffer) isCompanionKey(key string) bool {
+	return ab.config.TrackModified && strings.HasPrefix(key, ModifiedPrefix)
+}
+
+// withModified adds the companion keys of the pairs to each batch, with the current
+// round of the node as value.
+func (ab *AlgorandBuffer) withModified(ctx context.Context, batches [][]models.TealKeyValue) ([][]models.TealKeyValue, error) {
+	if !ab.config.TrackModified || len(batches) == 0 {
+		return batches, nil
+	}
+	statusCtx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
+	status, err := ab.Client.Status(statusCtx)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	round := make([]byte, 8)
+	binary.BigEndian.PutUint64(round, status.LastRound)
+
+	tracked := make([][]models.TealKeyValue, len(batches))
+	for i, kvArray := range batches {
+		tracked[i] = make([]models.TealKeyValue, 0, 2*len(kvArray))
+		tracked[i] = append(tracked[i], kvArray...)
+		for _, kv := range kvArray {
+			key := modifiedKey(kv.Key)
+			if len(key)+len(round) > 128 {
+				return nil, fmt.Errorf("%w: companion key of {%s} exceeds 120 bytes", ErrValueTooLong, kv.Key)
+			}
+			tracked[i] = append(tracked[i], models.TealKeyValue{Key: key, Value: models.TealValue{Bytes: string(round)}})
+		}
+	}
+	return tracked, nil
+}
+
+// withModifiedKeys adds the companion keys to the given global state keys.
+func (ab *AlgorandBuffer) withModifiedKeys(keys []string) []string {
+	if !ab.config.TrackModified {
+		return keys
+	}
+	tracked := make([]string, 0, 2*len(keys))
+	tracked = append(tracked, keys...)
+	for _, k := range keys {
+		tracked = append(tracked, modifiedKey(k))
+	}
+	return tracked
+}
+
+// LastModified returns the round in which the given key was last written, as tracked
+// by the companion keys of ManageConfig.TrackModified. The round is the last round of
+// the node when the write was submitted, so the write was confirmed a few rounds
+// later at most. Returns ErrKeyNotFound if no round is tracked for the key.
+func (ab *AlgorandBuffer) LastModified(key string) (uint64, error) {
+	if !ab.config.TrackModified {
+		return 0, errors.New("last-modified rounds aren't tracked, see ManageConfig.TrackModified")
+	}
+	if ab.isStopped() {
+		return 0, ErrStopped
+	}
+	stateKey, err := ab.config.KeyEncoding.toState(key)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ab.readTimeout())
+	state, err := client.ReadGlobalState(ab.Client, ab.currentAppId(), ctx)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+	value, ok := state[modifiedKey(stateKey)]
+	if !ok || len(value) != 8 {
+		return 0, fmt.Errorf("%w {%s}", ErrKeyNotFound, key)
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
@@ -0,0 +1,59 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_LastModified(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.NodeStatus.LastRound = 100
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{TrackModified: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, buffer.batchSize())
+	assert.Equal(t, 32, buffer.capacity())
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "1", "b": "2"}))
+	round, err := buffer.LastModified("a")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 100, round)
+
+	// only the written key is updated
+	c.NodeStatus.LastRound = 105
+	assert.Nil(t, buffer.PutOrdered(context.Background(), []KV{{Key: "b", Value: "3"}}))
+	round, err = buffer.LastModified("b")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 105, round)
+	round, err = buffer.LastModified("a")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 100, round)
+
+	// companion keys are hidden, and deleted with their keys
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "3"}, stored)
+	assert.Nil(t, buffer.DeleteElements(context.Background(), "a"))
+	_, err = buffer.LastModified("a")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Len(t, c.App.Params.GlobalState, 2)
+}
+
+func TestAlgorandBuffer_LastModifiedDisabled(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	_, err = buffer.LastModified("a")
+	assert.NotNil(t, err)
+
+	_, err = NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{TrackModified: true, BatchSize: 1})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,100 @@
+package siam
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyReservoirSize is the number of recent confirmation durations that are
+// kept for computing latency percentiles.
+const latencyReservoirSize = 1024
+
+// LatencyStats contains percentiles of recent transaction confirmation latencies.
+type LatencyStats struct {
+	// Count is the number of durations the percentiles are computed from.
+	Count int
+
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+// latencyReservoir is a bounded ring buffer of the most recent durations. It also
+// keeps the sum and number of all recorded durations.
+type latencyReservoir struct {
+	durations []time.Duration
+	next      int
+	sum       time.Duration
+	count     uint64
+	mu        sync.Mutex
+}
+
+func newLatencyReservoir(size int) *latencyReservoir {
+	return &latencyReservoir{durations: make([]time.Duration, 0, size)}
+}
+
+// record adds d to the reservoir. If the reservoir is full, the oldest duration
+// is replaced.
+func (r *latencyReservoir) record(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sum += d
+	r.count++
+	if len(r.durations) < cap(r.durations) {
+		r.durations = append(r.durations, d)
+		return
+	}
+	r.durations[r.next] = d
+	r.next = (r.next + 1) % len(r.durations)
+}
+
+// totals returns the sum and number of all recorded durations, including those that
+// have been replaced.
+func (r *latencyReservoir) totals() (time.Duration, uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sum, r.count
+}
+
+// stats computes the percentiles of the recorded durations with the nearest-rank
+// method.
+func (r *latencyReservoir) stats() LatencyStats {
+	r.mu.Lock()
+	sorted := make([]time.Duration, len(r.durations))
+	copy(sorted, r.durations)
+	r.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return sorted[rank-1]
+	}
+	return LatencyStats{
+		Count: len(sorted),
+		P50:   percentile(50),
+		P95:   percentile(95),
+		P99:   percentile(99),
+	}
+}
+
+// observeLatency records the latency of a transaction that was submitted at start and
+// just confirmed, and reports it to the Metrics, if configured.
+func (ab *AlgorandBuffer) observeLatency(start time.Time) {
+	d := ab.now().Sub(start)
+	ab.latency.record(d)
+	if ab.config.Metrics != nil {
+		ab.config.Metrics.ConfirmationLatency(d)
+	}
+}
+
+// ConfirmationLatency returns percentiles of the time it took the node to confirm
+// recent transactions of the buffer, measured from submission until confirmation.
+// Use it for quick insight into node performance.
+func (ab *AlgorandBuffer) ConfirmationLatency() LatencyStats {
+	return ab.latency.stats()
+}
@@ -0,0 +1,72 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyReservoir_Percentiles(t *testing.T) {
+	r := newLatencyReservoir(100)
+	assert.Equal(t, LatencyStats{}, r.stats())
+
+	// feed 1ms..100ms in reverse order
+	for i := 100; i > 0; i-- {
+		r.record(time.Duration(i) * time.Millisecond)
+	}
+	stats := r.stats()
+	assert.Equal(t, 100, stats.Count)
+	assert.Equal(t, 50*time.Millisecond, stats.P50)
+	assert.Equal(t, 95*time.Millisecond, stats.P95)
+	assert.Equal(t, 99*time.Millisecond, stats.P99)
+}
+
+// Only the most recent durations are kept
+func TestLatencyReservoir_Bounded(t *testing.T) {
+	r := newLatencyReservoir(4)
+	for i := 0; i < 4; i++ {
+		r.record(time.Second)
+	}
+	for i := 0; i < 4; i++ {
+		r.record(time.Millisecond)
+	}
+	stats := r.stats()
+	assert.Equal(t, 4, stats.Count)
+	assert.Equal(t, time.Millisecond, stats.P99)
+}
+
+// The totals include replaced durations
+func TestLatencyReservoir_Totals(t *testing.T) {
+	r := newLatencyReservoir(2)
+	for i := 0; i < 3; i++ {
+		r.record(time.Second)
+	}
+	sum, count := r.totals()
+	assert.Equal(t, 3*time.Second, sum)
+	assert.EqualValues(t, 3, count)
+	assert.Equal(t, 2, r.stats().Count)
+}
+
+func TestAlgorandBuffer_ConfirmationLatency(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, _ := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+
+	// every call of the clock advances it by a second
+	now := time.Unix(0, 0)
+	buffer.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"x": "y"}))
+	assert.Nil(t, buffer.DeleteElements(context.Background(), "x"))
+
+	// creation of the app + store + delete
+	stats := buffer.ConfirmationLatency()
+	assert.Equal(t, 3, stats.Count)
+	assert.Equal(t, time.Second, stats.P99)
+}
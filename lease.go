@@ -0,0 +1,109 @@
+package siam
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// LeaseKey is the reserved key of the global state that holds the management lease
+// (see ManageConfig.LeaseDuration). Its value consists of the unix timestamp in
+// seconds at which the lease expires (8 bytes, big-endian), followed by the ID of
+// the owner.
+const LeaseKey = "__lease"
+
+// ReadLease returns the owner and expiry of the lease stored in a global state with
+// raw keys (e.g. from client.ReadGlobalState). Returns false, if the state holds no
+// valid lease.
+func ReadLease(state map[string][]byte) (string, time.Time, bool) {
+	v, ok := state[LeaseKey]
+	if !ok || len(v) <= 8 {
+		return "", time.Time{}, false
+	}
+	ts := int64(binary.BigEndian.Uint64(v))
+	return string(v[8:]), time.Unix(ts, 0), true
+}
+
+// newLeaseOwner generates a random owner ID, for managers without a configured
+// ManageConfig.LeaseOwner.
+func newLeaseOwner() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Standby returns true, if another manager holds the lease of the application (see
+// ManageConfig.LeaseDuration). While on standby, the buffer is read-only.
+func (ab *AlgorandBuffer) Standby() bool {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	return ab.standby
+}
+
+func (ab *AlgorandBuffer) setStandby(standby bool) {
+	ab.mu.Lock()
+	ab.standby = standby
+	ab.mu.Unlock()
+}
+
+// acquireLease acquires or renews the lease of the buffer's application. If another
+// manager holds an unexpired lease, the buffer goes on standby instead. The lease is
+// renewed once less than half of its duration remains.
+//
+// The transaction that stores the lease carries a transaction lease derived from the
+// previous lease value. If two managers claim the lease at the same time, the network
+// confirms only one of the claims.
+func (ab *AlgorandBuffer) acquireLease(ctx context.Context) error {
+	if ab.readOnly {
+		return ErrReadOnly
+	}
+	readCtx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	state, err := client.ReadGlobalState(ab.Client, ab.currentAppId(), readCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	now := ab.now()
+	duration := ab.config.LeaseDuration
+	owner, expiry, ok := ReadLease(state)
+	if ok && now.Before(expiry) {
+		if owner != ab.leaseOwner {
+			ab.setStandby(true)
+			return nil
+		}
+		if expiry.Sub(now) > duration/2 {
+			ab.setStandby(false)
+			return nil
+		}
+	}
+
+	err = ab.spendFees(ctx, 1)
+	if err != nil {
+		return err
+	}
+	value := make([]byte, 8, 8+len(ab.leaseOwner))
+	binary.BigEndian.PutUint64(value, uint64(now.Add(duration).Unix()))
+	value = append(value, ab.leaseOwner...)
+	appId := make([]byte, 8)
+	binary.BigEndian.PutUint64(appId, ab.currentAppId())
+	txnLease := sha256.Sum256(bytes.Join([][]byte{[]byte(LeaseKey), appId, state[LeaseKey]}, nil))
+
+	tkv := models.TealKeyValue{Key: LeaseKey, Value: models.TealValue{Bytes: string(value)}}
+	result, err := client.StoreGlobalsWithLease(ab.Client, ab.AccountCrypt, ab.currentAppId(), []models.TealKeyValue{tkv}, txnLease)
+	if err != nil {
+		return ab.observeSubmitError(err)
+	}
+	err = ab.awaitStoreFinality(ctx, []models.PendingTransactionInfoResponse{result})
+	if err != nil {
+		return err
+	}
+	ab.setStandby(false)
+	return nil
+}
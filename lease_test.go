@@ -0,0 +1,65 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// A second manager of the same account backs off while the first holds the lease.
+func TestAlgorandBuffer_Lease(t *testing.T) {
+	l := client.NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	key := base64.StdEncoding.EncodeToString(acc.PrivateKey)
+
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	primary, err := NewAlgorandBufferWithConfig(l, key, ManageConfig{LeaseDuration: time.Minute, LeaseOwner: "primary"})
+	assert.Nil(t, err)
+	primary.now = clock
+	secondary, err := NewAlgorandBufferWithConfig(l, key, ManageConfig{LeaseDuration: time.Minute, LeaseOwner: "secondary"})
+	assert.Nil(t, err)
+	secondary.now = clock
+	assert.Equal(t, primary.AppId, secondary.AppId)
+
+	assert.Nil(t, primary.manageCycle(context.Background()))
+	assert.False(t, primary.Standby())
+	assert.Nil(t, secondary.manageCycle(context.Background()))
+	assert.True(t, secondary.Standby())
+
+	state, err := client.ReadGlobalState(l, primary.AppId, context.Background())
+	assert.Nil(t, err)
+	owner, expiry, ok := ReadLease(state)
+	assert.True(t, ok)
+	assert.Equal(t, "primary", owner)
+	assert.Equal(t, now.Add(time.Minute), expiry)
+
+	// the standby manager is read-only, the lease is hidden from the buffer
+	assert.ErrorIs(t, secondary.PutElements(context.Background(), map[string]string{"k": "v"}), ErrReadOnly)
+	assert.Nil(t, primary.PutElements(context.Background(), map[string]string{"k": "v"}))
+	data, err := secondary.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"k": "v"}, data)
+
+	// the primary renews the lease in time
+	now = now.Add(time.Second * 40)
+	assert.Nil(t, primary.manageCycle(context.Background()))
+	now = now.Add(time.Second * 40)
+	assert.Nil(t, secondary.manageCycle(context.Background()))
+	assert.True(t, secondary.Standby())
+
+	// once the primary dies and the lease expires, the secondary takes over
+	now = now.Add(time.Minute)
+	assert.Nil(t, secondary.manageCycle(context.Background()))
+	assert.False(t, secondary.Standby())
+	assert.Nil(t, primary.manageCycle(context.Background()))
+	assert.True(t, primary.Standby())
+}
@@ -0,0 +1,100 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+)
+
+// LocalStatePolicy determines how the local state of opted-in accounts is removed
+// before their app is deleted (see ManageConfig.LocalStatePolicy).
+type LocalStatePolicy int
+
+const (
+	// ClearLocalState removes the local state with a clear-state call. Per protocol,
+	// clear-state calls remove the local state even if the clear program fails, so the
+	// deletion never gets stuck.
+	ClearLocalState LocalStatePolicy = iota
+
+	// CloseOutOrReport removes the local state with a close-out call, which lets the
+	// approval program react to it. Accounts whose close-out is rejected keep their local
+	// state. They're reported with ErrLocalStateNotCleared (see LastUncleared), and the
+	// app is deleted anyway.
+	CloseOutOrReport
+)
+
+// clearLocalStates removes the local state in the app of every account of
+// ManageConfig.OptedInAccounts that is opted in. With ClearLocalState, errors are
+// returned, so that the deletion is retried in the next cycle.
+func (ab *AlgorandBuffer) clearLocalStates(ctx context.Context, appId uint64) error {
+	if len(ab.config.OptedInAccounts) == 0 {
+		return nil
+	}
+	uncleared := make([]string, 0)
+	for _, acc := range ab.config.OptedInAccounts {
+		optedIn, err := ab.isOptedIn(ctx, acc, appId)
+		if err != nil {
+			return err
+		}
+		if !optedIn {
+			continue
+		}
+		if err := ab.spendFees(ctx, 1); err != nil {
+			return err
+		}
+		callCtx, cancel := context.WithTimeout(ctx, ab.writeTimeout())
+		if ab.config.LocalStatePolicy == CloseOutOrReport {
+			err = client.CloseOutApplication(ab.Client, acc, appId, callCtx)
+		} else {
+			err = client.ClearApplicationState(ab.Client, acc, appId, callCtx)
+		}
+		cancel()
+		if err == nil {
+			continue
+		}
+		if ab.config.LocalStatePolicy != CloseOutOrReport {
+			return ab.observeSubmitError(err)
+		}
+		uncleared = append(uncleared, acc.Address.String())
+	}
+
+	ab.mu.Lock()
+	ab.lastUncleared = uncleared
+	ab.mu.Unlock()
+	if len(uncleared) > 0 {
+		ab.emitEvent(ManageEvent{
+			AppId: ab.currentAppId(),
+			Err:   fmt.Errorf("%w: app %d, accounts %v", ErrLocalStateNotCleared, appId, uncleared),
+		})
+	}
+	return nil
+}
+
+// isOptedIn returns true if the account holds local state in the app.
+func (ab *AlgorandBuffer) isOptedIn(ctx context.Context, acc crypto.Account, appId uint64) (bool, error) {
+	infoCtx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	defer cancel()
+	info, err := ab.Client.AccountInformation(acc.Address.String(), infoCtx)
+	if err != nil {
+		return false, err
+	}
+	for _, local := range info.AppsLocalState {
+		if local.Id == appId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LastUncleared returns the addresses of the opted-in accounts whose local state
+// couldn't be removed during the last app deletion (see CloseOutOrReport). Returns an
+// empty slice, if all accounts were cleared.
+func (ab *AlgorandBuffer) LastUncleared() []string {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	addrs := make([]string, len(ab.lastUncleared))
+	copy(addrs, ab.lastUncleared)
+	return addrs
+}
@@ -0,0 +1,85 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_ClearLocalState(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.AppsLocalState = []models.ApplicationLocalState{{Id: 18}}
+	c.RejectCloseOut = true
+
+	cfg := ManageConfig{OptedInAccounts: []crypto.Account{crypto.GenerateAccount()}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.True(t, client.ValidAccount(c.Account))
+	assert.Empty(t, c.Account.AppsLocalState)
+	assert.Empty(t, buffer.LastUncleared())
+}
+
+func TestAlgorandBuffer_CloseOutOrReport(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.AppsLocalState = []models.ApplicationLocalState{{Id: 18}}
+	c.RejectCloseOut = true
+
+	acc := crypto.GenerateAccount()
+	cfg := ManageConfig{OptedInAccounts: []crypto.Account{acc}, LocalStatePolicy: CloseOutOrReport}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	// the app is deleted, even though the account couldn't be cleared
+	assert.True(t, client.ValidAccount(c.Account))
+	assert.Len(t, c.Account.AppsLocalState, 1)
+	assert.Equal(t, []string{acc.Address.String()}, buffer.LastUncleared())
+	select {
+	case e := <-buffer.AppChannel:
+		assert.True(t, errors.Is(e.Err, ErrLocalStateNotCleared))
+	default:
+		t.Fatal("uncleared accounts weren't reported")
+	}
+}
+
+func TestAlgorandBuffer_CloseOut(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.AppsLocalState = []models.ApplicationLocalState{{Id: 18}}
+
+	cfg := ManageConfig{OptedInAccounts: []crypto.Account{crypto.GenerateAccount()}, LocalStatePolicy: CloseOutOrReport}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.True(t, client.ValidAccount(c.Account))
+	assert.Empty(t, c.Account.AppsLocalState)
+	assert.Empty(t, buffer.LastUncleared())
+}
+
+// Failed clear-state calls are retried, instead of deleting the app.
+func TestAlgorandBuffer_ClearLocalStateError(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.AppsLocalState = []models.ApplicationLocalState{{Id: 18}}
+
+	cfg := ManageConfig{OptedInAccounts: []crypto.Account{crypto.GenerateAccount()}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	c.CreateDummyApps(6, 18)
+	c.Account.AppsLocalState = []models.ApplicationLocalState{{Id: 18}}
+	c.SetError(true, (*client.AlgorandMock).ExecuteTransaction)
+	assert.NotNil(t, buffer.manageDeletion(context.Background()))
+	assert.Len(t, c.Account.CreatedApps, 2)
+
+	c.ClearFunctionErrors()
+	assert.Nil(t, buffer.manageDeletion(context.Background()))
+	assert.Len(t, c.Account.CreatedApps, 1)
+	assert.Empty(t, c.Account.AppsLocalState)
+}
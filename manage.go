@@ -0,0 +1,269 @@
+package siam
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// Manage runs the management loop of the buffer. Every cycle, it brings the target
+// account into a valid state (see ReconcileOnce), stores the heartbeat (see
+// ManageConfig.HeartbeatInterval) and the pairs queued by QueueElements. Cycles run
+// every ManageConfig.SleepInterval. Errors of a cycle are reported on ErrChannel, and
+// retried in the next one, up to ManageConfig.MaxRetries times in a row. How an error
+// is retried depends on its ErrorClass (see ManageConfig.ClassifyError): e.g. if the
+// transaction pool of the node is full, the next cycle waits longer, and Permanent
+// errors aren't retried at all. Manage blocks until the buffer shuts down (see Stop),
+// so run it in its own goroutine:
+//
+//	go buffer.Manage()
+func (ab *AlgorandBuffer) Manage() {
+	ab.ManageContext(context.Background())
+}
+
+// ManageContext runs the management loop like Manage, until the buffer shuts down or
+// ctx is done. Cancelling ctx cancels the node calls of the current cycle, and the loop
+// returns right away instead of waiting for the next cycle. Unlike after Stop, queued
+// pairs are kept, so the loop can be resumed by calling Manage or ManageContext again.
+func (ab *AlgorandBuffer) ManageContext(ctx context.Context) {
+	done, ok := ab.beginLoop()
+	if !ok {
+		return
+	}
+	defer close(done)
+	loopCtx := ab.Context()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-loopCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	failures := 0
+	for {
+		err := ab.manageCycle(ctx)
+		if err == nil {
+			failures = 0
+		} else if class := ab.classifyError(err); class == Permanent {
+			return
+		} else if failures++; ab.config.MaxRetries > 0 && failures > ab.config.MaxRetries {
+			return
+		} else if class == FeeTooLow {
+			// retry with the current fees of the node
+			ab.mu.Lock()
+			ab.params = paramsCache{}
+			ab.mu.Unlock()
+		}
+		select {
+		case <-ctx.Done():
+			if loopCtx.Err() != nil {
+				ab.flushOnShutdown()
+			}
+			return
+		case <-time.After(ab.cycleDelay(err)):
+		}
+	}
+}
+
+// cycleDelay returns the time to wait after a cycle that returned err. Cycles that
+// failed because the transaction pool of the node is full, or because the node
+// throttles requests, wait longer to give the node time to recover (see
+// ManageConfig.PoolFullBackoff).
+func (ab *AlgorandBuffer) cycleDelay(err error) time.Duration {
+	sleep := client.AlgorandDefaultMinSleep
+	if ab.config.SleepInterval > 0 {
+		sleep = ab.config.SleepInterval
+	}
+	if err == nil {
+		return sleep
+	}
+	if class := ab.classifyError(err); class == PoolFull || class == RateLimited {
+		if ab.config.PoolFullBackoff > 0 {
+			return ab.config.PoolFullBackoff
+		}
+		return poolFullFactor * sleep
+	}
+	return sleep
+}
+
+// Context returns the context of the management loop. It's cancelled when the buffer
+// shuts down. Consumers reacting to a ManageEvent should derive their contexts from
+// it, so that their calls are cancelled as well.
+func (ab *AlgorandBuffer) Context() context.Context {
+	if ab.loopCtx == nil {
+		return context.Background()
+	}
+	return ab.loopCtx
+}
+
+// shutdown cancels the context of the management loop, which makes Manage return.
+func (ab *AlgorandBuffer) shutdown() {
+	if ab.stopLoop != nil {
+		ab.stopLoop()
+	}
+}
+
+// manageCycle performs a single iteration of the management loop. It first checks
+// that the account hasn't been rekeyed unexpectedly. If a lease is configured, the
+// cycle is skipped while another manager holds it. After a round rollback, the account
+// is only re-validated (see ErrRoundRollback). Writes of the cycle are skipped while
+// the account can't pay for them (see ErrInsufficientFunds). The app account is funded for its
+// boxes right after the app is reconciled, so that box writes never find it short.
+// Errors are reported on ErrChannel, wrapped in a ManageError.
+func (ab *AlgorandBuffer) manageCycle(ctx context.Context) (err error) {
+	ab.cycleMu.Lock()
+	defer ab.cycleMu.Unlock()
+	start := ab.now()
+	defer func() { ab.recordCycle(start, err) }()
+	defer func() { ab.reportError(ctx, err) }()
+	if err := ab.checkRekey(ctx); err != nil {
+		return withPhase(PhaseCheck, err)
+	}
+	if err := ab.checkRound(ctx); err != nil {
+		if !errors.Is(err, ErrRoundRollback) {
+			return withPhase(PhaseCheck, err)
+		}
+		// act on fresh state in the next cycle
+		if recErr := ab.ReconcileOnce(ctx); recErr != nil {
+			return recErr
+		}
+		return withPhase(PhaseCheck, err)
+	}
+	if ab.config.LeaseDuration > 0 {
+		if err := ab.acquireLease(ctx); err != nil {
+			return withPhase(PhaseCheck, err)
+		}
+		if ab.Standby() {
+			return nil
+		}
+	}
+	err = ab.ReconcileOnce(ctx)
+	if isPhase(err, PhaseCreate) {
+		// the app can't be created, if the account can't pay for it
+		if fundErr := ab.checkFunding(ctx); fundErr != nil {
+			return withPhase(PhaseCheck, fundErr)
+		}
+	}
+	if err == nil && ab.config.AutoFundBoxes && len(ab.config.Boxes) > 0 {
+		err = withPhase(PhaseFund, ab.FundBoxes(ctx))
+	}
+	if err == nil {
+		err = withPhase(PhaseCheck, ab.checkFunding(ctx))
+	}
+	if err == nil {
+		err = withPhase(PhaseStore, ab.heartbeat(ctx))
+	}
+	if err == nil {
+		err = withPhase(PhaseStore, ab.flushQueue(ctx))
+	}
+	if err == nil {
+		ab.observeKeyCount(ctx)
+	}
+	if ab.config.OnConverged != nil {
+		ab.observeConvergence(ctx, err)
+	}
+	return err
+}
+
+// observeConvergence calls ManageConfig.OnConverged, if the account converged during
+// the cycle: the buffer owns exactly one app, which it publishes to, and no writes are
+// pending. Cycles with errors diverge.
+func (ab *AlgorandBuffer) observeConvergence(ctx context.Context, cycleErr error) {
+	converged := false
+	if cycleErr == nil && ab.queueEmpty() {
+		infoCtx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+		info, err := ab.accountInformation(infoCtx)
+		cancel()
+		if err == nil {
+			apps := 0
+			for _, app := range info.CreatedApps {
+				if ab.isBufferApp(app) {
+					apps++
+				}
+			}
+			kept := ab.keptApp(info.CreatedApps)
+			converged = apps == 1 && kept >= 0 && info.CreatedApps[kept].Id == ab.currentAppId()
+		}
+	}
+	if converged && !ab.converged {
+		ab.config.OnConverged()
+	}
+	ab.converged = converged
+}
+
+// poolFullFactor is the factor by which the management loop waits longer after a
+// full transaction pool, if ManageConfig.PoolFullBackoff is zero.
+const poolFullFactor = 4
+
+// observeSubmitError counts failed transactions, including the submissions rejected
+// because the transaction pool of the node was full, and returns err.
+func (ab *AlgorandBuffer) observeSubmitError(err error) error {
+	ab.mu.Lock()
+	ab.metrics.failedTxns++
+	if errors.Is(err, client.ErrPoolFull) {
+		ab.metrics.poolFull++
+	}
+	ab.mu.Unlock()
+	if ab.config.Metrics != nil {
+		ab.config.Metrics.TransactionFailed(err)
+	}
+	return err
+}
+
+// loopStats are the statistics of the management loop (see LoopStats and LastCycle)
+type loopStats struct {
+	iterations uint64
+	last       time.Duration
+	total      time.Duration
+	succeeded  time.Time
+}
+
+// recordCycle records a cycle that started at start and returned err.
+func (ab *AlgorandBuffer) recordCycle(start time.Time, err error) {
+	end := ab.now()
+	d := end.Sub(start)
+	ab.mu.Lock()
+	ab.loop.iterations++
+	ab.loop.last = d
+	ab.loop.total += d
+	if err == nil {
+		ab.loop.succeeded = end
+	}
+	ab.mu.Unlock()
+	if ab.config.Metrics != nil {
+		ab.config.Metrics.CycleCompleted(d, err)
+	}
+}
+
+// LastCycle returns the time at which the management loop (see Manage) last completed
+// a cycle without error. It's zero if no cycle succeeded yet.
+func (ab *AlgorandBuffer) LastCycle() time.Time {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	return ab.loop.succeeded
+}
+
+// Healthy returns true if the management loop completed a cycle without error within
+// the last maxStaleness (see LastCycle). Use it for readiness probes: it turns false if
+// the cycles keep failing, e.g. because the connection to the node died, even though
+// the loop is still running. It's false until the first cycle succeeded.
+func (ab *AlgorandBuffer) Healthy(maxStaleness time.Duration) bool {
+	last := ab.LastCycle()
+	return !last.IsZero() && ab.now().Sub(last) <= maxStaleness
+}
+
+// LoopStats returns the number of cycles the management loop (see Manage) has run,
+// the duration of the last cycle and the average duration of all cycles. Cycles that
+// return an error are included. A growing average indicates that the node slows down.
+func (ab *AlgorandBuffer) LoopStats() (iterations uint64, lastDuration time.Duration, avgDuration time.Duration) {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if ab.loop.iterations == 0 {
+		return 0, 0, 0
+	}
+	return ab.loop.iterations, ab.loop.last, ab.loop.total / time.Duration(ab.loop.iterations)
+}
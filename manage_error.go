@@ -0,0 +1,88 @@
+package siam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errChannelSize is the capacity of AlgorandBuffer.ErrChannel.
+const errChannelSize = 16
+
+// ManagePhase names the phase of the management of the target account in which an
+// error occurred.
+type ManagePhase string
+
+const (
+	// PhaseCheck covers the checks at the start of a cycle of the management loop,
+	// like unexpected rekeys, round rollbacks and the lease.
+	PhaseCheck ManagePhase = "check"
+
+	// PhaseHealth covers the health check of the node.
+	PhaseHealth ManagePhase = "health"
+
+	// PhaseDelete covers the deletion of invalid or extra apps.
+	PhaseDelete ManagePhase = "delete"
+
+	// PhaseCreate covers the creation of a new app.
+	PhaseCreate ManagePhase = "create"
+
+	// PhaseValidate covers the validation of the app after deletion and creation, e.g.
+	// if no app fulfils the schema of the buffer.
+	PhaseValidate ManagePhase = "validate"
+
+	// PhaseFund covers the funding of the app account for its boxes.
+	PhaseFund ManagePhase = "fund"
+
+	// PhaseStore covers writes of the management loop, like the heartbeat and queued
+	// pairs.
+	PhaseStore ManagePhase = "store"
+)
+
+// ManageError is an error that occurred in a phase of the management of the target
+// account. It's returned by ReconcileOnce, and reported on AlgorandBuffer.ErrChannel.
+type ManageError struct {
+	Phase ManagePhase
+	Err   error
+}
+
+func (e *ManageError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Phase, e.Err)
+}
+
+func (e *ManageError) Unwrap() error {
+	return e.Err
+}
+
+// withPhase wraps err in a ManageError of the given phase. Errors that already name
+// their phase are returned as is.
+func withPhase(phase ManagePhase, err error) error {
+	var manageErr *ManageError
+	if err == nil || errors.As(err, &manageErr) {
+		return err
+	}
+	return &ManageError{Phase: phase, Err: err}
+}
+
+// isPhase returns true if err is a ManageError of the given phase.
+func isPhase(err error, phase ManagePhase) bool {
+	var manageErr *ManageError
+	return errors.As(err, &manageErr) && manageErr.Phase == phase
+}
+
+// reportError sends the error of a cycle on ErrChannel, without blocking if nobody
+// reads it. Errors caused by the shutdown of the buffer aren't reported.
+func (ab *AlgorandBuffer) reportError(ctx context.Context, err error) {
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if ab.stopped || ab.ErrChannel == nil {
+		return
+	}
+	select {
+	case ab.ErrChannel <- err:
+	default:
+	}
+}
@@ -0,0 +1,116 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newManagedMock(t *testing.T) (*AlgorandBuffer, *client.AlgorandMock) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	<-buffer.AppChannel
+	return buffer, c
+}
+
+// receivePhase returns the phase of the next error on ErrChannel.
+func receivePhase(t *testing.T, buffer *AlgorandBuffer) ManagePhase {
+	select {
+	case err := <-buffer.ErrChannel:
+		var manageErr *ManageError
+		if !errors.As(err, &manageErr) {
+			t.Fatalf("unexpected error type %T", err)
+		}
+		return manageErr.Phase
+	default:
+		t.Fatal("no error reported")
+		return ""
+	}
+}
+
+func TestAlgorandBuffer_ErrChannelPhases(t *testing.T) {
+	buffer, c := newManagedMock(t)
+
+	// an extra app with the wrong schema fails to be deleted
+	c.AddDummyApps(18)
+	c.Account.CreatedApps[1].Params.GlobalStateSchema = models.ApplicationStateSchema{}
+	c.SetError(true, (*client.AlgorandMock).DeleteApplication)
+	err := buffer.manageCycle(context.Background())
+	assert.NotNil(t, err)
+	assert.Equal(t, PhaseDelete, receivePhase(t, buffer))
+	c.ClearFunctionErrors()
+	c.CreateDummyApps()
+
+	c.SetError(true, (*client.AlgorandMock).CreateApplication)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, PhaseCreate, receivePhase(t, buffer))
+	c.ClearFunctionErrors()
+
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, PhaseHealth, receivePhase(t, buffer))
+	c.ClearFunctionErrors()
+
+	// successful cycles report nothing
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Empty(t, buffer.ErrChannel)
+
+	assert.Nil(t, buffer.QueueElements(map[string]string{"a": "1"}))
+	c.SetError(true, (*client.AlgorandMock).StoreGlobals)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, PhaseStore, receivePhase(t, buffer))
+}
+
+// The phase wraps the original error.
+func TestAlgorandBuffer_ManageErrorUnwraps(t *testing.T) {
+	buffer, c := newManagedMock(t)
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+	healthErr := buffer.checkConnection()
+
+	err := buffer.ReconcileOnce(context.Background())
+	var manageErr *ManageError
+	assert.ErrorAs(t, err, &manageErr)
+	assert.Equal(t, PhaseHealth, manageErr.Phase)
+	assert.Equal(t, healthErr, errors.Unwrap(err))
+	assert.Equal(t, "health: "+healthErr.Error(), err.Error())
+
+	// errors are wrapped once
+	assert.Equal(t, err, withPhase(PhaseStore, err))
+	assert.Nil(t, withPhase(PhaseStore, nil))
+}
+
+// A full ErrChannel never blocks the management loop.
+func TestAlgorandBuffer_ErrChannelNonBlocking(t *testing.T) {
+	buffer, c := newManagedMock(t)
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+	for i := 0; i < 2*errChannelSize; i++ {
+		assert.NotNil(t, buffer.manageCycle(context.Background()))
+	}
+	assert.Len(t, buffer.ErrChannel, errChannelSize)
+
+	buffer.Stop()
+	count := 0
+	for range buffer.ErrChannel {
+		count++
+	}
+	assert.Equal(t, errChannelSize, count)
+}
+
+// Errors caused by the shutdown of the buffer aren't reported.
+func TestAlgorandBuffer_ErrChannelShutdown(t *testing.T) {
+	buffer, c := newManagedMock(t)
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NotNil(t, buffer.manageCycle(ctx))
+	assert.Empty(t, buffer.ErrChannel)
+}
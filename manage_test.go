@@ -0,0 +1,237 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowMock advances a fake clock on each health check, to simulate a slow node
+type slowMock struct {
+	*client.AlgorandMock
+	now   time.Time
+	delay time.Duration
+}
+
+func (s *slowMock) HealthCheck(ctx context.Context) error {
+	s.now = s.now.Add(s.delay)
+	return s.AlgorandMock.HealthCheck(ctx)
+}
+
+func TestAlgorandBuffer_LoopStats(t *testing.T) {
+	c := &slowMock{AlgorandMock: client.CreateAlgorandClientMock("", ""), now: time.Unix(1000, 0)}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	buffer.now = func() time.Time { return c.now }
+
+	iterations, last, avg := buffer.LoopStats()
+	assert.EqualValues(t, 0, iterations)
+	assert.Zero(t, last)
+	assert.Zero(t, avg)
+
+	c.delay = time.Second
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	iterations, last, avg = buffer.LoopStats()
+	assert.EqualValues(t, 1, iterations)
+	assert.Equal(t, time.Second, last)
+	assert.Equal(t, time.Second, avg)
+
+	c.delay = time.Second * 3
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	iterations, last, avg = buffer.LoopStats()
+	assert.EqualValues(t, 2, iterations)
+	assert.Equal(t, time.Second*3, last)
+	assert.Equal(t, time.Second*2, avg)
+
+	// failed cycles are counted as well
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	iterations, _, _ = buffer.LoopStats()
+	assert.EqualValues(t, 3, iterations)
+}
+
+// OnConverged fires once on the initial convergence, and again after a divergence.
+func TestAlgorandBuffer_OnConverged(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	calls := 0
+	cfg := ManageConfig{OnConverged: func() { calls++ }, ExtraAppGrace: time.Hour}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, calls)
+
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, 1, calls)
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, 1, calls)
+
+	// failing cycles diverge
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	c.ClearFunctionErrors()
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, 2, calls)
+
+	// an extra app within its grace period isn't a steady state
+	c.AddDummyApps(7)
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, 2, calls)
+	c.Account.CreatedApps = c.Account.CreatedApps[:1]
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, 3, calls)
+}
+
+// congestedMock rejects the first n stores because the transaction pool is full.
+type congestedMock struct {
+	*client.AlgorandMock
+	n int
+}
+
+func (m *congestedMock) StoreGlobalsResult(acc crypto.Account, appId uint64, kv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	if m.n > 0 {
+		m.n--
+		return models.PendingTransactionInfoResponse{}, fmt.Errorf("%w: HTTP 400", client.ErrPoolFull)
+	}
+	return m.AlgorandMock.StoreGlobalsResult(acc, appId, kv)
+}
+
+// A full transaction pool is counted, and the management loop waits longer before
+// retrying than after other errors.
+func TestAlgorandBuffer_PoolFull(t *testing.T) {
+	c := &congestedMock{AlgorandMock: client.CreateAlgorandClientMock("", ""), n: 1}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{HeartbeatInterval: time.Minute})
+	assert.Nil(t, err)
+
+	err = buffer.manageCycle(context.Background())
+	assert.ErrorIs(t, err, client.ErrPoolFull)
+	assert.Equal(t, poolFullFactor*client.AlgorandDefaultMinSleep, buffer.cycleDelay(err))
+	assert.Greater(t, buffer.cycleDelay(err), buffer.cycleDelay(errors.New("node offline")))
+	var b strings.Builder
+	assert.Nil(t, buffer.WritePrometheus(&b))
+	assert.Contains(t, b.String(), "siam_pool_full_total 1\n")
+
+	// the retry succeeds
+	err = buffer.manageCycle(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, client.AlgorandDefaultMinSleep, buffer.cycleDelay(err))
+	assert.Len(t, c.App.Params.GlobalState, 1)
+
+	buffer.config.PoolFullBackoff = time.Minute
+	assert.Equal(t, time.Minute, buffer.cycleDelay(fmt.Errorf("store: %w", client.ErrPoolFull)))
+}
+
+// The zero config uses the default constants.
+func TestManageConfig_LoopDefaults(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	assert.Equal(t, client.AlgorandDefaultMinSleep, buffer.cycleDelay(nil))
+	assert.Equal(t, client.AlgorandDefaultTimeout, buffer.timeoutLength)
+
+	cfg := ManageConfig{SleepInterval: time.Second, HealthTimeout: 2 * time.Second}
+	buffer, err = NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Second, buffer.cycleDelay(errors.New("node offline")))
+	assert.Equal(t, poolFullFactor*time.Second, buffer.cycleDelay(client.ErrPoolFull))
+	assert.Equal(t, 2*time.Second, buffer.timeoutLength)
+
+	_, err = NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{MaxRetries: -1})
+	assert.NotNil(t, err)
+}
+
+// Manage gives up after MaxRetries consecutive failed cycles.
+func TestAlgorandBuffer_MaxRetries(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	cfg := ManageConfig{SleepInterval: time.Millisecond, MaxRetries: 3}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+
+	done := make(chan struct{})
+	go func() {
+		buffer.Manage()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Manage didn't give up")
+	}
+	iterations, _, _ := buffer.LoopStats()
+	assert.EqualValues(t, 4, iterations)
+}
+
+// Healthy turns false if no cycle succeeded within the staleness limit.
+func TestAlgorandBuffer_Healthy(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	clock := time.Unix(1000, 0)
+	buffer.now = func() time.Time { return clock }
+	assert.True(t, buffer.LastCycle().IsZero())
+	assert.False(t, buffer.Healthy(time.Hour))
+
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, clock, buffer.LastCycle())
+	assert.True(t, buffer.Healthy(time.Minute))
+
+	c.SetError(true, (*client.AlgorandMock).HealthCheck)
+	clock = clock.Add(30 * time.Second)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, clock.Add(-30*time.Second), buffer.LastCycle())
+	assert.True(t, buffer.Healthy(time.Minute))
+	clock = clock.Add(time.Minute)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	assert.False(t, buffer.Healthy(time.Minute))
+
+	c.ClearFunctionErrors()
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.True(t, buffer.Healthy(time.Minute))
+}
+
+// Cancelling the context of ManageContext stops the loop right away, without waiting
+// for the next cycle, and the buffer can be managed again
+func TestAlgorandBuffer_ManageContext(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{SleepInterval: time.Hour})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		buffer.ManageContext(ctx)
+		close(done)
+	}()
+	iterations := func() uint64 {
+		n, _, _ := buffer.LoopStats()
+		return n
+	}
+	assert.Eventually(t, func() bool { return iterations() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ManageContext didn't return after cancellation")
+	}
+
+	// the buffer isn't stopped, so the loop can be resumed
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"k": "v"}))
+	go buffer.Manage()
+	assert.Eventually(t, func() bool { return iterations() == 2 }, time.Second, time.Millisecond)
+	buffer.Stop()
+}
@@ -0,0 +1,149 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// InMemoryBuffer is a Buffer backed by a map, without a client or a management loop.
+// Use it to test code that consumes a Buffer. Writes are validated like those of an
+// AlgorandBuffer with the default config: keys that exceed MaxKeyBytes fail with
+// ErrKeyTooLong, pairs that exceed MaxValueBytes with ErrValueTooLong, and writes that
+// exceed the capacity with ErrBufferFull. Failed writes don't store anything. It's safe
+// for concurrent use.
+type InMemoryBuffer struct {
+	mu       sync.Mutex
+	state    map[string][]byte
+	capacity int
+}
+
+// NewInMemoryBuffer returns an empty InMemoryBuffer with the capacity of an app with
+// the client.DefaultSchema.
+func NewInMemoryBuffer() *InMemoryBuffer {
+	return NewInMemoryBufferWithCapacity(int(client.DefaultSchema.GlobalBytes))
+}
+
+// NewInMemoryBufferWithCapacity returns an empty InMemoryBuffer that holds up to
+// capacity keys.
+func NewInMemoryBufferWithCapacity(capacity int) *InMemoryBuffer {
+	return &InMemoryBuffer{state: make(map[string][]byte), capacity: capacity}
+}
+
+func (b *InMemoryBuffer) GetBuffer(ctx context.Context) (map[string]string, error) {
+	raw, err := b.GetBufferRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(raw))
+	for k, v := range raw {
+		m[k] = string(v)
+	}
+	return m, nil
+}
+
+func (b *InMemoryBuffer) GetBufferRaw(ctx context.Context) (map[string][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m := make(map[string][]byte, len(b.state))
+	for k, v := range b.state {
+		m[k] = append([]byte(nil), v...)
+	}
+	return m, nil
+}
+
+func (b *InMemoryBuffer) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	value, ok := b.state[key]
+	if !ok {
+		return nil, fmt.Errorf("%w {%s}", ErrKeyNotFound, key)
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (b *InMemoryBuffer) PutElements(ctx context.Context, data map[string]string) error {
+	m := make(map[string][]byte, len(data))
+	for k, v := range data {
+		m[k] = []byte(v)
+	}
+	return b.PutElementsRaw(ctx, m)
+}
+
+func (b *InMemoryBuffer) PutElementsRaw(ctx context.Context, data map[string][]byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for k, v := range data {
+		if len(k) > MaxKeyBytes {
+			return fmt.Errorf("%w {%s}: key cannot exceed %d bytes", ErrKeyTooLong, k, MaxKeyBytes)
+		}
+		if len(k)+len(v) > MaxValueBytes {
+			return fmt.Errorf("%w {%s}: kv pair cannot exceed %d bytes", ErrValueTooLong, k, MaxValueBytes)
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	newKeys := 0
+	for k := range data {
+		if _, ok := b.state[k]; !ok {
+			newKeys++
+		}
+	}
+	if len(b.state)+newKeys > b.capacity {
+		return fmt.Errorf("%w: %d new keys, %d of %d keys used", ErrBufferFull, newKeys, len(b.state), b.capacity)
+	}
+	for k, v := range data {
+		b.state[k] = append([]byte(nil), v...)
+	}
+	return nil
+}
+
+func (b *InMemoryBuffer) DeleteElements(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, k := range keys {
+		delete(b.state, k)
+	}
+	return nil
+}
+
+func (b *InMemoryBuffer) Contains(ctx context.Context, m map[string]string) (bool, error) {
+	data, err := b.GetBuffer(ctx)
+	if err != nil {
+		return false, err
+	}
+	return mapContainsMap(data, m), nil
+}
+
+func (b *InMemoryBuffer) AchieveDesiredState(ctx context.Context, desired map[string]string) error {
+	data, err := b.GetBuffer(ctx)
+	if err != nil {
+		return err
+	}
+	put, del := computeOverlap(desired, data)
+	if err := b.DeleteElements(ctx, getKeys(del)...); err != nil {
+		return err
+	}
+	return b.PutElements(ctx, put)
+}
+
+func (b *InMemoryBuffer) Capacity(ctx context.Context) (used int, total int, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.state), b.capacity, nil
+}
@@ -0,0 +1,67 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// Both implementations of Buffer behave the same.
+func TestBuffer_Implementations(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	algorand, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	for name, buffer := range map[string]Buffer{"algorand": algorand, "memory": NewInMemoryBuffer()} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			assert.Nil(t, buffer.PutElements(ctx, map[string]string{"a": "1", "b": "2"}))
+			ok, err := buffer.Contains(ctx, map[string]string{"a": "1"})
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			value, err := buffer.GetBytes(ctx, "b")
+			assert.Nil(t, err)
+			assert.Equal(t, "2", string(value))
+			_, err = buffer.GetBytes(ctx, "c")
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+
+			assert.Nil(t, buffer.AchieveDesiredState(ctx, map[string]string{"b": "3", "c": "4"}))
+			data, err := buffer.GetBuffer(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]string{"b": "3", "c": "4"}, data)
+
+			assert.Nil(t, buffer.DeleteElements(ctx, "b"))
+			used, total, err := buffer.Capacity(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, 1, used)
+			assert.Equal(t, 64, total)
+
+			assert.ErrorIs(t, buffer.PutElements(ctx, map[string]string{strings.Repeat("k", 65): "v"}), ErrKeyTooLong)
+			assert.ErrorIs(t, buffer.PutElements(ctx, map[string]string{"k": strings.Repeat("v", 128)}), ErrValueTooLong)
+		})
+	}
+}
+
+func TestInMemoryBuffer_Capacity(t *testing.T) {
+	buffer := NewInMemoryBufferWithCapacity(2)
+	ctx := context.Background()
+	assert.Nil(t, buffer.PutElements(ctx, map[string]string{"a": "1", "b": "2"}))
+	assert.ErrorIs(t, buffer.PutElements(ctx, map[string]string{"a": "3", "c": "4"}), ErrBufferFull)
+	// failed writes don't store anything
+	data, _ := buffer.GetBuffer(ctx)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, data)
+	// existing keys don't take up capacity
+	assert.Nil(t, buffer.PutElements(ctx, map[string]string{"a": "3"}))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := buffer.GetBuffer(cancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,143 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Metrics receives the metrics of an AlgorandBuffer as they change (see
+// ManageConfig.Metrics). Implement it with the metric types of your monitoring library,
+// e.g. a Prometheus counter for the transactions sent, without the buffer depending on
+// it. Methods are called synchronously by the buffer, so they must not block.
+type Metrics interface {
+	// CycleCompleted is called after every cycle of the management loop (see Manage)
+	// with its duration. err is nil if the cycle succeeded, so the time since the last
+	// successful cycle can be tracked by setting a gauge to the current time.
+	CycleCompleted(duration time.Duration, err error)
+
+	// TransactionsSent is called with the number of confirmed transactions of every
+	// write, like PutElements and DeleteElements.
+	TransactionsSent(n int)
+
+	// TransactionFailed is called for every transaction that failed to be submitted or
+	// confirmed.
+	TransactionFailed(err error)
+
+	// KeyCount is called with the number of keys of the global state (excluding
+	// reserved keys), after every successful cycle of the management loop.
+	KeyCount(n int)
+
+	// ConfirmationLatency is called with the time it took the node to confirm a
+	// transaction, from submission until confirmation.
+	ConfirmationLatency(d time.Duration)
+}
+
+// bufferMetrics are the counters of an AlgorandBuffer.
+type bufferMetrics struct {
+	// storeTxns is the number of confirmed transactions that stored elements
+	storeTxns uint64
+	// deleteTxns is the number of confirmed transactions that deleted elements
+	deleteTxns uint64
+	// feesPaid is the sum of fees of all confirmed transactions in microAlgos
+	feesPaid uint64
+	// poolFull is the number of submissions rejected because the transaction pool was full
+	poolFull uint64
+	// nearLimit is the number of warnings about writes close to a limit
+	nearLimit uint64
+	// failedTxns is the number of transactions that failed to be submitted or confirmed
+	failedTxns uint64
+}
+
+// WritePrometheus writes the metrics of the buffer to w in the Prometheus text
+// exposition format. This includes the number of store, delete and failed
+// transactions, the cycles of the management loop and the time of the last successful
+// one, the spent fees, the submissions rejected by a full transaction pool, the warnings about
+// writes close to a limit, the fill level of the global state, a summary of the
+// confirmation latency and the health of the node. The health and fill level are requested from
+// the node, so this blocks for up to one timeout length. If the global state can't
+// be read, the fill level is left out.
+//
+// Serve it with a few lines, without importing the Prometheus client:
+//
+//	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+//	    _ = buffer.WritePrometheus(w)
+//	})
+func (ab *AlgorandBuffer) WritePrometheus(w io.Writer) error {
+	ab.mu.Lock()
+	m := ab.metrics
+	loop := ab.loop
+	ab.mu.Unlock()
+
+	var b strings.Builder
+	writeMetric(&b, "siam_store_transactions_total", "counter",
+		"Number of confirmed transactions that stored elements.", float64(m.storeTxns))
+	writeMetric(&b, "siam_delete_transactions_total", "counter",
+		"Number of confirmed transactions that deleted elements.", float64(m.deleteTxns))
+	writeMetric(&b, "siam_failed_transactions_total", "counter",
+		"Number of transactions that failed to be submitted or confirmed.", float64(m.failedTxns))
+	writeMetric(&b, "siam_cycles_total", "counter",
+		"Number of cycles of the management loop.", float64(loop.iterations))
+	if !loop.succeeded.IsZero() {
+		writeMetric(&b, "siam_last_successful_cycle_timestamp_seconds", "gauge",
+			"Unix time of the last cycle of the management loop without error.",
+			float64(loop.succeeded.UnixNano())/float64(time.Second))
+	}
+	writeMetric(&b, "siam_fees_paid_microalgos_total", "counter",
+		"Sum of fees of confirmed transactions in microAlgos.", float64(m.feesPaid))
+	writeMetric(&b, "siam_pool_full_total", "counter",
+		"Number of submissions rejected because the transaction pool of the node was full.", float64(m.poolFull))
+	writeMetric(&b, "siam_near_limit_warnings_total", "counter",
+		"Number of writes that came close to the maximum value length or capacity.", float64(m.nearLimit))
+
+	up := 0.0
+	if ab.Health() == nil {
+		up = 1
+	}
+	writeMetric(&b, "siam_node_up", "gauge", "Whether the Algorand node is healthy.", up)
+
+	data, err := ab.GetBufferRaw(context.Background())
+	if err == nil {
+		writeMetric(&b, "siam_global_state_keys", "gauge",
+			"Number of keys in the global state of the application.", float64(len(data)))
+		writeMetric(&b, "siam_global_state_capacity_keys", "gauge",
+			"Maximum number of keys in the global state of the application.", float64(ab.capacity()))
+	}
+
+	latency := ab.ConfirmationLatency()
+	sum, count := ab.latency.totals()
+	name := "siam_confirmation_latency_seconds"
+	fmt.Fprintf(&b, "# HELP %s Transaction confirmation latencies, with percentiles of recent ones.\n", name)
+	fmt.Fprintf(&b, "# TYPE %s summary\n", name)
+	fmt.Fprintf(&b, "%s{quantile=\"0.5\"} %g\n", name, latency.P50.Seconds())
+	fmt.Fprintf(&b, "%s{quantile=\"0.95\"} %g\n", name, latency.P95.Seconds())
+	fmt.Fprintf(&b, "%s{quantile=\"0.99\"} %g\n", name, latency.P99.Seconds())
+	fmt.Fprintf(&b, "%s_sum %g\n", name, sum.Seconds())
+	fmt.Fprintf(&b, "%s_count %d\n", name, count)
+	writeMetric(&b, "siam_confirmation_latency_samples", "gauge",
+		"Number of latencies the percentiles are computed from.", float64(latency.Count))
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// writeMetric writes a single metric without labels in the Prometheus text format.
+func writeMetric(b *strings.Builder, name, metricType, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", name, metricType)
+	fmt.Fprintf(b, "%s %g\n", name, value)
+}
+
+// observeKeyCount reports the number of keys of the global state to the Metrics, if
+// configured.
+func (ab *AlgorandBuffer) observeKeyCount(ctx context.Context) {
+	if ab.config.Metrics == nil {
+		return
+	}
+	state, err := ab.userState(ctx)
+	if err == nil {
+		ab.config.Metrics.KeyCount(len(state))
+	}
+}
@@ -0,0 +1,140 @@
+//go:build unit
+
+package siam
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	prometheusComment = regexp.MustCompile(`^# (HELP [a-z_]+ .+|TYPE [a-z_]+ (counter|gauge|summary))$`)
+	prometheusSample  = regexp.MustCompile(`^[a-z_]+(\{[a-z_]+="[^"]*"\})? -?[0-9.e+-]+$`)
+)
+
+func TestAlgorandBuffer_WritePrometheus(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.PendingTXNInfo = models.PendingTransactionInfoResponse{ConfirmedRound: 1,
+		Transaction: types.SignedTxn{Txn: types.Transaction{Header: types.Header{Fee: 1000}}}}
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "1", "b": "2"}))
+	assert.Nil(t, buffer.DeleteElements(context.Background(), "a"))
+
+	var w bytes.Buffer
+	assert.Nil(t, buffer.WritePrometheus(&w))
+	out := w.String()
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		assert.True(t, prometheusComment.MatchString(line) || prometheusSample.MatchString(line), line)
+	}
+	assert.Contains(t, out, "siam_store_transactions_total 1\n")
+	assert.Contains(t, out, "siam_delete_transactions_total 1\n")
+	assert.Contains(t, out, "siam_fees_paid_microalgos_total 2000\n")
+	assert.Contains(t, out, "siam_node_up 1\n")
+	assert.Contains(t, out, "siam_global_state_keys 1\n")
+	assert.Contains(t, out, "siam_global_state_capacity_keys 64\n")
+	assert.Contains(t, out, "# TYPE siam_confirmation_latency_seconds summary\n")
+	assert.Contains(t, out, `siam_confirmation_latency_seconds{quantile="0.99"}`)
+	assert.Contains(t, out, "siam_confirmation_latency_seconds_sum ")
+	assert.Contains(t, out, "siam_confirmation_latency_seconds_count 2\n")
+	assert.Contains(t, out, "siam_confirmation_latency_samples 2\n")
+}
+
+// The fill level is left out, if the global state can't be read.
+func TestAlgorandBuffer_WritePrometheusUnhealthy(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	c.SetError(true, (*client.AlgorandMock).HealthCheck, (*client.AlgorandMock).GetApplicationByID)
+
+	var w bytes.Buffer
+	assert.Nil(t, buffer.WritePrometheus(&w))
+	assert.Contains(t, w.String(), "siam_node_up 0\n")
+	assert.NotContains(t, w.String(), "siam_global_state_keys")
+}
+
+// recordingMetrics records the calls of the Metrics interface.
+type recordingMetrics struct {
+	mu        sync.Mutex
+	cycles    []error
+	sent      int
+	failed    []error
+	keys      int
+	latencies int
+}
+
+func (m *recordingMetrics) CycleCompleted(_ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cycles = append(m.cycles, err)
+}
+
+func (m *recordingMetrics) TransactionsSent(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent += n
+}
+
+func (m *recordingMetrics) TransactionFailed(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failed = append(m.failed, err)
+}
+
+func (m *recordingMetrics) KeyCount(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = n
+}
+
+func (m *recordingMetrics) ConfirmationLatency(time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies++
+}
+
+func TestAlgorandBuffer_Metrics(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	metrics := &recordingMetrics{}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{Metrics: metrics})
+	assert.Nil(t, err)
+
+	assert.Nil(t, buffer.QueueElements(map[string]string{"a": "1", "b": "2"}))
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, []error{nil}, metrics.cycles)
+	assert.Equal(t, 1, metrics.sent)
+	assert.Equal(t, 1, metrics.latencies)
+	assert.Equal(t, 2, metrics.keys)
+
+	c.SetError(true, (*client.AlgorandMock).StoreGlobals)
+	assert.NotNil(t, buffer.PutElements(context.Background(), map[string]string{"c": "3"}))
+	assert.Len(t, metrics.failed, 1)
+	assert.Equal(t, 1, metrics.sent)
+
+	c.SetError(true, (*client.AlgorandMock).AccountInformation)
+	assert.NotNil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, metrics.cycles, 2)
+	assert.NotNil(t, metrics.cycles[1])
+
+	var w bytes.Buffer
+	assert.Nil(t, buffer.WritePrometheus(&w))
+	assert.Contains(t, w.String(), "siam_cycles_total 2\n")
+	assert.Contains(t, w.String(), "siam_failed_transactions_total 1\n")
+	assert.Contains(t, w.String(), "siam_last_successful_cycle_timestamp_seconds ")
+}
@@ -0,0 +1,118 @@
+package siam
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// MigrateTo moves the buffer to a new application with the given TEAL programs (e.g.
+// with a different approval program). It creates the new app, copies the current
+// global state into it and verifies the copy. Only then the old app is deleted and
+// the buffer publishes to the new app. If the creation can't be confirmed, or copying
+// or verification fails, the new app is deleted again and the buffer keeps using the
+// old one. The old app is deleted like extra apps of the management loop, i.e. after
+// the local states of ManageConfig.OptedInAccounts are removed.
+//
+// The new programs must accept the same "put" calls as approval.teal, and must let
+// the creator delete the app.
+//
+// Cycles of the management loop (see Manage) and reconciliations (see ReconcileOnce)
+// wait until the migration is done, so they don't delete either app while both
+// exist. Other writes to the buffer during the migration may not be copied.
+func (ab *AlgorandBuffer) MigrateTo(newApproval, newClear string) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	ab.cycleMu.Lock()
+	defer ab.cycleMu.Unlock()
+	ab.reconcileMu.Lock()
+	defer ab.reconcileMu.Unlock()
+	ctx := context.Background()
+	data, err := ab.GetBufferRaw(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = ab.spendTxnFees(ctx, createTxnSize)
+	if err != nil {
+		return err
+	}
+	newId, err := ab.createApp(newApproval, newClear)
+	if err != nil {
+		return err
+	}
+	oldId := ab.currentAppId()
+	ab.mu.Lock()
+	oldApp := models.Application{Id: oldId, Params: models.ApplicationParams{
+		GlobalStateSchema: ab.schema,
+		LocalStateSchema:  ab.localSchema,
+	}}
+	ab.mu.Unlock()
+	// abort switches back to the old app and deletes the new one
+	abort := func(err error) error {
+		ab.storeAppId(oldId)
+		ab.observeSchema(oldApp)
+		if delErr := ab.Client.DeleteApplication(ab.AccountCrypt, newId); delErr != nil {
+			return fmt.Errorf("migration failed: %s. could not delete new app %d: %s", err, newId, ab.observeSubmitError(delErr))
+		}
+		return fmt.Errorf("migration failed: %s", err)
+	}
+	err = ab.awaitFinality(ctx, ab.config.CreateConfirmation, 0)
+	if err != nil {
+		return abort(err)
+	}
+	appCtx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	newApp, err := ab.Client.GetApplicationByID(newId, appCtx)
+	cancel()
+	if err != nil {
+		return abort(err)
+	}
+
+	ab.storeAppId(newId)
+	ab.observeSchema(newApp)
+	err = ab.copyAndVerify(ctx, data)
+	if err != nil {
+		return abort(err)
+	}
+	ab.emitEvent(ManageEvent{AppId: newId})
+	ab.publishApp(newId, true)
+
+	err = ab.deleteApp(ctx, oldId)
+	if err != nil {
+		return fmt.Errorf("migrated to app %d, but could not delete old app %d: %w", newId, oldId, err)
+	}
+	return nil
+}
+
+// copyAndVerify writes data into the buffer's application and verifies that the
+// application state equals data afterwards. The copy isn't subject to churn
+// suppression, as the keys were recently written to the old app.
+func (ab *AlgorandBuffer) copyAndVerify(ctx context.Context, data map[string][]byte) error {
+	if len(data) > 0 {
+		batches, err := ab.rawBatches(data)
+		if err != nil {
+			return err
+		}
+		err = ab.writeBatches(ctx, batches, client.WriteOptions{}, false)
+		if err != nil {
+			return err
+		}
+	}
+	copied, err := ab.GetBufferRaw(ctx)
+	if err != nil {
+		return err
+	}
+	if len(copied) != len(data) {
+		return fmt.Errorf("new app has %d keys instead of %d", len(copied), len(data))
+	}
+	for k, v := range data {
+		if !bytes.Equal(copied[k], v) {
+			return fmt.Errorf("value of key %q differs in new app", k)
+		}
+	}
+	return nil
+}
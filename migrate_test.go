@@ -0,0 +1,194 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_MigrateTo(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	data := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		data[strconv.Itoa(i)] = "value" + strconv.Itoa(i)
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+	oldId := buffer.AppId
+
+	assert.Nil(t, buffer.MigrateTo(client.ApproveTeal+"\n// v2", client.ClearTeal))
+	assert.NotEqual(t, oldId, buffer.AppId)
+
+	// state is preserved
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, d)
+
+	// old app is removed
+	_, err = l.GetApplicationByID(oldId, context.Background())
+	assert.NotNil(t, err)
+	info, _ := l.AccountInformation(buffer.AccountCrypt.Address.String(), context.Background())
+	assert.True(t, client.ValidAccount(info))
+	assert.Equal(t, buffer.AppId, info.CreatedApps[0].Id)
+	app, _ := l.GetApplicationByID(buffer.AppId, context.Background())
+	assert.Contains(t, string(app.Params.ApprovalProgram), "// v2")
+}
+
+// If the state can't be copied, the new app is deleted and the buffer keeps
+// using the old app
+func TestAlgorandBuffer_MigrateToFailure(t *testing.T) {
+	l := client.NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	// enough funds for creating a second app, but not for copying data into it
+	mbr := uint64(client.MinBalance + 2*(client.AppMinBalance+
+		(client.SchemaMinBalance+client.SchemaBytesMinBalance)*client.GlobalBytes))
+	l.Fund(acc.Address, mbr+3*client.MinTxnFee)
+	buffer, err := NewAlgorandBuffer(l, base64.StdEncoding.EncodeToString(acc.PrivateKey))
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"x": "y"}))
+	oldId := buffer.AppId
+
+	err = buffer.MigrateTo(client.ApproveTeal, client.ClearTeal)
+	assert.NotNil(t, err)
+	assert.Equal(t, oldId, buffer.AppId)
+	d, _ := buffer.GetBuffer(context.Background())
+	assert.Equal(t, map[string]string{"x": "y"}, d)
+	info, _ := l.AccountInformation(acc.Address.String(), context.Background())
+	assert.Len(t, info.CreatedApps, 1)
+}
+
+// Keys written within MinWriteInterval are still copied into the new app
+func TestAlgorandBuffer_MigrateToMinWriteInterval(t *testing.T) {
+	l := client.NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 10000000)
+	cfg := ManageConfig{MinWriteInterval: time.Hour}
+	buffer, err := NewAlgorandBufferWithConfig(l, base64.StdEncoding.EncodeToString(acc.PrivateKey), cfg)
+	assert.Nil(t, err)
+	data := map[string]string{"price": "1", "volume": "9"}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+
+	assert.Nil(t, buffer.MigrateTo(client.ApproveTeal+"\n// v2", client.ClearTeal))
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, d)
+}
+
+// The management loop doesn't delete the new app while the migration is running
+func TestAlgorandBuffer_MigrateToWhileManaging(t *testing.T) {
+	l := client.NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	l.Fund(acc.Address, 100000000)
+	cfg := ManageConfig{SleepInterval: time.Millisecond}
+	buffer, err := NewAlgorandBufferWithConfig(l, base64.StdEncoding.EncodeToString(acc.PrivateKey), cfg)
+	assert.Nil(t, err)
+	data := map[string]string{"x": "y"}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		buffer.ManageContext(ctx)
+		close(done)
+	}()
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, buffer.MigrateTo(client.ApproveTeal+"\n// v"+strconv.Itoa(i), client.ClearTeal))
+	}
+	cancel()
+	<-done
+
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, d)
+	info, _ := l.AccountInformation(acc.Address.String(), context.Background())
+	assert.Len(t, info.CreatedApps, 1)
+}
+
+// migrationLedger is a FakeLedger whose finality checks can fail, and that opts an
+// account into every app.
+type migrationLedger struct {
+	*client.FakeLedger
+	failFinality bool
+	creator      types.Address
+	optedIn      types.Address
+	cleared      []uint64
+}
+
+func (l *migrationLedger) StatusAfterBlock(round uint64, ctx context.Context) (models.NodeStatus, error) {
+	if l.failFinality {
+		return models.NodeStatus{}, errors.New("node unavailable")
+	}
+	return l.FakeLedger.StatusAfterBlock(round, ctx)
+}
+
+func (l *migrationLedger) AccountInformation(addr string, ctx context.Context) (models.Account, error) {
+	if addr == l.optedIn.String() {
+		info, _ := l.FakeLedger.AccountInformation(l.creator.String(), ctx)
+		local := make([]models.ApplicationLocalState, 0)
+		for _, app := range info.CreatedApps {
+			local = append(local, models.ApplicationLocalState{Id: app.Id})
+		}
+		return models.Account{Address: addr, AppsLocalState: local}, nil
+	}
+	return l.FakeLedger.AccountInformation(addr, ctx)
+}
+
+func (l *migrationLedger) ExecuteTransaction(acc crypto.Account, txn types.Transaction, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	if txn.OnCompletion == types.ClearStateOC {
+		l.cleared = append(l.cleared, uint64(txn.ApplicationID))
+		return models.PendingTransactionInfoResponse{}, nil
+	}
+	return l.FakeLedger.ExecuteTransaction(acc, txn, ctx)
+}
+
+// If the creation of the new app can't be confirmed, the new app is deleted again
+func TestAlgorandBuffer_MigrateToFinalityFailure(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	l := &migrationLedger{FakeLedger: client.NewFakeLedger(), creator: acc.Address}
+	l.Fund(acc.Address, 10000000)
+	cfg := ManageConfig{CreateConfirmation: ConfirmationPolicy{FinalityRounds: 1}}
+	buffer, err := NewAlgorandBufferWithConfig(l, base64.StdEncoding.EncodeToString(acc.PrivateKey), cfg)
+	assert.Nil(t, err)
+	oldId := buffer.AppId
+
+	l.failFinality = true
+	assert.NotNil(t, buffer.MigrateTo(client.ApproveTeal+"\n// v2", client.ClearTeal))
+	assert.Equal(t, oldId, buffer.AppId)
+	info, _ := l.AccountInformation(acc.Address.String(), context.Background())
+	assert.Len(t, info.CreatedApps, 1)
+	assert.Equal(t, oldId, info.CreatedApps[0].Id)
+}
+
+// The old app is deleted like the extra apps of the management loop, and the buffer
+// adopts the schema of the new app
+func TestAlgorandBuffer_MigrateToDeletesOldApp(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	opted := crypto.GenerateAccount()
+	l := &migrationLedger{FakeLedger: client.NewFakeLedger(), creator: acc.Address, optedIn: opted.Address}
+	l.Fund(acc.Address, 10000000)
+	small := client.SchemaSpec{GlobalBytes: 16}
+	oldId, err := client.CreateApplicationWithSchema(l, acc, client.ApproveTeal, client.ClearTeal, small)
+	assert.Nil(t, err)
+	cfg := ManageConfig{AdaptToDeployedSchema: true, OptedInAccounts: []crypto.Account{opted}}
+	buffer, err := NewAlgorandBufferWithConfig(l, base64.StdEncoding.EncodeToString(acc.PrivateKey), cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, oldId, buffer.AppId)
+	assert.Equal(t, 16-len(buffer.reservedKeys()), buffer.capacity())
+
+	assert.Nil(t, buffer.MigrateTo(client.ApproveTeal+"\n// v2", client.ClearTeal))
+	assert.Equal(t, []uint64{oldId}, l.cleared)
+	assert.Equal(t, client.GlobalBytes-len(buffer.reservedKeys()), buffer.capacity())
+	info, _ := l.AccountInformation(acc.Address.String(), context.Background())
+	assert.Len(t, info.CreatedApps, 1)
+	assert.Equal(t, buffer.AppId, info.CreatedApps[0].Id)
+}
@@ -0,0 +1,84 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+)
+
+// PrepareMultisigPut returns the unsigned write of the given key-value pairs, to be
+// signed by the keys of the multisig account ma (see client.MultisigSigner). Use it
+// for data that must be approved by M of N signers: rekey the target account to the
+// address of ma (see Rekey), collect the signatures with AppendSignature, and submit
+// the write with SubmitMultisig. The pairs must fit into a single transaction (see
+// ManageConfig.BatchSize), and are checked like in PutElements.
+func (ab *AlgorandBuffer) PrepareMultisigPut(ctx context.Context, ma crypto.MultisigAccount, data map[string]string) (*client.MultisigSigner, error) {
+	if err := ab.checkWritable(); err != nil {
+		return nil, err
+	}
+	m := make(map[string][]byte, len(data))
+	for k, v := range data {
+		value, err := ab.config.ValueEncoding.decode(v)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = value
+	}
+	batches, err := ab.rawBatches(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) != 1 {
+		return nil, fmt.Errorf("a multisig write stores at most %d pairs, got %d", ab.batchSize(), len(data))
+	}
+	if err := ab.validateSpec(batches); err != nil {
+		return nil, err
+	}
+	if err := ab.checkCapacity(ctx, batches); err != nil {
+		return nil, err
+	}
+	txn, err := client.StoreGlobalsTx(ab.Client, ab.AccountCrypt, ab.currentAppId(), batches[0])
+	if err != nil {
+		return nil, err
+	}
+	return client.NewMultisigSigner(ma, txn)
+}
+
+// SubmitMultisig submits a write prepared by PrepareMultisigPut, and waits for its
+// confirmation. Returns client.ErrMultisigThreshold without submitting anything, if
+// the write hasn't been signed by enough keys of the multisig account yet.
+func (ab *AlgorandBuffer) SubmitMultisig(ctx context.Context, s *client.MultisigSigner) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	if _, err := s.SignedTransaction(); err != nil {
+		return err
+	}
+	if err := ab.beginWrite(); err != nil {
+		return err
+	}
+	defer ab.active.Done()
+	if err := ab.spendFees(ctx, 1); err != nil {
+		return err
+	}
+	start := ab.now()
+	ctx, cancel := context.WithTimeout(ctx, ab.writeTimeout())
+	result, err := client.SubmitMultisig(ab.Client, s, ctx)
+	cancel()
+	if err != nil {
+		return ab.observeSubmitError(err)
+	}
+	ab.observeLatency(start)
+	args := s.Txn.ApplicationArgs
+	kvArray := make([]models.TealKeyValue, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		kvArray = append(kvArray, models.TealKeyValue{Key: string(args[i]), Value: models.TealValue{Bytes: string(args[i+1])}})
+	}
+	ab.recordWrites(kvArray, start)
+	ab.trackKeys(kvArray)
+	ab.observeResults(&ab.metrics.storeTxns, []models.PendingTransactionInfoResponse{result})
+	return nil
+}
@@ -0,0 +1,56 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// A write to a buffer account rekeyed to a 2-of-3 multisig account
+func TestAlgorandBuffer_Multisig(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	members := []crypto.Account{crypto.GenerateAccount(), crypto.GenerateAccount(), crypto.GenerateAccount()}
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{members[0].Address, members[1].Address, members[2].Address})
+	assert.Nil(t, err)
+	addr, err := ma.Address()
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.Rekey(context.Background(), addr))
+
+	data := map[string]string{"price": "42"}
+	s, err := buffer.PrepareMultisigPut(context.Background(), ma, data)
+	assert.Nil(t, err)
+	assert.NotNil(t, s.AppendSignature(crypto.GenerateAccount()), "only members sign")
+	assert.Nil(t, s.AppendSignature(members[0]))
+	assert.Equal(t, 1, s.Signatures())
+
+	// below the threshold, nothing is submitted
+	assert.ErrorIs(t, buffer.SubmitMultisig(context.Background(), s), client.ErrMultisigThreshold)
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Empty(t, d)
+
+	assert.Nil(t, s.AppendSignature(members[2]))
+	assert.Equal(t, 2, s.Signatures())
+	assert.Nil(t, buffer.SubmitMultisig(context.Background(), s))
+	d, err = buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, d)
+}
+
+func TestAlgorandBuffer_PrepareMultisigPut_TooMany(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	ma, err := crypto.MultisigAccountWithParams(1, 1, []types.Address{crypto.GenerateAccount().Address})
+	assert.Nil(t, err)
+	data := make(map[string]string)
+	for i := 0; i <= buffer.batchSize(); i++ {
+		data[string(rune('a'+i))] = "v"
+	}
+	_, err = buffer.PrepareMultisigPut(context.Background(), ma, data)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,51 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// warnNearLimits reports the stored pairs that reach ManageConfig.WarnValueRatio of the
+// maximum pair length, and whether the write crossed ManageConfig.WarnCapacityRatio of
+// the capacity. It's called after every successful write, including deletes, so
+// that the buffer notices when it drops below the capacity threshold.
+func (ab *AlgorandBuffer) warnNearLimits(ctx context.Context, batches [][]models.TealKeyValue) {
+	if ratio := ab.config.WarnValueRatio; ratio > 0 {
+		for _, kvArray := range batches {
+			for _, kv := range kvArray {
+				length := len(kv.Key) + len(kv.Value.Bytes)
+				if ab.isCompanionKey(kv.Key) || float64(length) < ratio*maxPairLength {
+					continue
+				}
+				ab.warnNearLimit(fmt.Errorf("%w: kv pair {%s} has %d of %d bytes", ErrNearLimit, kv.Key, length, maxPairLength))
+			}
+		}
+	}
+
+	ratio := ab.config.WarnCapacityRatio
+	if ratio <= 0 {
+		return
+	}
+	data, err := ab.GetBufferRaw(ctx)
+	if err != nil {
+		// the write succeeded, the capacity is checked again on the next one
+		return
+	}
+	near := float64(len(data)) >= ratio*float64(ab.capacity())
+	ab.mu.Lock()
+	crossed := near && !ab.nearCapacity
+	ab.nearCapacity = near
+	ab.mu.Unlock()
+	if crossed {
+		ab.warnNearLimit(fmt.Errorf("%w: %d of %d keys stored", ErrNearLimit, len(data), ab.capacity()))
+	}
+}
+
+func (ab *AlgorandBuffer) warnNearLimit(err error) {
+	ab.mu.Lock()
+	ab.metrics.nearLimit++
+	ab.mu.Unlock()
+	ab.emitEvent(ManageEvent{AppId: ab.currentAppId(), Err: err})
+}
@@ -0,0 +1,81 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// nearLimitEvents returns the ErrNearLimit events on the channel of the buffer.
+func nearLimitEvents(buffer *AlgorandBuffer) []error {
+	errs := make([]error, 0)
+	for {
+		select {
+		case e := <-buffer.AppChannel:
+			if errors.Is(e.Err, ErrNearLimit) {
+				errs = append(errs, e.Err)
+			}
+		default:
+			return errs
+		}
+	}
+}
+
+func TestAlgorandBuffer_WarnValueRatio(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{WarnValueRatio: 0.9})
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	// 1 + 113 bytes stay below 90% of 128 bytes
+	assert.Nil(t, buffer.PutElements(ctx, map[string]string{"a": strings.Repeat("x", 113)}))
+	assert.Empty(t, nearLimitEvents(buffer))
+
+	assert.Nil(t, buffer.PutElements(ctx, map[string]string{"a": strings.Repeat("x", 115)}))
+	errs := nearLimitEvents(buffer)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "116 of 128 bytes")
+	stored, err := buffer.GetBuffer(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, stored["a"], 115)
+}
+
+func TestAlgorandBuffer_WarnCapacityRatio(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{WarnCapacityRatio: 0.5})
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	data := make(map[string]string)
+	for i := 0; i < buffer.capacity()/2-1; i++ {
+		data[strconv.Itoa(i)] = "v"
+	}
+	assert.Nil(t, buffer.PutElements(ctx, data))
+	assert.Empty(t, nearLimitEvents(buffer))
+
+	// crossing the threshold is reported once
+	assert.Nil(t, buffer.PutElements(ctx, map[string]string{"a": "v"}))
+	assert.Len(t, nearLimitEvents(buffer), 1)
+	assert.Nil(t, buffer.PutElements(ctx, map[string]string{"b": "v"}))
+	assert.Empty(t, nearLimitEvents(buffer))
+
+	var b strings.Builder
+	assert.Nil(t, buffer.WritePrometheus(&b))
+	assert.Contains(t, b.String(), "siam_near_limit_warnings_total 1\n")
+
+	// and again after dropping below it
+	assert.Nil(t, buffer.DeleteElements(ctx, "a", "b"))
+	assert.Nil(t, buffer.PutElements(ctx, map[string]string{"c": "v"}))
+	assert.Len(t, nearLimitEvents(buffer), 1)
+}
@@ -0,0 +1,45 @@
+package siam
+
+import (
+	"context"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// paramsCache holds the suggested params of the last request. Guarded by the mutex
+// of the buffer.
+type paramsCache struct {
+	params  types.SuggestedParams
+	fetched time.Time
+}
+
+// SuggestedParams returns the suggested transaction parameters of the node. Use them to
+// build your own transactions against the application (e.g. for admin tools). If
+// ManageConfig.ParamsCacheTTL is set, params are cached for that long. Keep in mind
+// that the validity window of cached params starts at the round in which they were
+// fetched.
+func (ab *AlgorandBuffer) SuggestedParams(ctx context.Context) (types.SuggestedParams, error) {
+	ttl := ab.config.ParamsCacheTTL
+	if ttl > 0 {
+		ab.mu.Lock()
+		cached := ab.params
+		ab.mu.Unlock()
+		if !cached.fetched.IsZero() && ab.now().Sub(cached.fetched) < ttl {
+			return cached.params, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
+	params, err := ab.Client.SuggestedParams(ctx)
+	cancel()
+	if err != nil {
+		return types.SuggestedParams{}, err
+	}
+	if ttl > 0 {
+		ab.mu.Lock()
+		ab.params = paramsCache{params: params, fetched: ab.now()}
+		ab.mu.Unlock()
+	}
+	return params, nil
+}
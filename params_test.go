@@ -0,0 +1,72 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// paramsCountingMock counts the calls of SuggestedParams.
+type paramsCountingMock struct {
+	*client.AlgorandMock
+	suggestedParams int
+}
+
+func (m *paramsCountingMock) SuggestedParams(ctx context.Context) (types.SuggestedParams, error) {
+	m.suggestedParams++
+	return m.AlgorandMock.SuggestedParams(ctx)
+}
+
+func TestAlgorandBuffer_SuggestedParams(t *testing.T) {
+	c := &paramsCountingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.Params = types.SuggestedParams{Fee: 1000, FlatFee: true, MinFee: 1000, FirstRoundValid: 10, LastRoundValid: 1010}
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		params, err := buffer.SuggestedParams(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, c.Params, params)
+	}
+	assert.Equal(t, 2, c.suggestedParams)
+}
+
+func TestAlgorandBuffer_SuggestedParamsCached(t *testing.T) {
+	c := &paramsCountingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.Params = types.SuggestedParams{Fee: 1000, FlatFee: true, MinFee: 1000, FirstRoundValid: 10, LastRoundValid: 1010}
+	cfg := ManageConfig{ParamsCacheTTL: time.Minute}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	now := time.Unix(1000, 0)
+	buffer.now = func() time.Time { return now }
+
+	params, err := buffer.SuggestedParams(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, c.Params, params)
+
+	c.Params.FirstRoundValid = 20
+	now = now.Add(time.Second * 59)
+	params, _ = buffer.SuggestedParams(context.Background())
+	assert.EqualValues(t, 10, params.FirstRoundValid)
+	assert.Equal(t, 1, c.suggestedParams)
+
+	now = now.Add(time.Second)
+	params, _ = buffer.SuggestedParams(context.Background())
+	assert.EqualValues(t, 20, params.FirstRoundValid)
+	assert.Equal(t, 2, c.suggestedParams)
+}
+
+func TestAlgorandBuffer_SuggestedParamsError(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ParamsCacheTTL: time.Minute})
+	assert.Nil(t, err)
+	c.SetError(true, (*client.AlgorandMock).SuggestedParams)
+	_, err = buffer.SuggestedParams(context.Background())
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,17 @@
+package siam
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// PendingTransactions returns up to max transactions of the target account that are in
+// the transaction pool of the node, or all of them if max is zero. This includes
+// transactions the buffer doesn't track, e.g. those of another process using the same
+// account, which helps to diagnose stuck updates.
+func (ab *AlgorandBuffer) PendingTransactions(ctx context.Context, max uint64) ([]types.SignedTxn, error) {
+	ctx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	defer cancel()
+	return ab.Client.PendingTransactionsByAddress(ab.AccountCrypt.Address.String(), max, ctx)
+}
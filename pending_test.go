@@ -0,0 +1,41 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_PendingTransactions(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	own := buffer.AccountCrypt.Address
+	other := crypto.GenerateAccount().Address
+	c.PendingTXNs = []types.SignedTxn{
+		{Txn: types.Transaction{Header: types.Header{Sender: own, Note: []byte("a")}}},
+		{Txn: types.Transaction{Header: types.Header{Sender: other}}},
+		{Txn: types.Transaction{Header: types.Header{Sender: own, Note: []byte("b")}}},
+	}
+	txns, err := buffer.PendingTransactions(context.Background(), 0)
+	assert.Nil(t, err)
+	assert.Len(t, txns, 2)
+	assert.Equal(t, []byte("b"), txns[1].Txn.Note)
+
+	txns, err = buffer.PendingTransactions(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Len(t, txns, 1)
+
+	c.SetError(true, (*client.AlgorandMock).PendingTransactionsByAddress)
+	_, err = buffer.PendingTransactions(context.Background(), 0)
+	assert.NotNil(t, err)
+}
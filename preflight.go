@@ -0,0 +1,181 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// RemainingCapacity returns the number of keys that can still be added to the buffer.
+// Reserved keys (e.g. HeartbeatKey) don't count as stored keys.
+func (ab *AlgorandBuffer) RemainingCapacity(ctx context.Context) (int, error) {
+	data, err := ab.GetBufferRaw(ctx)
+	if err != nil {
+		return 0, err
+	}
+	remaining := ab.capacity() - len(data)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, nil
+}
+
+// MinBalanceAfter returns the balance of the target account after paying the fees of
+// writing kv, and the minimum balance the account has to keep. The write fails if the
+// balance would fall below the minimum balance.
+func (ab *AlgorandBuffer) MinBalanceAfter(ctx context.Context, kv map[string]string) (balance uint64, minBalance uint64, err error) {
+	return ab.balanceAfterTxns(ctx, batchCount(len(kv), ab.batchSize()))
+}
+
+// balanceAfterTxns returns the balance of the target account after paying the fees of
+// the given number of writes, and the minimum balance of the account. Every write is
+// charged like the largest one (see maxWriteTxnSize).
+func (ab *AlgorandBuffer) balanceAfterTxns(ctx context.Context, txns int) (balance uint64, minBalance uint64, err error) {
+	infoCtx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	info, err := ab.accountInformation(infoCtx)
+	cancel()
+	if err != nil {
+		return 0, 0, err
+	}
+	params, err := ab.SuggestedParams(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	fees := ab.txnFee(params, maxWriteTxnSize) * uint64(txns)
+	minBalance = client.MinAccountBalance(info.CreatedApps)
+	if info.Amount < fees {
+		return 0, minBalance, nil
+	}
+	return info.Amount - fees, minBalance, nil
+}
+
+// batchCount returns the number of transactions needed for n arguments, if each
+// transaction carries at most size arguments.
+func batchCount(n int, size int) int {
+	return (n + size - 1) / size
+}
+
+// checkValues returns the failed checks of the key-value pairs, sorted by key.
+func (ab *AlgorandBuffer) checkValues(kv map[string]string) []string {
+	failures := make([]string, 0)
+	keys := make([]string, 0, len(kv))
+	for k := range kv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key, err := ab.config.KeyEncoding.toState(k)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		if _, _, err := ab.fitValue(key, []byte(kv[k])); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	return failures
+}
+
+// checkBalance returns the failed balance check, if the target account can't pay the
+// fees of the given number of transactions. Returns an empty string if it can.
+func (ab *AlgorandBuffer) checkBalance(ctx context.Context, txns int) (string, error) {
+	balance, minBalance, err := ab.balanceAfterTxns(ctx, txns)
+	if err != nil {
+		return "", err
+	}
+	if balance < minBalance {
+		return fmt.Sprintf("balance of %d microAlgos after fees is below the minimum balance of %d", balance, minBalance), nil
+	}
+	return "", nil
+}
+
+// preflightError returns ErrPreflightFailed with the given failures, or nil if there
+// are none.
+func preflightError(failures []string) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrPreflightFailed, strings.Join(failures, "; "))
+}
+
+// Preflight checks whether writing kv (see PutElements) would succeed, without
+// submitting a transaction. It checks that all pairs fit the maximum length, that the
+// buffer has enough capacity for the new keys, and that the target account keeps its
+// minimum balance after paying the fees. If any check fails, ErrPreflightFailed is
+// returned with the details of all failed checks.
+func (ab *AlgorandBuffer) Preflight(ctx context.Context, kv map[string]string) error {
+	failures := ab.checkValues(kv)
+
+	data, err := ab.GetBufferRaw(ctx)
+	if err != nil {
+		return err
+	}
+	added := 0
+	for k := range kv {
+		if _, ok := data[k]; !ok {
+			added++
+		}
+	}
+	if remaining := ab.capacity() - len(data); added > remaining {
+		failures = append(failures, fmt.Sprintf("%d new keys exceed the remaining capacity of %d keys", added, remaining))
+	}
+
+	failure, err := ab.checkBalance(ctx, batchCount(len(kv), ab.batchSize()))
+	if err != nil {
+		return err
+	}
+	if failure != "" {
+		failures = append(failures, failure)
+	}
+	return preflightError(failures)
+}
+
+// PreflightPut simulates the transactions that writing kv (see PutElements) would
+// submit, with the dryrun endpoint of the node (see client.AlgorandClient.
+// SimulateTransaction). This catches rejections of the approval program, e.g. in CI,
+// without paying fees. If a transaction would be rejected, ErrPreflightFailed is
+// returned with the messages of the programs. Nothing is submitted.
+func (ab *AlgorandBuffer) PreflightPut(ctx context.Context, kv map[string]string) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	data := make(map[string][]byte, len(kv))
+	for k, v := range kv {
+		value, err := ab.config.ValueEncoding.decode(v)
+		if err != nil {
+			return err
+		}
+		data[k] = value
+	}
+	batches, _, err := ab.fittedBatches(data)
+	if err != nil {
+		return err
+	}
+	if err := ab.validateSpec(batches); err != nil {
+		return err
+	}
+	batches, err = ab.withModified(ctx, batches)
+	if err != nil {
+		return err
+	}
+	failures := make([]string, 0)
+	for _, batch := range batches {
+		txn, err := client.StoreGlobalsTx(ab.Client, ab.AccountCrypt, ab.currentAppId(), batch)
+		if err != nil {
+			return err
+		}
+		simCtx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
+		resp, err := ab.Client.SimulateTransaction(ab.AccountCrypt, txn, simCtx)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if err := client.DryrunError(resp); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	return preflightError(failures)
+}
@@ -0,0 +1,146 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_Preflight(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	kv := map[string]string{"1000": "Astralis", "1001": "Vitality"}
+	assert.Nil(t, buffer.Preflight(context.Background(), kv))
+
+	remaining, err := buffer.RemainingCapacity(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, client.GlobalBytes, remaining)
+
+	assert.Nil(t, buffer.PutElements(context.Background(), kv))
+	remaining, err = buffer.RemainingCapacity(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, client.GlobalBytes-2, remaining)
+}
+
+func TestAlgorandBuffer_PreflightValueTooLong(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	err := buffer.Preflight(context.Background(), map[string]string{"key": strings.Repeat("x", 128)})
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Contains(t, err.Error(), "key")
+}
+
+func TestAlgorandBuffer_PreflightCapacity(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	full := make(map[string]string)
+	for i := 0; i < client.GlobalBytes; i++ {
+		full[strconv.Itoa(i)] = "v"
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), full))
+
+	// overwriting stored keys needs no capacity
+	assert.Nil(t, buffer.Preflight(context.Background(), map[string]string{"0": "w"}))
+	err := buffer.Preflight(context.Background(), map[string]string{"new": "v"})
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Contains(t, err.Error(), "capacity")
+}
+
+func TestAlgorandBuffer_PreflightMinBalance(t *testing.T) {
+	l := client.NewFakeLedger()
+	acc := crypto.GenerateAccount()
+	_, glob := client.GenerateSchemasModel()
+	mbr := client.MinAccountBalance([]models.Application{{Params: models.ApplicationParams{GlobalStateSchema: glob}}})
+	// enough for the creation, but not for another transaction
+	l.Fund(acc.Address, mbr+client.MinTxnFee+500)
+	buffer, err := NewAlgorandBuffer(l, base64.StdEncoding.EncodeToString(acc.PrivateKey))
+	assert.Nil(t, err)
+
+	balance, minBalance, err := buffer.MinBalanceAfter(context.Background(), map[string]string{"k": "v"})
+	assert.Nil(t, err)
+	assert.Equal(t, mbr, minBalance)
+	assert.Equal(t, mbr-500, balance)
+
+	err = buffer.Preflight(context.Background(), map[string]string{"k": "v"})
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Contains(t, err.Error(), "minimum balance")
+	// the preflight is right: the write fails
+	assert.NotNil(t, buffer.PutElements(context.Background(), map[string]string{"k": "v"}))
+}
+
+// All failed checks are reported at once
+func TestAlgorandBuffer_PreflightCombined(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.Params.MinFee = client.MinTxnFee
+	c.Account.Amount = 0
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	err = buffer.Preflight(context.Background(), map[string]string{"key": strings.Repeat("x", 128)})
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Contains(t, err.Error(), "128 bytes")
+	assert.Contains(t, err.Error(), "minimum balance")
+
+	c.SetError(true, (*client.AlgorandMock).GetApplicationByID)
+	err = buffer.Preflight(context.Background(), map[string]string{"k": "v"})
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrPreflightFailed)
+}
+
+func TestAlgorandBuffer_PreflightPut(t *testing.T) {
+	buffer, l := newFakeLedgerBuffer(t)
+	balance := l.Balance(buffer.AccountCrypt.Address)
+	kv := make(map[string]string)
+	for i := 0; i < client.GlobalBytes-4; i++ {
+		kv[strconv.Itoa(i)] = "v"
+	}
+	assert.Nil(t, buffer.PreflightPut(context.Background(), kv))
+
+	// nothing is submitted
+	assert.Equal(t, balance, l.Balance(buffer.AccountCrypt.Address))
+	data, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Empty(t, data)
+
+	// the ledger rejects keys beyond the schema
+	assert.Nil(t, buffer.PutElements(context.Background(), kv))
+	more := map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5"}
+	err = buffer.PreflightPut(context.Background(), more)
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Contains(t, err.Error(), "exceeds schema bytes count")
+	delete(more, "e")
+	assert.Nil(t, buffer.PreflightPut(context.Background(), more))
+}
+
+func TestAlgorandBuffer_PreflightPutRejected(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	kv := map[string]string{"1000": "Astralis"}
+	assert.Nil(t, buffer.PreflightPut(context.Background(), kv))
+
+	c.DryrunResponse = models.DryrunResponse{Txns: []models.DryrunTxnResult{{AppCallMessages: []string{"REJECT"}}}}
+	err = buffer.PreflightPut(context.Background(), kv)
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Contains(t, err.Error(), "REJECT")
+	assert.Empty(t, c.App.Params.GlobalState)
+
+	c.SetError(true, (*client.AlgorandMock).SimulateTransaction)
+	err = buffer.PreflightPut(context.Background(), kv)
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrPreflightFailed)
+
+	c.ClearFunctionErrors()
+	err = buffer.PreflightPut(context.Background(), map[string]string{"k": strings.Repeat("v", 200)})
+	assert.ErrorIs(t, err, ErrValueTooLong)
+}
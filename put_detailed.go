@@ -0,0 +1,76 @@
+package siam
+
+import (
+	"context"
+	"sort"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+)
+
+// PutElementsDetailed stores the given key-value pairs like PutElements, but reports
+// the outcome of every key, so that only the failed keys need to be retried. The
+// returned map holds the error of every key that hasn't been stored, and is empty if
+// all keys have been stored. Pairs are validated one by one before submission: keys
+// that exceed 64 bytes fail with ErrKeyTooLong, and pairs that exceed 128 bytes with
+// ErrValueTooLong (see ManageConfig.TruncateOversized), without affecting the other
+// pairs. The valid pairs are then submitted in batches (see ManageConfig.BatchSize),
+// and if a batch fails, its error is reported for each of its keys. The error is only
+// returned if nothing could be written, e.g. with ErrStopped or ErrReadOnly.
+func (ab *AlgorandBuffer) PutElementsDetailed(ctx context.Context, data map[string]string) (map[string]error, error) {
+	if err := ab.checkWritable(); err != nil {
+		return nil, err
+	}
+	failed := make(map[string]error)
+	m := make(map[string][]byte, len(data))
+	for k, v := range data {
+		value, err := ab.config.ValueEncoding.decode(v)
+		if err != nil {
+			failed[k] = err
+			continue
+		}
+		m[k] = value
+	}
+	stores, deletes := ab.splitEmpty(m)
+	if len(deletes) > 0 {
+		if err := ab.DeleteElements(ctx, deletes...); err != nil {
+			for _, k := range deletes {
+				failed[k] = err
+			}
+		}
+	}
+
+	valid := make(map[string][]byte, len(stores))
+	truncated := make([]string, 0)
+	for k, v := range stores {
+		key, err := ab.config.KeyEncoding.toState(k)
+		if err != nil {
+			failed[k] = err
+			continue
+		}
+		value, wasTruncated, err := ab.fitValue(key, v)
+		if err == nil && ab.config.ContractSpec != nil {
+			err = ab.config.ContractSpec.validate(key, string(value))
+		}
+		if err != nil {
+			failed[k] = err
+			continue
+		}
+		if wasTruncated {
+			truncated = append(truncated, k)
+		}
+		valid[key] = value
+	}
+	sort.Strings(truncated)
+	ab.setTruncated(truncated)
+
+	for _, batch := range ab.batchesOf(valid) {
+		err := ab.storeBatches(ctx, [][]models.TealKeyValue{batch}, client.WriteOptions{})
+		if err != nil {
+			for _, kv := range batch {
+				failed[ab.config.KeyEncoding.fromState([]byte(kv.Key))] = err
+			}
+		}
+	}
+	return failed, nil
+}
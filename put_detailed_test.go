@@ -0,0 +1,71 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_PutElementsDetailed(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	data := make(map[string]string)
+	for i := 0; i < 28; i++ {
+		data["key"+strconv.Itoa(i)] = "value"
+	}
+	longKey := strings.Repeat("k", 65)
+	data[longKey] = "v"
+	data["long"] = strings.Repeat("v", 125)
+
+	failed, err := buffer.PutElementsDetailed(context.Background(), data)
+	assert.Nil(t, err)
+	assert.Len(t, failed, 2)
+	assert.ErrorIs(t, failed[longKey], ErrKeyTooLong)
+	assert.ErrorIs(t, failed["long"], ErrValueTooLong)
+
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, stored, 28)
+	assert.Equal(t, "value", stored["key27"])
+}
+
+// Only the keys of failed batches are reported.
+func TestAlgorandBuffer_PutElementsDetailedBatchFailure(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{BatchSize: 5})
+	assert.Nil(t, err)
+
+	data := make(map[string]string)
+	for i := 0; i < 30; i++ {
+		data["key"+strconv.Itoa(i)] = "value"
+	}
+	rejected := errors.New("rejected")
+	c.QueueResponse((*client.AlgorandMock).StoreGlobals, rejected)
+
+	failed, err := buffer.PutElementsDetailed(context.Background(), data)
+	assert.Nil(t, err)
+	assert.Len(t, failed, 5)
+	stored, _ := buffer.GetBuffer(context.Background())
+	assert.Len(t, stored, 25)
+	for k, err := range failed {
+		assert.ErrorIs(t, err, rejected)
+		assert.NotContains(t, stored, k)
+	}
+
+	buffer.Stop()
+	_, err = buffer.PutElementsDetailed(context.Background(), data)
+	assert.ErrorIs(t, err, ErrStopped)
+}
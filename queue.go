@@ -0,0 +1,209 @@
+package siam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// ErrQueueFull is returned by QueueElements, if the write queue can't hold the pairs.
+var ErrQueueFull = errors.New("write queue is full")
+
+// QueueElements queues the given key-value pairs, without waiting for them to be
+// stored. The management loop (see Manage) stores queued pairs in its next cycle. If
+// a key is queued several times, the last value wins. Returns ErrQueueFull if the
+// queue can't hold all pairs, and ErrKeyTooLong or ErrValueTooLong if a pair exceeds
+// the limits of the global state. Then, none of the pairs are queued. On shutdown,
+// queued pairs are flushed for up to ManageConfig.ShutdownGrace. Use Flush to wait
+// until they're stored.
+func (ab *AlgorandBuffer) QueueElements(data map[string]string) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	kvs := make([]models.TealKeyValue, 0, len(data))
+	for k, v := range data {
+		value, err := ab.config.ValueEncoding.decode(v)
+		if err != nil {
+			return err
+		}
+		// reject oversized pairs now, instead of failing every cycle that flushes them
+		key, err := ab.config.KeyEncoding.toState(k)
+		if err != nil {
+			return err
+		}
+		if _, _, err := ab.fitValue(key, value); err != nil {
+			return err
+		}
+		kvs = append(kvs, models.TealKeyValue{Key: k, Value: models.TealValue{Bytes: string(value)}})
+	}
+	ab.queueMu.Lock()
+	defer ab.queueMu.Unlock()
+	if cap(ab.storeArguments)-len(ab.storeArguments) < len(kvs) {
+		return ErrQueueFull
+	}
+	ab.queueSeq++
+	if ab.unflushed == nil {
+		ab.unflushed = make(map[string]uint64)
+	}
+	for _, kv := range kvs {
+		ab.storeArguments <- kv
+		ab.unflushed[kv.Key] = ab.queueSeq
+	}
+	return nil
+}
+
+// ErrLoopReturned is returned by Flush, if the management loop returned before the
+// pairs were stored (e.g. after ManageConfig.MaxRetries failed cycles).
+var ErrLoopReturned = errors.New("management loop returned")
+
+// FlushError is returned by Flush, if not all queued pairs have been stored. Keys
+// holds the sorted keys that haven't been stored.
+type FlushError struct {
+	Keys []string
+	Err  error
+}
+
+func (e *FlushError) Error() string {
+	return fmt.Sprintf("%d queued keys not stored: %s", len(e.Keys), e.Err)
+}
+
+func (e *FlushError) Unwrap() error {
+	return e.Err
+}
+
+// Flush blocks until all pairs queued by QueueElements before the call have been
+// stored by the management loop (see Manage), e.g. to reply to a request only once
+// its value is on-chain. Pairs queued again in the meantime count as stored once
+// any of their writes succeeded. If ctx is done first, or if the loop returns before
+// (see Stop), Flush returns a FlushError with the keys that haven't been stored.
+// Without a running management loop, Flush waits until ctx is done.
+func (ab *AlgorandBuffer) Flush(ctx context.Context) error {
+	ab.queueMu.Lock()
+	target := ab.queueSeq
+	ab.queueMu.Unlock()
+	ab.mu.Lock()
+	loopDone := ab.loopDone
+	ab.mu.Unlock()
+	for {
+		keys, flushed := ab.pendingKeys(target)
+		if len(keys) == 0 {
+			return nil
+		}
+		select {
+		case <-flushed:
+		case <-ctx.Done():
+			keys, _ = ab.pendingKeys(target)
+			return &FlushError{Keys: keys, Err: ctx.Err()}
+		case <-loopDone:
+			keys, _ = ab.pendingKeys(target)
+			if len(keys) == 0 {
+				return nil
+			}
+			if ab.isStopped() || ab.Context().Err() != nil {
+				return &FlushError{Keys: keys, Err: ErrStopped}
+			}
+			return &FlushError{Keys: keys, Err: ErrLoopReturned}
+		}
+	}
+}
+
+// pendingKeys returns the sorted keys queued by the first seq QueueElements calls
+// that haven't been stored yet, and a channel that is closed once queued pairs are
+// stored.
+func (ab *AlgorandBuffer) pendingKeys(seq uint64) ([]string, chan struct{}) {
+	ab.queueMu.Lock()
+	defer ab.queueMu.Unlock()
+	if ab.flushed == nil {
+		ab.flushed = make(chan struct{})
+	}
+	keys := make([]string, 0)
+	for k, s := range ab.unflushed {
+		if s <= seq {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, ab.flushed
+}
+
+// markFlushed records that the pairs of the first seq QueueElements calls have been
+// stored, and wakes up Flush.
+func (ab *AlgorandBuffer) markFlushed(seq uint64) {
+	ab.queueMu.Lock()
+	defer ab.queueMu.Unlock()
+	for k, s := range ab.unflushed {
+		if s <= seq {
+			delete(ab.unflushed, k)
+		}
+	}
+	if ab.flushed != nil {
+		close(ab.flushed)
+	}
+	ab.flushed = make(chan struct{})
+}
+
+// queueEmpty returns true if no pairs are waiting to be stored.
+func (ab *AlgorandBuffer) queueEmpty() bool {
+	return len(ab.storeArguments) == 0 && len(ab.deleteArguments) == 0 && len(ab.queued) == 0
+}
+
+// flushQueue stores the queued pairs. If storing fails, the pairs stay queued for
+// the next attempt. Only used by the management loop.
+func (ab *AlgorandBuffer) flushQueue(ctx context.Context) error {
+	ab.queueMu.Lock()
+	for len(ab.storeArguments) > 0 {
+		kv := <-ab.storeArguments
+		if ab.queued == nil {
+			ab.queued = make(map[string][]byte)
+		}
+		ab.queued[kv.Key] = []byte(kv.Value.Bytes)
+	}
+	// all pairs of the calls up to seq have been taken from storeArguments
+	seq := ab.queueSeq
+	ab.queueMu.Unlock()
+	if len(ab.queued) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := ab.PutElementsRaw(ctx, ab.queued); err != nil {
+		return err
+	}
+	ab.queued = nil
+	ab.markFlushed(seq)
+	return nil
+}
+
+// flushOnShutdown flushes the queue for up to ManageConfig.ShutdownGrace. Pairs that
+// haven't been stored by then are dropped.
+func (ab *AlgorandBuffer) flushOnShutdown() {
+	if ab.config.ShutdownGrace > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), ab.config.ShutdownGrace)
+		_ = ab.flushGrace(ctx)
+		cancel()
+	}
+	for len(ab.storeArguments) > 0 {
+		<-ab.storeArguments
+	}
+	ab.queued = nil
+}
+
+// flushGrace flushes the queue until it's empty, or until ctx is done.
+func (ab *AlgorandBuffer) flushGrace(ctx context.Context) error {
+	for {
+		err := ab.flushQueue(ctx)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(ab.config.ShutdownGrace / 10):
+		}
+	}
+}
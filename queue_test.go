@@ -0,0 +1,153 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_QueueElements(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	assert.Nil(t, buffer.QueueElements(map[string]string{"a": "1", "b": "2"}))
+	assert.Nil(t, buffer.QueueElements(map[string]string{"a": "3"}))
+	assert.Len(t, c.App.Params.GlobalState, 0)
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "3", "b": "2"}, stored)
+
+	full := make(map[string]string)
+	for i := 0; i <= cap(buffer.storeArguments); i++ {
+		full[string(rune('a'+i))] = "v"
+	}
+	assert.ErrorIs(t, buffer.QueueElements(full), ErrQueueFull)
+	assert.True(t, buffer.queueEmpty())
+
+	// oversized pairs are rejected before they're queued
+	assert.ErrorIs(t, buffer.QueueElements(map[string]string{"c": "1", strings.Repeat("k", MaxKeyBytes+1): "v"}), ErrKeyTooLong)
+	assert.ErrorIs(t, buffer.QueueElements(map[string]string{"c": strings.Repeat("v", MaxValueBytes)}), ErrValueTooLong)
+	assert.True(t, buffer.queueEmpty())
+}
+
+// unhealthyMock fails health checks while unhealthy is set, so that management
+// cycles fail before they flush the queue.
+type unhealthyMock struct {
+	*client.AlgorandMock
+	unhealthy int32
+}
+
+func (m *unhealthyMock) HealthCheck(ctx context.Context) error {
+	if atomic.LoadInt32(&m.unhealthy) == 1 {
+		return assert.AnError
+	}
+	return m.AlgorandMock.HealthCheck(ctx)
+}
+
+// runUntilStopped queues pairs while cycles fail, and stops the buffer afterwards.
+func runUntilStopped(t *testing.T, grace time.Duration) *unhealthyMock {
+	c := &unhealthyMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ShutdownGrace: grace})
+	assert.Nil(t, err)
+
+	atomic.StoreInt32(&c.unhealthy, 1)
+	assert.Nil(t, buffer.QueueElements(map[string]string{"a": "1", "b": "2"}))
+	go buffer.Manage()
+	for iterations, _, _ := buffer.LoopStats(); iterations == 0; iterations, _, _ = buffer.LoopStats() {
+		time.Sleep(time.Millisecond)
+	}
+	atomic.StoreInt32(&c.unhealthy, 0)
+	buffer.Stop()
+	return c
+}
+
+// Queued pairs are flushed within the grace period on shutdown.
+func TestAlgorandBuffer_ShutdownGrace(t *testing.T) {
+	c := runUntilStopped(t, time.Second)
+	assert.Len(t, c.App.Params.GlobalState, 2)
+}
+
+// Queued pairs are dropped if the grace period expires.
+func TestAlgorandBuffer_ShutdownGraceExpired(t *testing.T) {
+	c := runUntilStopped(t, time.Nanosecond)
+	assert.Len(t, c.App.Params.GlobalState, 0)
+
+	c = runUntilStopped(t, 0)
+	assert.Len(t, c.App.Params.GlobalState, 0)
+}
+
+// Flush returns once the management loop stored the pairs queued before the call.
+func TestAlgorandBuffer_Flush(t *testing.T) {
+	c := &unhealthyMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	cfg := ManageConfig{SleepInterval: time.Millisecond}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	defer buffer.Stop()
+	assert.Nil(t, buffer.Flush(context.Background()))
+
+	atomic.StoreInt32(&c.unhealthy, 1)
+	assert.Nil(t, buffer.QueueElements(map[string]string{"b": "2", "a": "1"}))
+	go buffer.Manage()
+
+	// the loop fails to store the pairs until the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	err = buffer.Flush(ctx)
+	cancel()
+	var flushErr *FlushError
+	assert.ErrorAs(t, err, &flushErr)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"a", "b"}, flushErr.Keys)
+
+	atomic.StoreInt32(&c.unhealthy, 0)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, buffer.Flush(ctx))
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, stored)
+}
+
+// Flush reports the keys that were dropped because the buffer shut down.
+func TestAlgorandBuffer_FlushStopped(t *testing.T) {
+	c := &unhealthyMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	cfg := ManageConfig{SleepInterval: time.Millisecond}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	atomic.StoreInt32(&c.unhealthy, 1)
+	assert.Nil(t, buffer.QueueElements(map[string]string{"a": "1"}))
+	go buffer.Manage()
+	for iterations, _, _ := buffer.LoopStats(); iterations == 0; iterations, _, _ = buffer.LoopStats() {
+		time.Sleep(time.Millisecond)
+	}
+
+	errs := make(chan error)
+	go func() { errs <- buffer.Flush(context.Background()) }()
+	time.Sleep(5 * time.Millisecond)
+	buffer.Stop()
+	select {
+	case err = <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("Flush didn't return on Stop")
+	}
+	var flushErr *FlushError
+	assert.ErrorAs(t, err, &flushErr)
+	assert.ErrorIs(t, err, ErrStopped)
+	assert.Equal(t, []string{"a"}, flushErr.Keys)
+}
@@ -0,0 +1,66 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// NewReadOnlyBuffer creates an AlgorandBuffer that reads the application with the given
+// ID, without a private key. Reads and verification (e.g. GetBuffer, Contains,
+// VerifyAgainst) work as usual. Writes (e.g. PutElements, DeleteElements, ReconcileOnce)
+// return ErrReadOnly. Use it for monitoring services that should never write.
+//
+// The application must exist and fulfil the schema of the buffer. The target account
+// isn't managed, so ManageConfig fields that concern writes have no effect.
+func NewReadOnlyBuffer(c client.AlgorandClient, appId uint64, cfg ManageConfig) (*AlgorandBuffer, error) {
+	buffer := &AlgorandBuffer{
+		AppId:         appId,
+		Client:        c,
+		timeoutLength: client.AlgorandDefaultTimeout,
+		config:        cfg,
+		now:           time.Now,
+		latency:       newLatencyReservoir(latencyReservoirSize),
+		extraApps:     make(map[uint64]time.Time),
+		lastWrites:    make(map[string]time.Time),
+		readOnly:      true,
+		AppChannel:    make(chan ManageEvent, appChannelSize),
+		ErrChannel:    make(chan error, errChannelSize),
+	}
+	buffer.appId.Store(appId)
+	buffer.loopCtx, buffer.stopLoop = context.WithCancel(context.Background())
+	if cfg.HealthTimeout > 0 {
+		buffer.timeoutLength = cfg.HealthTimeout
+	}
+
+	err := buffer.checkConnection()
+	if err != nil {
+		return buffer, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), buffer.readTimeout())
+	app, err := c.GetApplicationByID(appId, ctx)
+	cancel()
+	if err != nil {
+		return buffer, err
+	}
+	if !buffer.fulfillsSchema(app) {
+		return buffer, fmt.Errorf("application does not fulfil the schema of the buffer {%d}", appId)
+	}
+	buffer.observeSchema(app)
+	buffer.publishApp(appId, true)
+	return buffer, nil
+}
+
+// checkWritable returns ErrStopped after Stop, and ErrReadOnly if the buffer has been
+// created without a private key, or if it's on standby (see Standby).
+func (ab *AlgorandBuffer) checkWritable() error {
+	if ab.isStopped() {
+		return ErrStopped
+	}
+	if ab.readOnly || ab.Standby() {
+		return ErrReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyBuffer(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	writer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	assert.Nil(t, writer.PutElements(context.Background(), map[string]string{"k": "v"}))
+
+	buffer, err := NewReadOnlyBuffer(c, 6, ManageConfig{})
+	assert.Nil(t, err)
+
+	// reads succeed
+	d, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"k": "v"}, d)
+	contains, err := buffer.Contains(context.Background(), map[string]string{"k": "v"})
+	assert.Nil(t, err)
+	assert.True(t, contains)
+	drift, err := buffer.VerifyAgainst(map[string]string{"k": "v"})
+	assert.Nil(t, err)
+	assert.True(t, drift.Empty())
+
+	// writes fail
+	assert.ErrorIs(t, buffer.PutElements(context.Background(), map[string]string{"k": "w"}), ErrReadOnly)
+	assert.ErrorIs(t, buffer.PutOrdered(context.Background(), []KV{{Key: "k", Value: "w"}}), ErrReadOnly)
+	assert.ErrorIs(t, buffer.DeleteElements(context.Background(), "k"), ErrReadOnly)
+	assert.ErrorIs(t, buffer.AchieveDesiredState(context.Background(), map[string]string{}), ErrReadOnly)
+	assert.ErrorIs(t, buffer.ReconcileOnce(context.Background()), ErrReadOnly)
+	assert.ErrorIs(t, buffer.MigrateTo(client.ApproveTeal, client.ClearTeal), ErrReadOnly)
+
+	d, _ = buffer.GetBuffer(context.Background())
+	assert.Equal(t, map[string]string{"k": "v"}, d)
+}
+
+func TestReadOnlyBuffer_InvalidApp(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyAppsWithSchema(models.ApplicationStateSchema{}, 6)
+	c.App = c.Account.CreatedApps[0]
+	_, err := NewReadOnlyBuffer(c, 6, ManageConfig{})
+	assert.NotNil(t, err)
+
+	c.SetError(true, (*client.AlgorandMock).GetApplicationByID)
+	_, err = NewReadOnlyBuffer(c, 6, ManageConfig{})
+	assert.NotNil(t, err)
+}
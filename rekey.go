@@ -0,0 +1,83 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// expectedSigner returns the auth address the target account is expected to have.
+// Accounts that haven't been rekeyed sign for themselves.
+func (ab *AlgorandBuffer) expectedSigner() types.Address {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if ab.signer.IsZero() {
+		return ab.AccountCrypt.Address
+	}
+	return ab.signer
+}
+
+// Rekey rekeys the target account to the given address, by sending a payment of zero
+// to itself. Afterwards, the Client must sign with the key of the new address (e.g.
+// with client.AlgorandClientWrapper.Signer). Rekeying to the address of the account
+// itself undoes a rekey. Rekeys of the buffer aren't reported as ErrUnexpectedRekey.
+func (ab *AlgorandBuffer) Rekey(ctx context.Context, to types.Address) error {
+	if err := ab.checkWritable(); err != nil {
+		return err
+	}
+	params, err := ab.SuggestedParams(ctx)
+	if err != nil {
+		return err
+	}
+	addr := ab.AccountCrypt.Address.String()
+	txn, err := future.MakePaymentTxn(addr, addr, 0, nil, "", params)
+	if err != nil {
+		return err
+	}
+	if err = txn.Rekey(to.String()); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, ab.writeTimeout())
+	defer cancel()
+	if _, err = ab.Client.ExecuteTransaction(ab.AccountCrypt, txn, ctx); err != nil {
+		return err
+	}
+	ab.mu.Lock()
+	ab.signer = to
+	ab.mu.Unlock()
+	return nil
+}
+
+// checkRekey emits a ManageEvent with ErrUnexpectedRekey if the auth address of the
+// target account differs from the expected signer. Each unexpected auth address is
+// reported once. Read-only buffers don't manage an account, so they aren't checked.
+func (ab *AlgorandBuffer) checkRekey(ctx context.Context) error {
+	if ab.readOnly {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, ab.readTimeout())
+	info, err := ab.accountSummary(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	actual := ab.AccountCrypt.Address
+	if info.AuthAddr != "" {
+		if actual, err = types.DecodeAddress(info.AuthAddr); err != nil {
+			return err
+		}
+	}
+	expected := ab.expectedSigner()
+	if actual == expected {
+		ab.reportedSigner = types.Address{}
+		return nil
+	}
+	if actual != ab.reportedSigner {
+		ab.reportedSigner = actual
+		err := fmt.Errorf("%w: auth address is %s, expected %s", ErrUnexpectedRekey, actual, expected)
+		ab.emitEvent(ManageEvent{AppId: ab.currentAppId(), Err: err})
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRekeyBuffer(t *testing.T, cfg ManageConfig) (*AlgorandBuffer, *client.AlgorandMock) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	// payment transactions require a genesis hash
+	c.Params.GenesisHash = make([]byte, 32)
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	// drain the event of the adopted app
+	<-buffer.AppChannel
+	return buffer, c
+}
+
+// Out-of-band rekeys are reported once per auth address.
+func TestAlgorandBuffer_UnexpectedRekey(t *testing.T) {
+	buffer, c := newRekeyBuffer(t, ManageConfig{})
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 0)
+
+	attacker := crypto.GenerateAccount().Address
+	c.Account.AuthAddr = attacker.String()
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 1)
+	event := <-buffer.AppChannel
+	assert.True(t, errors.Is(event.Err, ErrUnexpectedRekey))
+	assert.Contains(t, event.Err.Error(), attacker.String())
+	assert.Equal(t, uint64(6), event.AppId)
+
+	// the same auth address isn't reported again
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 0)
+
+	// after the rekey is undone, a new rekey is reported again
+	c.Account.AuthAddr = ""
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 0)
+	c.Account.AuthAddr = attacker.String()
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 1)
+}
+
+// Rekeys performed by the buffer aren't reported.
+func TestAlgorandBuffer_Rekey(t *testing.T) {
+	buffer, c := newRekeyBuffer(t, ManageConfig{})
+	signer := crypto.GenerateAccount().Address
+	assert.Nil(t, buffer.Rekey(context.Background(), signer))
+	assert.Equal(t, signer.String(), c.Account.AuthAddr)
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 0)
+
+	// undoing the rekey isn't reported either
+	assert.Nil(t, buffer.Rekey(context.Background(), buffer.AccountCrypt.Address))
+	assert.Equal(t, "", c.Account.AuthAddr)
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 0)
+
+	// failed rekeys don't change the expected signer
+	c.SetError(true, (*client.AlgorandMock).ExecuteTransaction)
+	assert.NotNil(t, buffer.Rekey(context.Background(), signer))
+	c.ClearFunctionErrors()
+	c.Account.AuthAddr = signer.String()
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 1)
+}
+
+// Accounts rekeyed before the buffer was created are configured with ExpectedSigner.
+func TestAlgorandBuffer_ExpectedSigner(t *testing.T) {
+	signer := crypto.GenerateAccount().Address
+	buffer, c := newRekeyBuffer(t, ManageConfig{ExpectedSigner: signer})
+	c.Account.AuthAddr = signer.String()
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 0)
+
+	c.Account.AuthAddr = ""
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, buffer.AppChannel, 1)
+}
@@ -0,0 +1,54 @@
+package siam
+
+import (
+	"context"
+)
+
+// Restore writes the state of a snapshot back into the buffer, e.g. into a new app
+// after a redeployment:
+//
+//	s, err := buffer.Snapshot(ctx)
+//	...
+//	err = buffer.Restore(ctx, s.State)
+//
+// Afterwards, the buffer holds exactly the pairs of state. Restore compares state to
+// the live state, and only writes what differs: keys that aren't in state are
+// deleted, and added or changed pairs are stored in batches. Values are compared and
+// stored as raw bytes, regardless of the ManageConfig.ValueEncoding.
+func (ab *AlgorandBuffer) Restore(ctx context.Context, state map[string][]byte) error {
+	live, err := ab.GetBufferRaw(ctx)
+	if err != nil {
+		return err
+	}
+	diff := diffStates(rawStrings(live), rawStrings(state))
+	if len(diff.Removed) > 0 {
+		// make room for the added keys first
+		keys := make([]string, 0, len(diff.Removed))
+		for k := range diff.Removed {
+			keys = append(keys, k)
+		}
+		if err := ab.DeleteElements(ctx, keys...); err != nil {
+			return err
+		}
+	}
+	puts := make(map[string][]byte, len(diff.Added)+len(diff.Changed))
+	for k := range diff.Added {
+		puts[k] = state[k]
+	}
+	for k := range diff.Changed {
+		puts[k] = state[k]
+	}
+	if len(puts) == 0 {
+		return nil
+	}
+	return ab.PutElementsRaw(ctx, puts)
+}
+
+// rawStrings converts the values of m to strings, without encoding them.
+func rawStrings(m map[string][]byte) map[string]string {
+	s := make(map[string]string, len(m))
+	for k, v := range m {
+		s[k] = string(v)
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// A snapshot restored into a new app results in the same state.
+func TestAlgorandBuffer_Restore(t *testing.T) {
+	source, _ := newFakeLedgerBuffer(t)
+	data := map[string]string{"price/BTC": "1", "price/ETH": "2", "meta/updated": "3"}
+	assert.Nil(t, source.PutElements(context.Background(), data))
+	s, err := source.Snapshot(context.Background())
+	assert.Nil(t, err)
+
+	target, _ := newFakeLedgerBuffer(t)
+	assert.Nil(t, target.PutElements(context.Background(), map[string]string{"price/BTC": "0", "stale": "x"}))
+	assert.Nil(t, target.Restore(context.Background(), s.State))
+	stored, err := target.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, data, stored)
+}
+
+// Only pairs that differ from the live state are written.
+func TestAlgorandBuffer_RestoreChangedOnly(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ValueEncoding: ValueHex})
+	assert.Nil(t, err)
+	state := map[string][]byte{"a": {0xff}, "b": []byte("2")}
+	assert.Nil(t, buffer.Restore(context.Background(), state))
+
+	// nothing differs, so nothing is written
+	c.SetError(true, (*client.AlgorandMock).StoreGlobals, (*client.AlgorandMock).DeleteGlobals)
+	assert.Nil(t, buffer.Restore(context.Background(), state))
+
+	c.ClearFunctionErrors()
+	state = map[string][]byte{"a": {0xff}, "c": []byte("3")}
+	assert.Nil(t, buffer.Restore(context.Background(), state))
+	stored, err := buffer.GetBufferRaw(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, state, stored)
+}
@@ -0,0 +1,32 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+)
+
+// checkRound returns ErrRoundRollback and emits it as ManageEvent, if the last round of
+// the node is lower than in the previous call. Cached suggested params are dropped,
+// because their validity window may refer to rounds of the abandoned fork.
+func (ab *AlgorandBuffer) checkRound(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
+	status, err := ab.Client.Status(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	ab.mu.Lock()
+	previous := ab.lastRound
+	ab.lastRound = status.LastRound
+	rolledBack := status.LastRound < previous
+	if rolledBack {
+		ab.params = paramsCache{}
+	}
+	ab.mu.Unlock()
+	if !rolledBack {
+		return nil
+	}
+	err = fmt.Errorf("%w: round %d after %d", ErrRoundRollback, status.LastRound, previous)
+	ab.emitEvent(ManageEvent{AppId: ab.currentAppId(), Err: err})
+	return err
+}
@@ -0,0 +1,51 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_RoundRollback(t *testing.T) {
+	c := &storeRecordingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ParamsCacheTTL: 1 << 40})
+	assert.Nil(t, err)
+	<-buffer.AppChannel
+
+	c.NodeStatus.LastRound = 10
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	_, err = buffer.SuggestedParams(context.Background())
+	assert.Nil(t, err)
+
+	// the node rolls back, while the account has been modified
+	c.NodeStatus.LastRound = 8
+	c.AddDummyApps(18)
+	c.Account.CreatedApps[1].Params.GlobalStateSchema = models.ApplicationStateSchema{}
+	assert.Nil(t, buffer.QueueElements(map[string]string{"a": "1"}))
+	err = buffer.manageCycle(context.Background())
+	assert.ErrorIs(t, err, ErrRoundRollback)
+
+	// the account is re-validated, but nothing is written
+	assert.True(t, client.ValidAccount(c.Account))
+	assert.Empty(t, c.stored)
+	assert.Zero(t, buffer.params.fetched)
+	select {
+	case e := <-buffer.AppChannel:
+		assert.True(t, errors.Is(e.Err, ErrRoundRollback))
+	default:
+		t.Fatal("rollback wasn't reported")
+	}
+
+	// the next cycle acts again
+	c.NodeStatus.LastRound = 9
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Len(t, c.stored, 1)
+}
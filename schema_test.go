@@ -0,0 +1,43 @@
+//go:build unit
+
+package siam
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_CustomSchema(t *testing.T) {
+	spec := client.SchemaSpec{GlobalInts: 1, GlobalBytes: 30}
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{Schema: spec})
+	assert.Nil(t, err)
+
+	// the app is created with the custom schema
+	assert.True(t, spec.ValidAccount(c.Account))
+	assert.False(t, client.ValidAccount(c.Account))
+	assert.EqualValues(t, 1, c.Account.CreatedApps[0].Params.GlobalStateSchema.NumUint)
+	assert.Equal(t, 30, buffer.capacity())
+}
+
+// Apps with the default schema aren't recognized by buffers with a custom schema.
+func TestAlgorandBuffer_CustomSchemaDeletesDefault(t *testing.T) {
+	spec := client.SchemaSpec{GlobalInts: 1, GlobalBytes: 30}
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6, 18)
+	c.Account.CreatedApps[1].Params.GlobalStateSchema = models.ApplicationStateSchema{NumUint: 1, NumByteSlice: 30}
+
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{Schema: spec})
+	assert.Nil(t, err)
+	assert.True(t, spec.ValidAccount(c.Account))
+	assert.EqualValues(t, 18, buffer.AppId)
+}
+
+func TestManageConfig_InvalidSchema(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	_, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{Schema: client.SchemaSpec{GlobalInts: 64}})
+	assert.NotNil(t, err)
+}
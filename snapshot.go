@@ -0,0 +1,69 @@
+package siam
+
+import (
+	"context"
+	"fmt"
+)
+
+// snapshotAttempts is the number of reads SnapshotAtRound attempts before it gives up.
+const snapshotAttempts = 5
+
+// SnapshotAtRound returns the values of the given keys from a single read of the
+// global state, and the round the read reflects. Use it instead of several reads if
+// related keys must be consistent with each other, even if the oracle updates them
+// in the meantime. Keys that aren't stored are left out of the result.
+//
+// The state is read between two status requests of the node. If both report the same
+// last round, the state is known to reflect that round. Otherwise, the read is
+// repeated, up to five times.
+func (ab *AlgorandBuffer) SnapshotAtRound(keys ...string) (map[string]string, uint64, error) {
+	for attempt := 0; attempt < snapshotAttempts; attempt++ {
+		values, round, ok, err := ab.readSnapshot(keys)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			return values, round, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("no consistent snapshot after %d attempts, the round advanced during every read", snapshotAttempts)
+}
+
+// readSnapshot reads the given keys once. It returns false, if the round of the node
+// advanced during the read.
+func (ab *AlgorandBuffer) readSnapshot(keys []string) (map[string]string, uint64, bool, error) {
+	var data map[string]string
+	round, ok, err := ab.readConsistent(context.Background(), func(ctx context.Context) (err error) {
+		data, err = ab.GetBuffer(ctx)
+		return err
+	})
+	if err != nil || !ok {
+		return nil, 0, false, err
+	}
+	values := make(map[string]string, len(keys))
+	for _, k := range keys {
+		if v, ok := data[k]; ok {
+			values[k] = v
+		}
+	}
+	return values, round, true, nil
+}
+
+// readConsistent calls read between two status requests of the node, and returns the
+// round the read reflects. It returns false, if the round advanced during the read.
+func (ab *AlgorandBuffer) readConsistent(ctx context.Context, read func(context.Context) error) (uint64, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, ab.timeoutLength)
+	defer cancel()
+	before, err := ab.Client.Status(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	if err = read(ctx); err != nil {
+		return 0, false, err
+	}
+	after, err := ab.Client.Status(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	return after.LastRound, before.LastRound == after.LastRound, nil
+}
@@ -0,0 +1,193 @@
+package siam
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// The binary format of a Snapshot starts with snapshotMagic and the format version,
+// followed by the app ID, the round, the number of pairs, the pairs sorted by key and
+// the signature. Integers are unsigned varints, and every key, value and the signature
+// is prefixed with its length.
+const (
+	snapshotMagic   = "SIAM"
+	snapshotVersion = 1
+)
+
+// ErrInvalidSnapshot is returned if the binary encoding of a Snapshot is malformed.
+var ErrInvalidSnapshot = errors.New("invalid snapshot encoding")
+
+// Snapshot is the state of a buffer at a single round, e.g. for backups or transfers to
+// another buffer (see Restore). Use MarshalBinary for a compact encoding. It
+// complements ExportCSV, and keeps values that aren't valid UTF-8.
+type Snapshot struct {
+	AppId uint64
+	Round uint64
+
+	// State holds the stored pairs of the buffer, like GetBufferRaw.
+	State map[string][]byte
+
+	// Signature is the signature of the snapshot (see Sign), or nil if it's unsigned.
+	Signature []byte
+}
+
+// Snapshot returns the state of the buffer from a single read, and the round the read
+// reflects, like SnapshotAtRound. The snapshot is unsigned.
+func (ab *AlgorandBuffer) Snapshot(ctx context.Context) (Snapshot, error) {
+	for attempt := 0; attempt < snapshotAttempts; attempt++ {
+		var state map[string][]byte
+		round, ok, err := ab.readConsistent(ctx, func(ctx context.Context) (err error) {
+			state, err = ab.GetBufferRaw(ctx)
+			return err
+		})
+		if err != nil {
+			return Snapshot{}, err
+		}
+		if ok {
+			return Snapshot{AppId: ab.currentAppId(), Round: round, State: state}, nil
+		}
+	}
+	return Snapshot{}, fmt.Errorf("no consistent snapshot after %d attempts, the round advanced during every read", snapshotAttempts)
+}
+
+// MarshalBinary encodes the snapshot. Equal snapshots have equal encodings, since the
+// pairs are sorted by key.
+func (s Snapshot) MarshalBinary() ([]byte, error) {
+	b := s.appendPayload(make([]byte, 0, s.encodedSize()))
+	return appendBytes(b, s.Signature), nil
+}
+
+// UnmarshalBinary decodes a snapshot encoded by MarshalBinary. Returns
+// ErrInvalidSnapshot if data is malformed.
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(snapshotMagic)) {
+		return fmt.Errorf("%w: missing header", ErrInvalidSnapshot)
+	}
+	r := snapshotReader{data: data[len(snapshotMagic):]}
+	if version := r.uvarint(); r.err == nil && version != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+	decoded := Snapshot{AppId: r.uvarint(), Round: r.uvarint()}
+	n := r.uvarint()
+	if r.err == nil && n > uint64(len(r.data))/2 {
+		// every pair takes at least two bytes
+		return fmt.Errorf("%w: %d pairs exceed the input", ErrInvalidSnapshot, n)
+	}
+	decoded.State = make(map[string][]byte, n)
+	for i := uint64(0); i < n && r.err == nil; i++ {
+		key := string(r.bytes())
+		decoded.State[key] = r.bytes()
+	}
+	decoded.Signature = r.bytes()
+	if r.err == nil && len(r.data) > 0 {
+		r.err = fmt.Errorf("%w: %d trailing bytes", ErrInvalidSnapshot, len(r.data))
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if len(decoded.Signature) == 0 {
+		decoded.Signature = nil
+	}
+	*s = decoded
+	return nil
+}
+
+// Sign sets the signature of the snapshot to the signature of acc over its encoding
+// without signature.
+func (s *Snapshot) Sign(acc crypto.Account) error {
+	sig, err := crypto.SignBytes(acc.PrivateKey, s.appendPayload(nil))
+	if err != nil {
+		return err
+	}
+	s.Signature = sig
+	return nil
+}
+
+// Verify returns true if the snapshot has been signed by the given address, and
+// hasn't been modified since.
+func (s Snapshot) Verify(signer types.Address) bool {
+	return len(s.Signature) > 0 && crypto.VerifyBytes(signer[:], s.appendPayload(nil), s.Signature)
+}
+
+// appendPayload appends the encoding of the snapshot without signature to b.
+func (s Snapshot) appendPayload(b []byte) []byte {
+	b = append(b, snapshotMagic...)
+	b = appendUvarint(b, snapshotVersion)
+	b = appendUvarint(b, s.AppId)
+	b = appendUvarint(b, s.Round)
+	b = appendUvarint(b, uint64(len(s.State)))
+	keys := make([]string, 0, len(s.State))
+	for k := range s.State {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b = appendBytes(b, []byte(k))
+		b = appendBytes(b, s.State[k])
+	}
+	return b
+}
+
+// encodedSize returns an upper bound of the length of the encoding.
+func (s Snapshot) encodedSize() int {
+	size := len(snapshotMagic) + 4*binary.MaxVarintLen64 + len(s.Signature) + binary.MaxVarintLen64
+	for k, v := range s.State {
+		size += len(k) + len(v) + 2*binary.MaxVarintLen64
+	}
+	return size
+}
+
+// appendUvarint appends the varint encoding of v to b.
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+// appendBytes appends p to b, prefixed with its length.
+func appendBytes(b []byte, p []byte) []byte {
+	b = appendUvarint(b, uint64(len(p)))
+	return append(b, p...)
+}
+
+// snapshotReader decodes the fields of a snapshot. After the first error, all reads
+// return zero values.
+type snapshotReader struct {
+	data []byte
+	err  error
+}
+
+func (r *snapshotReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = fmt.Errorf("%w: truncated integer", ErrInvalidSnapshot)
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *snapshotReader) bytes() []byte {
+	n := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(len(r.data)) {
+		r.err = fmt.Errorf("%w: length %d exceeds the input", ErrInvalidSnapshot, n)
+		return nil
+	}
+	p := make([]byte, n)
+	copy(p, r.data)
+	r.data = r.data[n:]
+	return p
+}
@@ -0,0 +1,114 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func binarySnapshot() Snapshot {
+	return Snapshot{
+		AppId: 4512,
+		Round: 1 << 40,
+		State: map[string][]byte{
+			"price":       []byte("42"),
+			"\x00\xffraw": {0x00, 0xff, 0xfe, 0x80},
+			"empty":       {},
+		},
+	}
+}
+
+func TestSnapshot_BinaryRoundTrip(t *testing.T) {
+	s := binarySnapshot()
+	b, err := s.MarshalBinary()
+	assert.Nil(t, err)
+
+	var decoded Snapshot
+	assert.Nil(t, decoded.UnmarshalBinary(b))
+	assert.Equal(t, s.AppId, decoded.AppId)
+	assert.Equal(t, s.Round, decoded.Round)
+	assert.Equal(t, s.State, decoded.State)
+	assert.Nil(t, decoded.Signature)
+
+	// the encoding is reproduced byte by byte
+	again, err := decoded.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Equal(t, b, again)
+}
+
+func TestSnapshot_BinaryIsCompact(t *testing.T) {
+	s := Snapshot{AppId: 1, Round: 1, State: map[string][]byte{"k": []byte("v")}}
+	b, err := s.MarshalBinary()
+	assert.Nil(t, err)
+	// magic, version, app, round, count, 2 length-prefixed bytes, empty signature
+	assert.Len(t, b, 4+1+1+1+1+2+2+1)
+}
+
+func TestSnapshot_SignAndVerify(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	s := binarySnapshot()
+	assert.False(t, s.Verify(acc.Address))
+	assert.Nil(t, s.Sign(acc))
+	assert.True(t, s.Verify(acc.Address))
+	assert.False(t, s.Verify(crypto.GenerateAccount().Address))
+
+	b, err := s.MarshalBinary()
+	assert.Nil(t, err)
+	var decoded Snapshot
+	assert.Nil(t, decoded.UnmarshalBinary(b))
+	assert.Equal(t, s.Signature, decoded.Signature)
+	assert.True(t, decoded.Verify(acc.Address))
+
+	decoded.State["price"] = []byte("43")
+	assert.False(t, decoded.Verify(acc.Address))
+}
+
+func TestSnapshot_UnmarshalInvalid(t *testing.T) {
+	b, err := binarySnapshot().MarshalBinary()
+	assert.Nil(t, err)
+
+	inputs := map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("XXXX"), b[4:]...),
+		"version":   append([]byte(snapshotMagic), append([]byte{2}, b[5:]...)...),
+		"truncated": b[:len(b)-3],
+		"trailing":  append(append([]byte{}, b...), 0),
+		"count":     append([]byte(snapshotMagic), 1, 1, 1, 0xff, 0xff, 0x03),
+	}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			s := Snapshot{AppId: 7}
+			assert.ErrorIs(t, s.UnmarshalBinary(input), ErrInvalidSnapshot)
+			assert.Equal(t, uint64(7), s.AppId)
+		})
+	}
+}
+
+func TestAlgorandBuffer_Snapshot(t *testing.T) {
+	buffer, c := newSnapshotBuffer(t)
+	raw := string([]byte{0x00, 0xff})
+	err := c.AlgorandMock.StoreGlobals(crypto.Account{}, c.App.Id, []models.TealKeyValue{{Key: "raw", Value: models.TealValue{Bytes: raw}}})
+	assert.Nil(t, err)
+	c.advances = 2
+
+	s, err := buffer.Snapshot(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, buffer.AppId, s.AppId)
+	assert.Equal(t, uint64(102), s.Round)
+	assert.Equal(t, []byte(raw), s.State["raw"])
+	assert.Equal(t, []byte("p102"), s.State["price"])
+
+	c.advances = 2 * snapshotAttempts
+	_, err = buffer.Snapshot(context.Background())
+	assert.NotNil(t, err)
+
+	c.SetError(true, (*client.AlgorandMock).Status)
+	_, err = buffer.Snapshot(context.Background())
+	assert.NotNil(t, err)
+}
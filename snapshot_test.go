@@ -0,0 +1,77 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// advancingMock advances the round on the first status requests, and the oracle
+// updates all keys to the current round whenever the state is read.
+type advancingMock struct {
+	*client.AlgorandMock
+	advances int
+}
+
+func (m *advancingMock) Status(ctx context.Context) (models.NodeStatus, error) {
+	if m.advances > 0 {
+		m.advances--
+		m.NodeStatus.LastRound++
+	}
+	return m.AlgorandMock.Status(ctx)
+}
+
+func (m *advancingMock) GetApplicationByID(id uint64, ctx context.Context) (models.Application, error) {
+	round := strconv.FormatUint(m.NodeStatus.LastRound, 10)
+	kv := []models.TealKeyValue{
+		{Key: "price", Value: models.TealValue{Bytes: "p" + round}},
+		{Key: "volume", Value: models.TealValue{Bytes: "v" + round}},
+	}
+	if err := m.AlgorandMock.StoreGlobals(crypto.Account{}, id, kv); err != nil {
+		return models.Application{}, err
+	}
+	return m.AlgorandMock.GetApplicationByID(id, ctx)
+}
+
+func newSnapshotBuffer(t *testing.T) (*AlgorandBuffer, *advancingMock) {
+	c := &advancingMock{AlgorandMock: client.CreateAlgorandClientMock("", "")}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	c.NodeStatus.LastRound = 100
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	return buffer, c
+}
+
+// All values come from the same round, even if the round advances during reads.
+func TestAlgorandBuffer_SnapshotAtRound(t *testing.T) {
+	buffer, c := newSnapshotBuffer(t)
+	c.advances = 3
+
+	values, round, err := buffer.SnapshotAtRound("price", "volume", "missing")
+	assert.Nil(t, err)
+	r := strconv.FormatUint(round, 10)
+	assert.Equal(t, map[string]string{"price": "p" + r, "volume": "v" + r}, values)
+	assert.Equal(t, c.NodeStatus.LastRound, round)
+	assert.EqualValues(t, 103, round)
+}
+
+// If the round advances during every read, no snapshot is returned.
+func TestAlgorandBuffer_SnapshotAtRoundUnstable(t *testing.T) {
+	buffer, c := newSnapshotBuffer(t)
+	c.advances = 2 * snapshotAttempts
+
+	_, _, err := buffer.SnapshotAtRound("price")
+	assert.NotNil(t, err)
+
+	c.SetError(true, (*client.AlgorandMock).Status)
+	_, _, err = buffer.SnapshotAtRound("price")
+	assert.NotNil(t, err)
+}
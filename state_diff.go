@@ -0,0 +1,64 @@
+package siam
+
+import (
+	"context"
+)
+
+// StateDiff describes the differences between two states of the buffer.
+type StateDiff struct {
+	// Added contains the entries that only exist in the new state.
+	Added map[string]string
+
+	// Removed contains the entries that only exist in the old state.
+	Removed map[string]string
+
+	// Changed contains the entries whose values differ between the states.
+	Changed map[string]ValueChange
+}
+
+// ValueChange is the old and new value of a changed key.
+type ValueChange struct {
+	Old string
+	New string
+}
+
+// Empty returns true if both states are identical.
+func (d StateDiff) Empty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Changed) == 0
+}
+
+// diffStates returns the StateDiff that turns state old into state new.
+func diffStates(old, new map[string]string) StateDiff {
+	d := StateDiff{
+		Added:   make(map[string]string),
+		Removed: make(map[string]string),
+		Changed: make(map[string]ValueChange),
+	}
+	for k, v := range new {
+		ov, ok := old[k]
+		if !ok {
+			d.Added[k] = v
+		} else if ov != v {
+			d.Changed[k] = ValueChange{Old: ov, New: v}
+		}
+	}
+	for k, v := range old {
+		if _, ok := new[k]; !ok {
+			d.Removed[k] = v
+		}
+	}
+	return d
+}
+
+// VerifyAgainst compares the live application state with an expected snapshot of it.
+// The returned StateDiff describes how the live state drifted from the expected one:
+// Added holds unexpected keys, Removed holds missing keys, and Changed holds keys
+// whose live value (New) differs from the expected value (Old). Use it to detect
+// external tampering with the oracle.
+func (ab *AlgorandBuffer) VerifyAgainst(expected map[string]string) (drift StateDiff, err error) {
+	live, err := ab.GetBuffer(context.Background())
+	if err != nil {
+		return StateDiff{}, err
+	}
+	return diffStates(expected, live), nil
+}
@@ -0,0 +1,52 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_VerifyAgainstNoDrift(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, _ := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	expected := map[string]string{"1000": "Astralis", "1001": "Vitality"}
+	assert.Nil(t, buffer.PutElements(context.Background(), expected))
+
+	drift, err := buffer.VerifyAgainst(expected)
+	assert.Nil(t, err)
+	assert.True(t, drift.Empty())
+}
+
+func TestAlgorandBuffer_VerifyAgainstTampered(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, _ := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	expected := map[string]string{"1000": "Astralis", "1001": "Vitality", "1002": "Gambit"}
+	assert.Nil(t, buffer.PutElements(context.Background(), expected))
+
+	// Tamper with the state behind the buffer's back
+	assert.Nil(t, c.StoreGlobals(buffer.AccountCrypt, buffer.AppId, []models.TealKeyValue{
+		{Key: "1000", Value: models.TealValue{Bytes: "G2"}},
+		{Key: "evil", Value: models.TealValue{Bytes: "x"}},
+	}))
+	assert.Nil(t, c.DeleteGlobals(buffer.AccountCrypt, buffer.AppId, "1002"))
+
+	drift, err := buffer.VerifyAgainst(expected)
+	assert.Nil(t, err)
+	assert.False(t, drift.Empty())
+	assert.Equal(t, map[string]string{"evil": "x"}, drift.Added)
+	assert.Equal(t, map[string]string{"1002": "Gambit"}, drift.Removed)
+	assert.Equal(t, map[string]ValueChange{"1000": {Old: "Astralis", New: "G2"}}, drift.Changed)
+}
+
+func TestAlgorandBuffer_VerifyAgainstError(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, _ := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	c.SetError(true, (*client.AlgorandMock).GetApplicationByID)
+	_, err := buffer.VerifyAgainst(map[string]string{})
+	assert.NotNil(t, err)
+}
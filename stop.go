@@ -0,0 +1,66 @@
+package siam
+
+// Stop shuts the buffer down. It cancels the management loop (see Manage) and waits
+// for it to return, which includes flushing queued writes (see
+// ManageConfig.ShutdownGrace). It then waits for writes that are in flight, and closes
+// AppChannel, ErrChannel and the channels of Watch.
+// Afterwards, reads and writes (e.g. GetBuffer, PutElements) return ErrStopped.
+// Calling Stop several times is safe.
+func (ab *AlgorandBuffer) Stop() {
+	ab.stopOnce.Do(func() {
+		ab.shutdown()
+		ab.mu.Lock()
+		done := ab.loopDone
+		ab.mu.Unlock()
+		if done != nil {
+			<-done
+		}
+
+		ab.mu.Lock()
+		ab.stopped = true
+		ab.mu.Unlock()
+		ab.active.Wait()
+
+		ab.mu.Lock()
+		if ab.AppChannel != nil {
+			close(ab.AppChannel)
+		}
+		if ab.ErrChannel != nil {
+			close(ab.ErrChannel)
+		}
+		ab.closeWatchers()
+		ab.mu.Unlock()
+	})
+}
+
+// isStopped returns true if Stop has been called.
+func (ab *AlgorandBuffer) isStopped() bool {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	return ab.stopped
+}
+
+// beginWrite registers a write that is in flight, so that Stop waits for it. Call
+// ab.active.Done when the write is finished. Returns ErrStopped after Stop.
+func (ab *AlgorandBuffer) beginWrite() error {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if ab.stopped {
+		return ErrStopped
+	}
+	ab.active.Add(1)
+	return nil
+}
+
+// beginLoop registers the management loop, so that Stop waits for it. Returns false
+// if the buffer has already been shut down.
+func (ab *AlgorandBuffer) beginLoop() (done chan struct{}, ok bool) {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if ab.stopped || ab.Context().Err() != nil {
+		return nil, false
+	}
+	done = make(chan struct{})
+	ab.loopDone = done
+	return done, true
+}
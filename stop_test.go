@@ -0,0 +1,99 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_Stop(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		buffer.Manage()
+		close(done)
+	}()
+	buffer.Stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Manage didn't return after Stop")
+	}
+
+	// the event of the adopted app is still buffered, then the channel is closed
+	_, ok := <-buffer.AppChannel
+	assert.True(t, ok)
+	_, ok = <-buffer.AppChannel
+	assert.False(t, ok)
+
+	_, err = buffer.GetBuffer(context.Background())
+	assert.ErrorIs(t, err, ErrStopped)
+	assert.ErrorIs(t, buffer.PutElements(context.Background(), map[string]string{"k": "v"}), ErrStopped)
+	assert.ErrorIs(t, buffer.DeleteElements(context.Background(), "k"), ErrStopped)
+	assert.ErrorIs(t, buffer.ReconcileOnce(context.Background()), ErrStopped)
+
+	// Stop is idempotent, and Manage returns right away
+	buffer.Stop()
+	buffer.Manage()
+}
+
+// blockingMock blocks stores until release is closed.
+type blockingMock struct {
+	*client.AlgorandMock
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (m *blockingMock) StoreGlobalsResult(acc crypto.Account, appId uint64, kv []models.TealKeyValue) (models.PendingTransactionInfoResponse, error) {
+	close(m.entered)
+	<-m.release
+	return m.AlgorandMock.StoreGlobalsResult(acc, appId, kv)
+}
+
+// Stop waits for writes that are in flight.
+func TestAlgorandBuffer_StopWaitsForWrites(t *testing.T) {
+	c := &blockingMock{AlgorandMock: client.CreateAlgorandClientMock("", ""),
+		entered: make(chan struct{}), release: make(chan struct{})}
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	written := make(chan error)
+	go func() {
+		written <- buffer.PutElements(context.Background(), map[string]string{"k": "v"})
+	}()
+	<-c.entered
+
+	stopped := make(chan struct{})
+	go func() {
+		buffer.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before the write finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(c.release)
+	assert.Nil(t, <-written)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop didn't return after the write finished")
+	}
+	assert.Len(t, c.App.Params.GlobalState, 1)
+}
@@ -0,0 +1,52 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// A hook returning an error prevents the transaction from being submitted.
+func TestAlgorandBuffer_OnBeforeSubmit(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	var submitted []types.Transaction
+	vetoed := errors.New("vetoed by policy")
+	cfg := ManageConfig{OnBeforeSubmit: func(tx types.Transaction) error {
+		if len(tx.ApplicationArgs) > 0 && string(tx.ApplicationArgs[0]) == "forbidden" {
+			return vetoed
+		}
+		submitted = append(submitted, tx)
+		return nil
+	}}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"allowed": "v"}))
+	assert.Len(t, submitted, 1)
+	assert.EqualValues(t, 6, submitted[0].ApplicationID)
+
+	err = buffer.PutElements(context.Background(), map[string]string{"forbidden": "v"})
+	assert.ErrorIs(t, err, vetoed)
+	assert.Len(t, submitted, 1)
+	data, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"allowed": "v"}, data)
+}
+
+// App creation goes through the hook as well.
+func TestAlgorandBuffer_OnBeforeSubmitCreation(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	vetoed := errors.New("no new apps")
+	cfg := ManageConfig{OnBeforeSubmit: func(tx types.Transaction) error { return vetoed }}
+	_, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.ErrorIs(t, err, vetoed)
+	assert.Len(t, c.Account.CreatedApps, 0)
+}
@@ -0,0 +1,38 @@
+package siam
+
+import (
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+)
+
+// readTimeout returns the timeout of reads of the target account and the app (see
+// ManageConfig.ReadTimeout).
+func (ab *AlgorandBuffer) readTimeout() time.Duration {
+	if ab.config.ReadTimeout > 0 {
+		return ab.config.ReadTimeout
+	}
+	return ab.timeoutLength
+}
+
+// writeTimeout returns the timeout of transactions that the buffer executes itself,
+// like rekeys and payments (see ManageConfig.StoreTimeout).
+func (ab *AlgorandBuffer) writeTimeout() time.Duration {
+	if ab.config.StoreTimeout > 0 {
+		return ab.config.StoreTimeout
+	}
+	return ab.timeoutLength
+}
+
+// withTimeouts returns c with the create and store timeouts of cfg applied to its
+// application management methods, if any of them are set.
+func withTimeouts(c client.AlgorandClient, cfg ManageConfig) client.AlgorandClient {
+	if cfg.CreateTimeout == 0 && cfg.StoreTimeout == 0 {
+		return c
+	}
+	return client.WithTimeouts(c, client.Timeouts{
+		Create: cfg.CreateTimeout,
+		Delete: cfg.CreateTimeout,
+		Store:  cfg.StoreTimeout,
+	})
+}
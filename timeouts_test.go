@@ -0,0 +1,59 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// newLatentBuffer returns a buffer whose mock takes 50ms for every request, unless
+// the context is done before.
+func newLatentBuffer(t *testing.T, cfg ManageConfig) (*AlgorandBuffer, *client.AlgorandMock) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+	<-buffer.AppChannel
+	c.Latency = 50 * time.Millisecond
+	return buffer, c
+}
+
+func TestAlgorandBuffer_ReadTimeout(t *testing.T) {
+	buffer, _ := newLatentBuffer(t, ManageConfig{ReadTimeout: time.Millisecond, HealthTimeout: time.Second})
+	_, err := buffer.GetBuffer(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, buffer.Health())
+
+	buffer, _ = newLatentBuffer(t, ManageConfig{ReadTimeout: time.Second, HealthTimeout: time.Millisecond})
+	_, err = buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.ErrorIs(t, buffer.Health(), context.DeadlineExceeded)
+}
+
+func TestAlgorandBuffer_StoreTimeout(t *testing.T) {
+	buffer, _ := newLatentBuffer(t, ManageConfig{StoreTimeout: time.Millisecond, CreateTimeout: time.Second})
+	err := buffer.PutElements(context.Background(), map[string]string{"a": "1"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	buffer, c := newLatentBuffer(t, ManageConfig{StoreTimeout: time.Second})
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"a": "1"}))
+	assert.Len(t, c.App.Params.GlobalState, 1)
+}
+
+func TestAlgorandBuffer_CreateTimeout(t *testing.T) {
+	buffer, c := newLatentBuffer(t, ManageConfig{CreateTimeout: time.Millisecond, StoreTimeout: time.Second})
+	c.CreateDummyApps()
+	assert.ErrorIs(t, buffer.ReconcileOnce(context.Background()), context.DeadlineExceeded)
+	assert.Empty(t, c.Account.CreatedApps)
+
+	buffer, c = newLatentBuffer(t, ManageConfig{CreateTimeout: time.Second, StoreTimeout: time.Millisecond})
+	c.CreateDummyApps()
+	assert.Nil(t, buffer.ReconcileOnce(context.Background()))
+	assert.True(t, client.ValidAccount(c.Account))
+}
@@ -0,0 +1,72 @@
+package siam
+
+import (
+	"encoding/base64"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// observeResults records the info responses of the confirmed transactions of a
+// single write operation. The counter is incremented by the number of transactions.
+func (ab *AlgorandBuffer) observeResults(counter *uint64, results []models.PendingTransactionInfoResponse) {
+	if len(results) == 0 {
+		return
+	}
+	logs := make([][]byte, 0)
+	delta := make([]models.EvalDeltaKeyValue, 0)
+	fees := uint64(0)
+	for _, r := range results {
+		logs = append(logs, r.Logs...)
+		for _, kv := range r.GlobalStateDelta {
+			delta = append(delta, ab.decodeDelta(kv))
+		}
+		fees += uint64(r.Transaction.Txn.Fee)
+	}
+	ab.mu.Lock()
+	ab.lastLogs = logs
+	ab.lastDelta = delta
+	*counter += uint64(len(results))
+	ab.metrics.feesPaid += fees
+	ab.mu.Unlock()
+	if ab.config.Metrics != nil {
+		ab.config.Metrics.TransactionsSent(len(results))
+	}
+}
+
+// LastLogs returns the logs that the approval program emitted during the last write
+// (see PutElements and DeleteElements). If a write consisted of several transactions,
+// the logs of all transactions are returned in order of submission. The approval
+// program can use logs to communicate back to the client (e.g. computed values).
+func (ab *AlgorandBuffer) LastLogs() [][]byte {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	logs := make([][]byte, len(ab.lastLogs))
+	copy(logs, ab.lastLogs)
+	return logs
+}
+
+// decodeDelta decodes the base64-encoded key and bytes of a global state delta. The
+// key is returned as buffer key (see ManageConfig.KeyEncoding). Fields that aren't
+// valid base64 are returned unchanged.
+func (ab *AlgorandBuffer) decodeDelta(kv models.EvalDeltaKeyValue) models.EvalDeltaKeyValue {
+	if key, err := base64.StdEncoding.DecodeString(kv.Key); err == nil {
+		kv.Key = ab.config.KeyEncoding.fromState(key)
+	}
+	if b, err := base64.StdEncoding.DecodeString(kv.Value.Bytes); err == nil {
+		kv.Value.Bytes = string(b)
+	}
+	return kv
+}
+
+// LastGlobalDelta returns the changes to the global state, as reported by the node
+// for the transactions of the last write (see PutElements and DeleteElements). Keys and
+// values are decoded. Compare it to the written data to confirm what the approval
+// program actually stored, if it transforms its inputs. The action of a delta is 1 for
+// stored bytes, 2 for stored uints and 3 for deleted keys.
+func (ab *AlgorandBuffer) LastGlobalDelta() []models.EvalDeltaKeyValue {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	delta := make([]models.EvalDeltaKeyValue, len(ab.lastDelta))
+	copy(delta, ab.lastDelta)
+	return delta
+}
@@ -0,0 +1,93 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_LastLogs(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, _ := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Len(t, buffer.LastLogs(), 0)
+
+	c.PendingTXNInfo = models.PendingTransactionInfoResponse{Logs: [][]byte{[]byte("computed"), {0, 1}}}
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"x": "y"}))
+	assert.Equal(t, [][]byte{[]byte("computed"), {0, 1}}, buffer.LastLogs())
+
+	c.PendingTXNInfo = models.PendingTransactionInfoResponse{Logs: [][]byte{[]byte("deleted")}}
+	assert.Nil(t, buffer.DeleteElements(context.Background(), "x"))
+	assert.Equal(t, [][]byte{[]byte("deleted")}, buffer.LastLogs())
+}
+
+// Logs of all transactions of a write are returned
+func TestAlgorandBuffer_LastLogsSeveralTransactions(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, _ := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	c.PendingTXNInfo = models.PendingTransactionInfoResponse{Logs: [][]byte{[]byte("log")}}
+
+	data := make(map[string]string)
+	for _, k := range []string{"0", "1", "2", "3", "4", "5", "6", "7", "8"} {
+		data[k] = "v"
+	}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+	assert.Len(t, buffer.LastLogs(), 2)
+}
+
+// plainClient only implements AlgorandClient, and no optional interfaces
+type plainClient struct {
+	client.AlgorandClient
+}
+
+// Clients that don't implement client.ResultClient can still be written to, but don't
+// surface logs.
+func TestAlgorandBuffer_LastLogsWithoutResults(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(plainClient{c}, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	c.PendingTXNInfo = models.PendingTransactionInfoResponse{Logs: [][]byte{[]byte("log")}}
+
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"x": "y"}))
+	assert.Equal(t, 1, c.CallCount((*client.AlgorandMock).StoreGlobals))
+	assert.Len(t, buffer.LastLogs(), 0)
+	d, _ := buffer.GetBuffer(context.Background())
+	assert.Equal(t, map[string]string{"x": "y"}, d)
+}
+
+// The global state delta of the last write is surfaced with decoded keys and values
+func TestAlgorandBuffer_LastGlobalDelta(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, _ := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Len(t, buffer.LastGlobalDelta(), 0)
+
+	// the approval program stores the uppercased value
+	c.PendingTXNInfo = models.PendingTransactionInfoResponse{GlobalStateDelta: []models.EvalDeltaKeyValue{
+		{Key: base64.StdEncoding.EncodeToString([]byte("team")), Value: models.EvalDelta{Action: 1, Bytes: base64.StdEncoding.EncodeToString([]byte("ASTRALIS"))}},
+		{Key: base64.StdEncoding.EncodeToString([]byte("count")), Value: models.EvalDelta{Action: 2, Uint: 7}},
+	}}
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"team": "astralis"}))
+	assert.Equal(t, []models.EvalDeltaKeyValue{
+		{Key: "team", Value: models.EvalDelta{Action: 1, Bytes: "ASTRALIS"}},
+		{Key: "count", Value: models.EvalDelta{Action: 2, Uint: 7}},
+	}, buffer.LastGlobalDelta())
+}
+
+// The fake ledger reports the delta of puts and deletes
+func TestAlgorandBuffer_LastGlobalDeltaFakeLedger(t *testing.T) {
+	buffer, _ := newFakeLedgerBuffer(t)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"1000": "Astralis"}))
+	assert.Equal(t, []models.EvalDeltaKeyValue{
+		{Key: "1000", Value: models.EvalDelta{Action: 1, Bytes: "Astralis"}},
+	}, buffer.LastGlobalDelta())
+
+	assert.Nil(t, buffer.DeleteElements(context.Background(), "1000"))
+	assert.Equal(t, []models.EvalDeltaKeyValue{
+		{Key: "1000", Value: models.EvalDelta{Action: 3}},
+	}, buffer.LastGlobalDelta())
+}
@@ -0,0 +1,65 @@
+package siam
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxPairLength is the maximum length of a key-value pair in the global state in bytes.
+const maxPairLength = 128
+
+// MaxKeyBytes is the maximum length of a key of the global state in bytes. Writes of
+// longer keys fail with ErrKeyTooLong.
+const MaxKeyBytes = 64
+
+// MaxValueBytes is the maximum length of a value of the global state in bytes. The key
+// counts towards it as well, so a key can hold MaxValueBytes minus the length of the key.
+// Writes of longer values fail with ErrValueTooLong. Use SplitValue to store larger
+// values.
+const MaxValueBytes = maxPairLength
+
+// fitValue returns the value of the key-value pair, if the pair doesn't exceed
+// maxPairLength. Otherwise, ErrValueTooLong is returned, unless
+// ManageConfig.TruncateOversized is set. Then, the value is truncated to fit at a
+// UTF-8 boundary, and true is returned. Keys are never truncated, so keys longer than
+// MaxKeyBytes are rejected with ErrKeyTooLong.
+func (ab *AlgorandBuffer) fitValue(key string, value []byte) ([]byte, bool, error) {
+	if len(key) > MaxKeyBytes {
+		return nil, false, fmt.Errorf("%w {%s}: key cannot exceed %d bytes", ErrKeyTooLong, key, MaxKeyBytes)
+	}
+	if len(key)+len(value) <= maxPairLength {
+		return value, false, nil
+	}
+	if !ab.config.TruncateOversized || len(key) >= maxPairLength {
+		return nil, false, fmt.Errorf("%w {%s}: kv pair cannot exceed %d bytes", ErrValueTooLong, key, maxPairLength)
+	}
+	return truncateUTF8(value, maxPairLength-len(key)), true, nil
+}
+
+// truncateUTF8 truncates b to at most n bytes, without splitting a UTF-8 encoded rune.
+func truncateUTF8(b []byte, n int) []byte {
+	if len(b) <= n {
+		return b
+	}
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return b[:n]
+}
+
+func (ab *AlgorandBuffer) setTruncated(keys []string) {
+	ab.mu.Lock()
+	ab.lastTruncated = keys
+	ab.mu.Unlock()
+}
+
+// LastTruncated returns the keys whose values were truncated during the last store
+// (see ManageConfig.TruncateOversized). Returns an empty slice, if no values were
+// truncated.
+func (ab *AlgorandBuffer) LastTruncated() []string {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	keys := make([]string, len(ab.lastTruncated))
+	copy(keys, ab.lastTruncated)
+	return keys
+}
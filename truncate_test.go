@@ -0,0 +1,69 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateUTF8(t *testing.T) {
+	assert.Equal(t, "abc", string(truncateUTF8([]byte("abc"), 5)))
+	assert.Equal(t, "ab", string(truncateUTF8([]byte("abc"), 2)))
+	// "世" is 3 bytes long, and must not be split
+	assert.Equal(t, "a", string(truncateUTF8([]byte("a世"), 3)))
+	assert.Equal(t, "a世", string(truncateUTF8([]byte("a世b"), 4)))
+	assert.Equal(t, "", string(truncateUTF8([]byte("世"), 2)))
+}
+
+// Oversized values are rejected with ErrValueTooLong by default.
+func TestAlgorandBuffer_ValueTooLong(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	err = buffer.PutElements(context.Background(), map[string]string{"key": strings.Repeat("x", 128)})
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+	err = buffer.PutOrdered(context.Background(), []KV{{Key: "key", Value: strings.Repeat("x", 128)}})
+	assert.True(t, errors.Is(err, ErrValueTooLong))
+	assert.Len(t, c.App.Params.GlobalState, 0)
+}
+
+// With TruncateOversized, values are cut at a UTF-8 boundary and their keys recorded.
+func TestAlgorandBuffer_TruncateOversized(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	cfg := ManageConfig{TruncateOversized: true}
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), cfg)
+	assert.Nil(t, err)
+
+	long := strings.Repeat("世", 50)
+	data := map[string]string{"log": long, "short": "ok"}
+	assert.Nil(t, buffer.PutElements(context.Background(), data))
+	assert.Equal(t, []string{"log"}, buffer.LastTruncated())
+
+	stored, err := buffer.GetBuffer(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", stored["short"])
+	assert.True(t, utf8.ValidString(stored["log"]))
+	assert.True(t, strings.HasPrefix(long, stored["log"]))
+	assert.LessOrEqual(t, len("log")+len(stored["log"]), 128)
+	assert.Equal(t, 41*3, len(stored["log"]))
+
+	// the next store resets the recorded keys
+	assert.Nil(t, buffer.PutOrdered(context.Background(), []KV{{Key: "short", Value: "fine"}}))
+	assert.Len(t, buffer.LastTruncated(), 0)
+
+	// keys that exceed the limit can't be truncated
+	err = buffer.PutElements(context.Background(), map[string]string{strings.Repeat("k", 128): "v"})
+	assert.True(t, errors.Is(err, ErrKeyTooLong))
+}
@@ -0,0 +1,85 @@
+package siam
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// uintBytesLength is the length of a uint64 encoded as byte slice
+const uintBytesLength = 8
+
+// PutUintBytes stores v under key as an 8-byte big-endian byte slice. Unlike a TEAL
+// uint, the value is a byte slice, which contracts can convert with btoi.
+func (ab *AlgorandBuffer) PutUintBytes(ctx context.Context, key string, v uint64) error {
+	value := make([]byte, uintBytesLength)
+	binary.BigEndian.PutUint64(value, v)
+	return ab.PutElementsRaw(ctx, map[string][]byte{key: value})
+}
+
+// GetUintBytes returns the uint64 stored under key as an 8-byte big-endian byte
+// slice (e.g. by PutUintBytes, or by a contract with itob). Returns ErrKeyNotFound,
+// if the key isn't stored, and ErrTypeMismatch, if the value has a different length.
+func (ab *AlgorandBuffer) GetUintBytes(ctx context.Context, key string) (uint64, error) {
+	value, err := ab.GetBytes(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(value) != uintBytesLength {
+		return 0, fmt.Errorf("%w: value of key {%s} has %d bytes, expected %d", ErrTypeMismatch, key, len(value), uintBytesLength)
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// PutInt stores v under key as an 8-byte big-endian two's complement byte slice.
+// Non-negative values are encoded like PutUintBytes.
+func (ab *AlgorandBuffer) PutInt(ctx context.Context, key string, v int64) error {
+	return ab.PutUintBytes(ctx, key, uint64(v))
+}
+
+// GetInt returns the int64 stored under key by PutInt. Returns ErrKeyNotFound, if the
+// key isn't stored, and ErrTypeMismatch, if the value isn't 8 bytes long.
+func (ab *AlgorandBuffer) GetInt(ctx context.Context, key string) (int64, error) {
+	v, err := ab.GetUintBytes(ctx, key)
+	return int64(v), err
+}
+
+// PutJSON stores the JSON encoding of v under key. The encoding has to fit into a
+// key-value pair (see ErrValueTooLong), so it suits small objects only.
+func (ab *AlgorandBuffer) PutJSON(ctx context.Context, key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ab.PutElementsRaw(ctx, map[string][]byte{key: value})
+}
+
+// GetJSON decodes the JSON stored under key (e.g. by PutJSON) into out. Returns
+// ErrKeyNotFound, if the key isn't stored, and ErrTypeMismatch, if the value isn't
+// valid JSON or doesn't fit out.
+func (ab *AlgorandBuffer) GetJSON(ctx context.Context, key string, out interface{}) error {
+	value, err := ab.GetBytes(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(value, out); err != nil {
+		return fmt.Errorf("%w: value of key {%s} isn't JSON of %T: %s", ErrTypeMismatch, key, out, err)
+	}
+	return nil
+}
+
+// GetBytes returns the raw bytes stored under key, regardless of the
+// ManageConfig.ValueEncoding. Returns ErrKeyNotFound, if the key isn't stored.
+func (ab *AlgorandBuffer) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	data, err := ab.GetBufferRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("%w {%s}", ErrKeyNotFound, key)
+	}
+	ab.touchRead(key)
+	return value, nil
+}
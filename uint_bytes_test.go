@@ -0,0 +1,76 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_UintBytes(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+
+	for _, v := range []uint64{0, 1, 256, 1 << 40, math.MaxUint64} {
+		assert.Nil(t, buffer.PutUintBytes(context.Background(), "counter", v))
+		got, err := buffer.GetUintBytes(context.Background(), "counter")
+		assert.Nil(t, err)
+		assert.Equal(t, v, got)
+	}
+
+	raw, err := buffer.GetBufferRaw(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, raw["counter"])
+
+	_, err = buffer.GetUintBytes(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"name": "OG"}))
+	_, err = buffer.GetUintBytes(context.Background(), "name")
+	assert.NotNil(t, err)
+}
+
+func TestAlgorandBuffer_TypedAccessors(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	for _, v := range []int64{0, -1, 42, math.MinInt64, math.MaxInt64} {
+		assert.Nil(t, buffer.PutInt(ctx, "int", v))
+		got, err := buffer.GetInt(ctx, "int")
+		assert.Nil(t, err)
+		assert.Equal(t, v, got)
+	}
+
+	type match struct {
+		Home  string `json:"home"`
+		Goals []int  `json:"goals"`
+	}
+	assert.Nil(t, buffer.PutJSON(ctx, "match", match{Home: "OG", Goals: []int{2, 1}}))
+	var m match
+	assert.Nil(t, buffer.GetJSON(ctx, "match", &m))
+	assert.Equal(t, match{Home: "OG", Goals: []int{2, 1}}, m)
+	raw, err := buffer.GetBytes(ctx, "match")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"home":"OG","goals":[2,1]}`, string(raw))
+
+	// mismatching types
+	assert.Nil(t, buffer.PutElements(ctx, map[string]string{"name": "OG"}))
+	_, err = buffer.GetInt(ctx, "name")
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+	assert.ErrorIs(t, buffer.GetJSON(ctx, "name", &m), ErrTypeMismatch)
+	var n int
+	assert.ErrorIs(t, buffer.GetJSON(ctx, "match", &n), ErrTypeMismatch)
+	_, err = buffer.GetBytes(ctx, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.ErrorIs(t, buffer.GetJSON(ctx, "missing", &m), ErrKeyNotFound)
+}
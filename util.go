@@ -51,6 +51,25 @@ func partitionMapByte(data map[string][]byte, size int) []map[string][]byte {
 	return partitions
 }
 
+// partitionPairs partitions the given pairs into consecutive partitions with a given size.
+// A key occurs at most once per partition: a duplicate key starts a new partition, since
+// approval.teal applies the pairs of a transaction from last to first, so the first value
+// of a key in a transaction would win.
+func partitionPairs(pairs []KV, size int) [][]KV {
+	partitions := make([][]KV, 0, (len(pairs)+size-1)/size)
+	start := 0
+	keys := make(map[string]bool, size)
+	for i, kv := range pairs {
+		if i-start == size || keys[kv.Key] {
+			partitions = append(partitions, pairs[start:i])
+			start = i
+			keys = make(map[string]bool, size)
+		}
+		keys[kv.Key] = true
+	}
+	return append(partitions, pairs[start:])
+}
+
 func getKeys(m map[string]string) []string {
 	s := make([]string, len(m))
 	i := 0
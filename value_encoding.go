@@ -0,0 +1,69 @@
+package siam
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// ValueEncoding determines how the string values of GetBuffer and PutElements map to
+// the byte values of the global state.
+type ValueEncoding int
+
+const (
+	// ValueUTF8 uses the bytes of a string as value.
+	ValueUTF8 ValueEncoding = iota
+
+	// ValueBase64 renders values base64-encoded (standard encoding with padding), and
+	// expects written values to be base64-encoded.
+	ValueBase64
+
+	// ValueHex renders values hex-encoded (lowercase), and expects written values to be
+	// hex-encoded. Use it to read binary values in a human-friendly format.
+	ValueHex
+)
+
+// encode returns the string representation of a value of the global state.
+func (e ValueEncoding) encode(value []byte) string {
+	switch e {
+	case ValueBase64:
+		return base64.StdEncoding.EncodeToString(value)
+	case ValueHex:
+		return hex.EncodeToString(value)
+	}
+	return string(value)
+}
+
+// decode returns the global state value of the given string representation.
+func (e ValueEncoding) decode(value string) ([]byte, error) {
+	switch e {
+	case ValueBase64:
+		b, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("value is not base64-encoded {%s}: %w", value, err)
+		}
+		return b, nil
+	case ValueHex:
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("value is not hex-encoded {%s}: %w", value, err)
+		}
+		return b, nil
+	}
+	return []byte(value), nil
+}
+
+// GetBufferHex returns the stored key-value pairs like GetBuffer, but with hex-encoded
+// values, regardless of ManageConfig.ValueEncoding.
+func (ab *AlgorandBuffer) GetBufferHex(ctx context.Context) (map[string]string, error) {
+	b, err := ab.GetBufferRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(b))
+	for k, v := range b {
+		m[k] = ValueHex.encode(v)
+	}
+	return m, nil
+}
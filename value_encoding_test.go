@@ -0,0 +1,54 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlgorandBuffer_ValueEncoding(t *testing.T) {
+	binary := []byte{0x00, 0xff, 0x10, 'a'}
+	rendered := map[ValueEncoding]string{
+		ValueUTF8:   string(binary),
+		ValueBase64: "AP8QYQ==",
+		ValueHex:    "00ff1061",
+	}
+	for encoding, expected := range rendered {
+		c := client.CreateAlgorandClientMock("", "")
+		c.CreateDummyApps(6)
+		c.App = c.Account.CreatedApps[0]
+		buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ValueEncoding: encoding})
+		assert.Nil(t, err)
+
+		assert.Nil(t, buffer.PutElementsRaw(context.Background(), map[string][]byte{"bin": binary}))
+		data, err := buffer.GetBuffer(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, expected, data["bin"])
+		hex, err := buffer.GetBufferHex(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, "00ff1061", hex["bin"])
+
+		// values are written in the same encoding
+		assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"copy": expected}))
+		assert.Nil(t, buffer.PutOrdered(context.Background(), []KV{{Key: "ordered", Value: expected}}))
+		raw, err := buffer.GetBufferRaw(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, binary, raw["copy"])
+		assert.Equal(t, binary, raw["ordered"])
+	}
+}
+
+func TestAlgorandBuffer_ValueEncodingInvalid(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	c.CreateDummyApps(6)
+	c.App = c.Account.CreatedApps[0]
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{ValueEncoding: ValueHex})
+	assert.Nil(t, err)
+	assert.NotNil(t, buffer.PutElements(context.Background(), map[string]string{"k": "not hex"}))
+	assert.NotNil(t, buffer.PutOrdered(context.Background(), []KV{{Key: "k", Value: "0"}}))
+	assert.Len(t, c.App.Params.GlobalState, 0)
+}
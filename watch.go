@@ -0,0 +1,145 @@
+package siam
+
+import (
+	"encoding/base64"
+	"sort"
+	"sync"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// watchChannelSize is the capacity of the channels returned by Watch.
+const watchChannelSize = 64
+
+// StateChange is a change of a key of the global state, observed by the management
+// loop (see Watch).
+type StateChange struct {
+	// Key is the changed key.
+	Key string
+
+	// Old is the value before the change, and New the value after it. Old is nil if the
+	// key has been created, and New is nil if it has been deleted.
+	Old []byte
+	New []byte
+
+	// Round is the round in which the change was observed. The change was confirmed in
+	// this round or before.
+	Round uint64
+}
+
+// watcher is a subscription created by Watch.
+type watcher struct {
+	keys map[string]bool
+	ch   chan StateChange
+}
+
+// Watch subscribes to changes of the given keys, or of all keys if none are given.
+// Every cycle of the management loop (see Manage) compares the global state of the
+// application to the state of the previous cycle, and sends the changes to the
+// returned channel, ordered by key. Changes by other writers are observed as well.
+// Changes are dropped if the channel is full, so the loop never blocks. Reserved keys
+// (e.g. HeartbeatKey) aren't reported.
+//
+// Call the returned function to unsubscribe, which closes the channel. The channel is
+// also closed by Stop.
+func (ab *AlgorandBuffer) Watch(keys ...string) (<-chan StateChange, func()) {
+	w := &watcher{ch: make(chan StateChange, watchChannelSize)}
+	if len(keys) > 0 {
+		w.keys = make(map[string]bool, len(keys))
+		for _, k := range keys {
+			w.keys[k] = true
+		}
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if ab.stopped {
+		close(w.ch)
+		return w.ch, func() {}
+	}
+	if ab.watchers == nil {
+		ab.watchers = make(map[*watcher]bool)
+	}
+	ab.watchers[w] = true
+	var once sync.Once
+	return w.ch, func() {
+		once.Do(func() {
+			ab.mu.Lock()
+			defer ab.mu.Unlock()
+			if ab.watchers[w] {
+				delete(ab.watchers, w)
+				close(w.ch)
+			}
+		})
+	}
+}
+
+// observeState compares the global state of the app to the state observed before,
+// and publishes the changes to the watchers (see Watch). The first observation only
+// records the state.
+func (ab *AlgorandBuffer) observeState(round uint64, app models.Application) {
+	state := make(map[string]string, len(app.Params.GlobalState))
+	for _, kv := range app.Params.GlobalState {
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil || ab.isReservedKey(string(key)) {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(kv.Value.Bytes)
+		if err != nil {
+			continue
+		}
+		state[ab.config.KeyEncoding.fromState(key)] = string(value)
+	}
+
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	previous, observed := ab.watchState, ab.watchObserved
+	ab.watchState, ab.watchObserved = state, true
+	if !observed || len(ab.watchers) == 0 {
+		return
+	}
+	for _, change := range stateChanges(diffStates(previous, state), round) {
+		for w := range ab.watchers {
+			if w.keys != nil && !w.keys[change.Key] {
+				continue
+			}
+			select {
+			case w.ch <- change:
+			default:
+			}
+		}
+	}
+}
+
+// isReservedKey returns true for reserved and companion keys of the global state.
+func (ab *AlgorandBuffer) isReservedKey(key string) bool {
+	for _, k := range ab.reservedKeys() {
+		if k == key {
+			return true
+		}
+	}
+	return ab.isCompanionKey(key)
+}
+
+// stateChanges returns the changes of the diff, ordered by key.
+func stateChanges(d StateDiff, round uint64) []StateChange {
+	changes := make([]StateChange, 0, len(d.Added)+len(d.Removed)+len(d.Changed))
+	for k, v := range d.Added {
+		changes = append(changes, StateChange{Key: k, New: []byte(v), Round: round})
+	}
+	for k, v := range d.Removed {
+		changes = append(changes, StateChange{Key: k, Old: []byte(v), Round: round})
+	}
+	for k, c := range d.Changed {
+		changes = append(changes, StateChange{Key: k, Old: []byte(c.Old), New: []byte(c.New), Round: round})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// closeWatchers closes the channels of all watchers. Call with mu held.
+func (ab *AlgorandBuffer) closeWatchers() {
+	for w := range ab.watchers {
+		close(w.ch)
+	}
+	ab.watchers = nil
+}
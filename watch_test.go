@@ -0,0 +1,82 @@
+//go:build unit
+
+package siam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/m2q/algo-siam/client"
+	"github.com/stretchr/testify/assert"
+)
+
+// drain returns the changes that are waiting in the channel.
+func drain(ch <-chan StateChange) []StateChange {
+	changes := make([]StateChange, 0)
+	for {
+		select {
+		case c := <-ch:
+			changes = append(changes, c)
+		default:
+			return changes
+		}
+	}
+}
+
+func TestAlgorandBuffer_Watch(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBuffer(c, client.GeneratePrivateKey64())
+	assert.Nil(t, err)
+	all, cancelAll := buffer.Watch()
+	price, cancelPrice := buffer.Watch("price")
+	defer cancelPrice()
+
+	// the cycle observes the write of the previous one
+	assert.Nil(t, buffer.PutElements(context.Background(), map[string]string{"price": "1", "meta": ""}))
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, []StateChange{
+		{Key: "meta", New: []byte{}},
+		{Key: "price", New: []byte("1")},
+	}, drain(all))
+	assert.Equal(t, []StateChange{{Key: "price", New: []byte("1")}}, drain(price))
+
+	// writes of others are observed as well
+	err = c.StoreGlobals(buffer.AccountCrypt, buffer.AppId, []models.TealKeyValue{{Key: "price", Value: models.TealValue{Bytes: "2"}}})
+	assert.Nil(t, err)
+	assert.Nil(t, buffer.DeleteElements(context.Background(), "meta"))
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Equal(t, []StateChange{
+		{Key: "meta", Old: []byte{}},
+		{Key: "price", Old: []byte("1"), New: []byte("2")},
+	}, drain(all))
+	assert.Equal(t, []StateChange{{Key: "price", Old: []byte("1"), New: []byte("2")}}, drain(price))
+
+	// unchanged state reports nothing
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Empty(t, drain(all))
+
+	cancelAll()
+	cancelAll()
+	_, open := <-all
+	assert.False(t, open)
+
+	buffer.Stop()
+	_, open = <-price
+	assert.False(t, open)
+	stopped, _ := buffer.Watch()
+	_, open = <-stopped
+	assert.False(t, open)
+}
+
+// Reserved keys aren't reported.
+func TestAlgorandBuffer_WatchReserved(t *testing.T) {
+	c := client.CreateAlgorandClientMock("", "")
+	buffer, err := NewAlgorandBufferWithConfig(c, client.GeneratePrivateKey64(), ManageConfig{HeartbeatInterval: 1})
+	assert.Nil(t, err)
+	all, cancel := buffer.Watch()
+	defer cancel()
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Nil(t, buffer.manageCycle(context.Background()))
+	assert.Empty(t, drain(all))
+}